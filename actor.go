@@ -2,10 +2,13 @@ package goactor
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/hedisam/goactor/internal/intlpid"
+	"github.com/hedisam/goactor/internal/pidconv"
 	"github.com/hedisam/goactor/internal/relations"
 	p "github.com/hedisam/goactor/pid"
+	"github.com/hedisam/goactor/reason"
 	"github.com/hedisam/goactor/sysmsg"
 	"log"
 	"sync/atomic"
@@ -17,6 +20,9 @@ const (
 	trapExitYes
 )
 
+// DefaultStashCap is the number of messages an actor can stash, unless it's changed by SetStashCap.
+const DefaultStashCap = 1000
+
 type Actor struct {
 	relationManager relationManager
 	mailbox         Mailbox
@@ -27,16 +33,29 @@ type Actor struct {
 	// to signal the actor's user by canceling the context using the shutdown method
 	ctx 			context.Context
 	ctxCancel		func()
+	// parent is the context the actor's context is derived from. the actor exits with the reason.Shutdown reason
+	// once it's cancelled.
+	parent context.Context
 	msgHandler 		MessageHandler
+	// behaviours is the stack of handlers pushed by Become on top of msgHandler. it's only accessed by the actor's
+	// own goroutine.
+	behaviours []MessageHandler
+	// stash holds the messages deferred by Stash. it's only accessed by the actor's own goroutine.
+	stash    []interface{}
+	stashCap int
+	// labels are set once the actor is spawned, and never change afterwards.
+	labels map[string]string
 }
 
-func newActor(mailbox Mailbox, manager relationManager) *Actor {
+func newActor(ctx context.Context, mailbox Mailbox, manager relationManager) *Actor {
 	a := &Actor{
 		mailbox:         mailbox,
 		relationManager: manager,
 		trapExit:        trapExitNo,
+		stashCap:        DefaultStashCap,
+		parent:          ctx,
 	}
-	a.ctx, a.ctxCancel = context.WithCancel(context.Background())
+	a.ctx, a.ctxCancel = context.WithCancel(ctx)
 	return a
 }
 
@@ -56,14 +75,89 @@ func (a *Actor) Self() *p.PID {
 	return a.self
 }
 
+// Labels returns a copy of the labels the actor was spawned with by WithLabels.
+func (a *Actor) Labels() map[string]string {
+	labels := make(map[string]string, len(a.labels))
+	for k, v := range a.labels {
+		labels[k] = v
+	}
+	return labels
+}
+
 func (a *Actor) Receive(handler MessageHandler) error {
 	a.msgHandler = handler
-	return a.mailbox.Receive(handler, a.systemMessageHandler)
+	a.behaviours = nil
+	return a.mailbox.Receive(a.handleMessage, a.systemMessageHandler)
 }
 
 func (a *Actor) ReceiveWithTimeout(timeout time.Duration, handler MessageHandler) error {
 	a.msgHandler = handler
-	return a.mailbox.ReceiveWithTimeout(timeout, handler, a.systemMessageHandler)
+	a.behaviours = nil
+	return a.mailbox.ReceiveWithTimeout(timeout, a.handleMessage, a.systemMessageHandler)
+}
+
+// Become makes the handler the actor's current behaviour, so it handles the next messages, including the system
+// messages that are passed to the user, instead of the handler given to Receive. The previous behaviour is kept
+// in a stack and is restored by Unbecome. It must be called by the actor's own goroutine, e.g. from within a handler.
+func (a *Actor) Become(handler MessageHandler) {
+	a.behaviours = append(a.behaviours, handler)
+}
+
+// Unbecome restores the behaviour which was current before the last call to Become. The handler given to Receive
+// is never removed, so calling Unbecome without a matching Become is a no-op.
+func (a *Actor) Unbecome() {
+	if len(a.behaviours) == 0 {
+		return
+	}
+	a.behaviours[len(a.behaviours)-1] = nil
+	a.behaviours = a.behaviours[:len(a.behaviours)-1]
+}
+
+// handleMessage dispatches the message to the current behaviour.
+func (a *Actor) handleMessage(message interface{}) (loop bool) {
+	if n := len(a.behaviours); n > 0 {
+		return a.behaviours[n-1](message)
+	}
+	return a.msgHandler(message)
+}
+
+// Stash defers the message, usually the one being handled, until UnstashAll is called. It returns ErrStashOverflow
+// once the stash holds as many messages as its capacity.
+// With a mailbox which acknowledges the handled messages, e.g. the WAL mailbox, the message being handled isn't
+// acknowledged until it's unstashed, so it's not lost if the actor crashes in between.
+func (a *Actor) Stash(msg interface{}) error {
+	if len(a.stash) >= a.stashCap {
+		return ErrStashOverflow
+	}
+	a.stash = append(a.stash, msg)
+	if m, ok := a.mailbox.(stashingMailbox); ok {
+		m.StashCurrent()
+	}
+	return nil
+}
+
+// UnstashAll puts the stashed messages back in the mailbox, in the order they were stashed and in front of the
+// messages which are already waiting, so they're the next ones to be handled.
+func (a *Actor) UnstashAll() error {
+	if len(a.stash) == 0 {
+		return nil
+	}
+	m, ok := a.mailbox.(prependableMailbox)
+	if !ok {
+		return ErrUnstashNotSupported
+	}
+	err := m.PrependMessages(a.stash)
+	if err != nil {
+		return fmt.Errorf("unstash failed: %w", err)
+	}
+	a.stash = nil
+	return nil
+}
+
+// SetStashCap sets the number of messages the actor can stash. The messages which are already stashed are kept
+// even if there are more of them.
+func (a *Actor) SetStashCap(capacity int) {
+	a.stashCap = capacity
 }
 
 func (a *Actor) Link(pid *p.PID) error {
@@ -72,12 +166,12 @@ func (a *Actor) Link(pid *p.PID) error {
 		return ErrLinkNilTargetPID
 	}
 	// first we need to ask the other actor to link to this actor.
-	err := intlpid.Link(pid.InternalPID(), a.self.InternalPID())
+	err := intlpid.Link(pidconv.Internal(pid), pidconv.Internal(a.self))
 	if err != nil {
 		return fmt.Errorf("failed to add this actor to the target's linked actors list: %w", err)
 	}
 	// add the target actor to our linked actors list
-	err = a.relationManager.AddLink(pid.InternalPID())
+	err = a.relationManager.AddLink(pidconv.Internal(pid))
 	if err != nil {
 		return fmt.Errorf("link failed: %w", err)
 	}
@@ -89,49 +183,69 @@ func (a *Actor) Unlink(pid *p.PID) error {
 		return ErrUnlinkNilTargetPID
 	}
 	// attempt to remove the link from the other actor
-	err := intlpid.Unlink(pid.InternalPID(), a.self.InternalPID())
+	err := intlpid.Unlink(pidconv.Internal(pid), pidconv.Internal(a.self))
 	if err != nil {
 		return fmt.Errorf("failed to remove this actor from the target's linked actors list: %w", err)
 	}
 	// remove the target actor from our linked actors list
-	err = a.relationManager.RemoveLink(pid.InternalPID())
+	err = a.relationManager.RemoveLink(pidconv.Internal(pid))
 	if err != nil {
 		return fmt.Errorf("unlink failed: %w", err)
 	}
 	return nil
 }
 
-func (a *Actor) Monitor(pid *p.PID) error {
+// Monitor starts monitoring the given actor and returns a unique reference to the new monitor. Once the target actor
+// exits, a sysmsg.Down message carrying the same reference is delivered to this actor. Monitoring the same actor more
+// than once creates independent monitors, each with its own Down message.
+// If the target actor has already exited, the Down message is delivered right away with the reason.NoProc reason.
+func (a *Actor) Monitor(pid *p.PID) (sysmsg.MonitorRef, error) {
 	if pid == nil {
-		return ErrMonitorNilTargetPID
+		return "", ErrMonitorNilTargetPID
 	}
-	// ask the child actor to be monitored by this actor.
-	err := intlpid.AddMonitor(pid.InternalPID(), a.self.InternalPID())
+	ref := sysmsg.NewMonitorRef()
+	// save the target actor as monitored first, so its Down message is recognised as soon as it gets delivered.
+	err := a.relationManager.AddMonitored(string(ref), pidconv.Internal(pid))
 	if err != nil {
-		return fmt.Errorf("failed to monitor: %w", err)
+		return "", fmt.Errorf("monitor failed: %w", err)
 	}
-	// save the child actor as monitored.
-	err = a.relationManager.AddMonitored(pid.InternalPID())
-	if err != nil {
-		return fmt.Errorf("monitor failed: %w", err)
+	// ask the target actor to be monitored by this actor.
+	err = intlpid.AddMonitor(pidconv.Internal(pid), pidconv.Internal(a.self), string(ref))
+	if errors.Is(err, relations.ErrDisposed) {
+		// the target actor has already exited.
+		err = intlpid.SendSystemMessage(pidconv.Internal(a.self), sysmsg.Down{Ref: ref, PID: pid, Reason: reason.NoProc})
+		if err != nil {
+			_ = a.relationManager.RemoveMonitored(string(ref))
+			return "", fmt.Errorf("failed to deliver the noproc down message: %w", err)
+		}
+		return ref, nil
+	} else if err != nil {
+		_ = a.relationManager.RemoveMonitored(string(ref))
+		return "", fmt.Errorf("failed to monitor: %w", err)
 	}
-	return nil
+	return ref, nil
 }
 
-func (a *Actor) Demonitor(pid *p.PID) error {
-	if pid == nil {
-		return ErrDemonitorNilTargetPID
+// Demonitor removes the monitor identified by the given reference. Once it returns, no Down message for that monitor
+// gets passed to the user's handler. Demonitoring an unknown or an already triggered monitor is a no-op.
+func (a *Actor) Demonitor(ref sysmsg.MonitorRef) error {
+	if ref == "" {
+		return ErrDemonitorEmptyRef
 	}
-	// ask the target actor to be de-monitored.
-	err := intlpid.RemoveMonitor(pid.InternalPID(), a.self.InternalPID())
-	if err != nil {
-		return fmt.Errorf("failed to demonitor: %w", err)
+	target, ok := a.relationManager.Monitored(string(ref))
+	if !ok {
+		return nil
 	}
-	// remove the child from our monitored actors list
-	err = a.relationManager.RemoveMonitored(pid.InternalPID())
+	// remove the target from our monitored actors list
+	err := a.relationManager.RemoveMonitored(string(ref))
 	if err != nil {
 		return fmt.Errorf("demonitor failed: %w", err)
 	}
+	// ask the target actor to be de-monitored. it's fine if it has already exited.
+	err = intlpid.RemoveMonitor(target, string(ref))
+	if err != nil && !errors.Is(err, relations.ErrDisposed) {
+		return fmt.Errorf("failed to demonitor: %w", err)
+	}
 	return nil
 }
 
@@ -144,40 +258,71 @@ func (a *Actor) shutdown() {
 	a.relationManager.Dispose()
 }
 
+// Context returns the actor's context, which is cancelled once the actor is shut down, e.g. by its supervisor, or
+// once its parent context, given by WithContext, is cancelled.
 func (a *Actor) Context() context.Context {
 	return a.ctx
 }
 
+// watchParent makes the actor exit once its parent context is cancelled. An actor blocked in Receive is woken up by
+// a ShutdownCMD sent to itself, while a busy one is expected to watch its own context.
+// The context is watched by a plain goroutine, since a goroutine driven by sched.Run can't block on anything but a
+// message. So under sched.Run, the ShutdownCMD isn't part of the run's deterministic interleaving.
+func (a *Actor) watchParent() {
+	if a.parent.Done() == nil {
+		// the parent context can't be cancelled.
+		return
+	}
+	go func() {
+		// the actor's context is cancelled too once the actor exits, which stops the watcher.
+		<-a.ctx.Done()
+		if a.parent.Err() == nil {
+			return
+		}
+		_ = intlpid.SendSystemMessage(pidconv.Internal(a.self), sysmsg.NewShutdownCMD(a.self, reason.Shutdown, nil))
+	}()
+}
+
 func (a *Actor) systemMessageHandler(sysMsg interface{}) (loop bool) {
 	switch msg := sysMsg.(type) {
 	case sysmsg.NormalExit:
-		// some actor (linked or monitored) has exited with normal reason.
-		relationType := a.relationManager.RelationType(msg.Sender())
-		if relationType == relations.MonitoredRelation || relationType == relations.LinkedRelation {
+		// some linked actor has exited with normal reason.
+		relationType := a.relationManager.RelationType(pidconv.Internal(msg.Sender()))
+		if relationType == relations.LinkedRelation {
 			// some child actor has exited normally. we should pass this message to the user.
-			return a.msgHandler(sysMsg)
+			return a.handleMessage(sysMsg)
 		}
 		break
 	case sysmsg.AbnormalExit:
-		// some actor (linked or monitored) has exited with an abnormal reason.
-		relationType := a.relationManager.RelationType(msg.Sender())
+		// some linked actor has exited with an abnormal reason.
+		relationType := a.relationManager.RelationType(pidconv.Internal(msg.Sender()))
 		trapExit := atomic.LoadInt32(&a.trapExit)
-		if relationType == relations.MonitoredRelation || (relationType == relations.LinkedRelation && trapExit == trapExitYes) {
-			// if the message is from a monitored actor, or it's from a linked one but the current actor
-			// is trapping exit messages, then we just need to pass the exit message to the user handler.
-			return a.msgHandler(sysMsg)
+		if relationType == relations.LinkedRelation && trapExit == trapExitYes {
+			// the current actor is trapping exit messages, so we just need to pass the exit message to the user handler.
+			return a.handleMessage(sysMsg)
 		} else if relationType == relations.LinkedRelation && trapExit == trapExitNo {
 			// the terminated actor is linked and we're not trapping exit messages.
 			// so we should panic with the same msg.
 			panic(sysMsg)
 		}
 
-		// if the terminated actor is not linked, nor monitored, then we just ignore the abnormal exit message.
+		// if the terminated actor is not linked, then we just ignore the abnormal exit message.
+		break
+	case sysmsg.Down:
+		// some monitored actor has exited. the monitor could've been removed by Demonitor in the meantime.
+		if _, ok := a.relationManager.Monitored(string(msg.Ref)); ok {
+			_ = a.relationManager.RemoveMonitored(string(msg.Ref))
+			return a.handleMessage(sysMsg)
+		}
 		break
 	case sysmsg.KillExit:
 		// todo: implement
 		break
 	case sysmsg.ShutdownCMD:
+		if p.Equal(msg.Sender(), a.self) && a.parent.Err() != nil {
+			// sent by watchParent, the parent context has been cancelled.
+			panic(reason.Shutdown)
+		}
 		// todo: implement
 		break
 	default:
@@ -195,25 +340,30 @@ func (a *Actor) dispose() {
 		// the actor has received an exit message and called panic on it.
 		// notifying linked and monitor actors.
 		log.Printf("actor %v received an abnormal exit message from %v, reason: %v\n", a.Self().ID(), r.Sender().ID(), r.Reason())
-		msg = sysmsg.NewAbnormalExitMsg(
-			a.self.InternalPID(),
-			"exiting by receiving an abnormal message",
-			&r)
+		// we exit with the same reason as the linked actor.
+		msg = sysmsg.NewAbnormalExitMsg(a.self, r.Reason(), &r)
 	case sysmsg.NormalExit:
 		// panic(NormalExit) has been called. so we just notify linked and monitor actors with a normal message.
-		msg = sysmsg.NewNormalExitMsg(a.self.InternalPID(), &r)
+		msg = sysmsg.NewNormalExitMsg(a.self, &r)
 	case sysmsg.KillExit:
-		msg = sysmsg.NewKillMessage(a.self.InternalPID(), "exiting by receiving a kill message", &r)
+		msg = sysmsg.NewKillMessage(a.self, reason.Killed, &r)
 	case sysmsg.ShutdownCMD:
-		msg = sysmsg.NewShutdownCMD(a.self.InternalPID(), "exiting by receiving a shutdown command", &r)
+		msg = sysmsg.NewShutdownCMD(a.self, reason.Shutdown, &r)
 	default:
-		if r != nil {
+		if r == reason.Normal {
+			// panic(reason.Normal) is just another way of exiting normally.
+			msg = sysmsg.NewNormalExitMsg(a.self, nil)
+		} else if r == reason.Shutdown || (r == nil && a.parent.Err() != nil) {
+			// the actor has been shut down, e.g. its parent context has been cancelled. the linked actors which don't
+			// trap exits exit with the same reason, like with any other abnormal exit.
+			msg = sysmsg.NewAbnormalExitMsg(a.self, reason.Shutdown, nil)
+		} else if r != nil {
 			// something has went wrong. notify with an AbnormalExit message.
 			log.Printf("dispose: actor %v had a panic, reason: %v\n", a.Self().ID(), r)
-			msg = sysmsg.NewAbnormalExitMsg(a.self.InternalPID(), r, nil)
+			msg = sysmsg.NewAbnormalExitMsg(a.self, reason.FromPanic(r), nil)
 		} else {
 			// it's just a normal exit
-			msg = sysmsg.NewNormalExitMsg(a.self.InternalPID(), nil)
+			msg = sysmsg.NewNormalExitMsg(a.self, nil)
 		}
 	}
 	a.notifyRelatedActors(msg)
@@ -226,12 +376,26 @@ func (a *Actor) notifyRelatedActors(msg sysmsg.SystemMessage) {
 	}
 	monitorIterator := a.relationManager.MonitorActors()
 	for monitorIterator.HasNext() {
-		a.notify(monitorIterator.Value(), msg)
+		monitor := monitorIterator.Value()
+		a.notifyMonitor(monitor, msg)
+	}
+}
+
+func (a *Actor) notifyMonitor(monitor relations.Monitor, msg sysmsg.SystemMessage) {
+	down := sysmsg.Down{
+		Ref:    sysmsg.MonitorRef(monitor.Ref),
+		PID:    a.self,
+		Reason: msg.Reason(),
+	}
+	err := intlpid.SendSystemMessage(monitor.PID, down)
+	if err != nil {
+		log.Printf("notifyRelatedActors: could not deliver down message to pid: %v, sender: %v, err: %v\n",
+			monitor.PID.ID(), a.self.ID(), err)
 	}
 }
 
 func (a *Actor) notify(pid intlpid.InternalPID, msg sysmsg.SystemMessage) {
-	if msg.Origin() != nil && msg.Origin().Sender() == pid {
+	if msg.Origin() != nil && pidconv.Internal(msg.Origin().Sender()) == pid {
 		return
 	}
 	err := intlpid.SendSystemMessage(pid, msg)
@@ -1,12 +1,18 @@
 package goactor
 
 import (
+	"errors"
+	"github.com/hedisam/goactor/clock"
+	"github.com/hedisam/goactor/codec"
+	"github.com/hedisam/goactor/internal/pidconv"
 	"github.com/hedisam/goactor/mailbox"
 	p "github.com/hedisam/goactor/pid"
+	"github.com/hedisam/goactor/reason"
 	"github.com/hedisam/goactor/sysmsg"
 	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
 	"reflect"
-	"sync"
 	"testing"
 	"time"
 )
@@ -76,7 +82,10 @@ func TestActor_Receive(t *testing.T) {
 }
 
 func TestActor_ReceiveWithTimeout(t *testing.T) {
-	actor, pid := setupActor(DefaultChanMailbox)
+	c := clock.NewFake(time.Now())
+	actor, pid := setupActor(func() Mailbox {
+		return mailbox.NewChanMailbox(mailbox.DefaultUserMailboxCap, mailbox.DefaultSysMailboxCap, 0).SetClock(c)
+	})
 	if !assert.NotNil(t, pid) || !assert.NotNil(t, actor) {return}
 
 	err := Send(pid, "Hello")
@@ -89,22 +98,22 @@ func TestActor_ReceiveWithTimeout(t *testing.T) {
 	})
 	if !assert.Nil(t, err) {return}
 
-	var wg sync.WaitGroup
-	wg.Add(1)
-	time.AfterFunc(time.Millisecond * 15, func() {
-		defer wg.Done()
-		err = Send(pid, "Hi with delay")
-		assert.Nil(t, err)
-	})
-
-	err = actor.ReceiveWithTimeout(time.Millisecond * 10, func(message interface{}) (loop bool) {
-		t.Errorf("timeout expected to get triggered before receiving this message: %v", message)
-		return false
-	})
+	done := make(chan error, 1)
+	go func() {
+		done <- actor.ReceiveWithTimeout(time.Millisecond * 10, func(message interface{}) (loop bool) {
+			t.Errorf("timeout expected to get triggered before receiving this message: %v", message)
+			return false
+		})
+	}()
+	c.BlockUntil(1)
+	c.Advance(time.Millisecond * 10)
+	err = <-done
 	if !assert.NotNil(t, err) {return}
 	assert.Equal(t, mailbox.ErrMailboxReceiveTimeout, err)
 
-	wg.Wait()
+	// the message sent after the timeout is left in the mailbox
+	err = Send(pid, "Hi with delay")
+	assert.Nil(t, err)
 
 	actor.mailbox.Dispose()
 
@@ -135,13 +144,13 @@ func TestActor_LinkUnlink(t *testing.T) {
 	if !assert.True(t, it.HasNext()) {return}
 	linkedToActor1 := it.Value()
 	assert.NotNil(t, linkedToActor1)
-	assert.Equal(t, pid2.InternalPID(), linkedToActor1)
+	assert.Equal(t, pidconv.Internal(pid2), linkedToActor1)
 
 	it = actor2.relationManager.LinkedActors()
 	if !assert.True(t, it.HasNext()) {return}
 	linkedToActor2 := it.Value()
 	assert.NotNil(t, linkedToActor2)
-	assert.Equal(t, pid1.InternalPID(), linkedToActor2)
+	assert.Equal(t, pidconv.Internal(pid1), linkedToActor2)
 
 	// we can not link the same actor twice
 	err = actor1.Link(pid2)
@@ -149,12 +158,12 @@ func TestActor_LinkUnlink(t *testing.T) {
 
 	it = actor1.relationManager.LinkedActors()
 	if !assert.True(t, it.HasNext()) {return}
-	assert.Equal(t, pid2.InternalPID(), it.Value())
+	assert.Equal(t, pidconv.Internal(pid2), it.Value())
 	if !assert.False(t, it.HasNext()) {return}
 
 	it = actor2.relationManager.LinkedActors()
 	if !assert.True(t, it.HasNext()) {return}
-	assert.Equal(t, pid1.InternalPID(), it.Value())
+	assert.Equal(t, pidconv.Internal(pid1), it.Value())
 	assert.False(t, it.HasNext())
 
 	actor3, pid3 := setupActor(DefaultChanMailbox)
@@ -166,16 +175,16 @@ func TestActor_LinkUnlink(t *testing.T) {
 	it = actor1.relationManager.LinkedActors()
 	if !assert.True(t, it.HasNext()) {return}
 	secondLinkedActor := it.Value()
-	if reflect.DeepEqual(secondLinkedActor, pid2.InternalPID()) {
+	if reflect.DeepEqual(secondLinkedActor, pidconv.Internal(pid2)) {
 		if !assert.True(t, it.HasNext()) {return}
 		secondLinkedActor = it.Value()
-		if !assert.Equal(t, pid3.InternalPID(), secondLinkedActor) {return}
-	} else if reflect.DeepEqual(secondLinkedActor, pid3.InternalPID()) {
+		if !assert.Equal(t, pidconv.Internal(pid3), secondLinkedActor) {return}
+	} else if reflect.DeepEqual(secondLinkedActor, pidconv.Internal(pid3)) {
 		if !assert.True(t, it.HasNext()) {return}
 		secondLinkedActor = it.Value()
-		if !assert.Equal(t, pid2.InternalPID(), secondLinkedActor) {return}
+		if !assert.Equal(t, pidconv.Internal(pid2), secondLinkedActor) {return}
 	} else {
-		t.Errorf("unknown actor linked to our actor: expected(%v or %v), got: %v", pid2.InternalPID(), pid3.InternalPID(), secondLinkedActor)
+		t.Errorf("unknown actor linked to our actor: expected(%v or %v), got: %v", pidconv.Internal(pid2), pidconv.Internal(pid3), secondLinkedActor)
 		return
 	}
 	if !assert.False(t, it.HasNext()) {return }
@@ -193,7 +202,7 @@ func TestActor_LinkUnlink(t *testing.T) {
 
 	it = actor1.relationManager.LinkedActors()
 	if !assert.True(t, it.HasNext()) {return }
-	assert.Equal(t, pid3.InternalPID(), it.Value())
+	assert.Equal(t, pidconv.Internal(pid3), it.Value())
 	assert.False(t, it.HasNext())
 
 	it = actor2.relationManager.LinkedActors()
@@ -239,81 +248,114 @@ func TestActor_MonitorDemonitor(t *testing.T) {
 		actor1, pid1 := getActorForTest(t)
 		actor2, pid2 := getActorForTest(t)
 
-		err := actor1.Monitor(pid2)
+		ref, err := actor1.Monitor(pid2)
 		if !assert.Nil(t, err) {return}
+		assert.NotEmpty(t, ref)
 
 		it := actor2.relationManager.MonitorActors()
 		assert.True(t, it.HasNext())
-		assert.Equal(t, pid1.InternalPID(), it.Value())
+		monitor := it.Value()
+		assert.Equal(t, pidconv.Internal(pid1), monitor.PID)
+		assert.Equal(t, string(ref), monitor.Ref)
 		assert.False(t, it.HasNext())
 
-		err = actor1.Demonitor(pid2)
+		err = actor1.Demonitor(ref)
 		if !assert.Nil(t, err) {return}
 
 		it = actor2.relationManager.MonitorActors()
 		assert.False(t, it.HasNext())
+		_, ok := actor1.relationManager.Monitored(string(ref))
+		assert.False(t, ok)
 	})
 
 	t.Run("monitor an already monitored actor", func(t *testing.T) {
-		actor1, pid1 := getActorForTest(t)
+		actor1, _ := getActorForTest(t)
 		actor2, pid2 := getActorForTest(t)
 
-		err := actor1.Monitor(pid2)
+		ref1, err := actor1.Monitor(pid2)
 		if !assert.Nil(t, err) {return}
 
-		// this should not add anything to the list of monitored actors
-		err = actor1.Monitor(pid2)
+		// this should create a second, independent monitor
+		ref2, err := actor1.Monitor(pid2)
 		if !assert.Nil(t, err) {return}
+		assert.NotEqual(t, ref1, ref2)
 
 		it := actor2.relationManager.MonitorActors()
-		assert.True(t, it.HasNext())
-		assert.Equal(t, pid1.InternalPID(), it.Value())
-		assert.False(t, it.HasNext())
+		count := 0
+		for it.HasNext() {
+			it.Value()
+			count++
+		}
+		assert.Equal(t, 2, count)
+
+		// both monitors get their own Down message
+		actor2.dispose()
+
+		var downs []sysmsg.Down
+		err = actor1.ReceiveWithTimeout(10*time.Millisecond, func(message interface{}) (loop bool) {
+			if !assert.IsType(t, sysmsg.Down{}, message) {return false}
+			downs = append(downs, message.(sysmsg.Down))
+			return len(downs) < 2
+		})
+		assert.Nil(t, err)
+		if !assert.Equal(t, 2, len(downs)) {return}
+		assert.ElementsMatch(t, []sysmsg.MonitorRef{ref1, ref2}, []sysmsg.MonitorRef{downs[0].Ref, downs[1].Ref})
+		assert.Equal(t, pid2, downs[0].PID)
 	})
 
-	t.Run("demonitor a non-monitored actor", func(t *testing.T) {
+	t.Run("demonitor an unknown reference", func(t *testing.T) {
 		actor1, _ := getActorForTest(t)
-		_, pid2 := getActorForTest(t)
 
-		err := actor1.Monitor(pid2)
-		if !assert.Nil(t, err) {return}
+		err := actor1.Demonitor(sysmsg.NewMonitorRef())
+		assert.Nil(t, err)
 	})
 
 	t.Run("(de)monitor nil pid", func(t *testing.T) {
 		actor1, _ := getActorForTest(t)
 
-		err := actor1.Monitor(nil)
+		_, err := actor1.Monitor(nil)
 		if !assert.NotNil(t, err) {return}
 		assert.Equal(t, ErrMonitorNilTargetPID, err)
 
-		err = actor1.Demonitor(nil)
+		err = actor1.Demonitor("")
 		if !assert.NotNil(t, err) {return}
-		assert.Equal(t, ErrDemonitorNilTargetPID, err)
+		assert.Equal(t, ErrDemonitorEmptyRef, err)
 	})
 
-	t.Run("(de)monitor disposed actor", func(t *testing.T) {
+	t.Run("monitor disposed actor", func(t *testing.T) {
 		actor1, _ := getActorForTest(t)
 		actor2, pid2 := getActorForTest(t)
 
 		actor2.relationManager.Dispose()
 
-		err := actor1.Monitor(pid2)
-		assert.NotNil(t, err)
+		// monitoring an actor that has already exited delivers a noproc Down message right away
+		ref, err := actor1.Monitor(pid2)
+		if !assert.Nil(t, err) {return}
 
-		err = actor1.Demonitor(pid2)
-		assert.NotNil(t, err)
+		err = actor1.ReceiveWithTimeout(10*time.Millisecond, func(message interface{}) (loop bool) {
+			if !assert.IsType(t, sysmsg.Down{}, message) {return false}
+			down := message.(sysmsg.Down)
+			assert.Equal(t, ref, down.Ref)
+			assert.Equal(t, pid2, down.PID)
+			assert.Equal(t, reason.NoProc, down.Reason)
+			return false
+		})
+		assert.Nil(t, err)
 	})
 
 	t.Run("(de)monitor while the actor itself is disposed", func(t *testing.T) {
 		actor1, _ := getActorForTest(t)
 		_, pid2 := getActorForTest(t)
 
+		ref, err := actor1.Monitor(pid2)
+		if !assert.Nil(t, err) {return}
+
 		actor1.relationManager.Dispose()
 
-		err := actor1.Monitor(pid2)
+		_, err = actor1.Monitor(pid2)
 		assert.NotNil(t, err)
 
-		err = actor1.Demonitor(pid2)
+		err = actor1.Demonitor(ref)
 		assert.NotNil(t, err)
 	})
 }
@@ -329,12 +371,173 @@ func TestActor_Context(t *testing.T) {
 	select {
 	case <-ctx.Done():
 		canceled = true
-	case <-time.After(10 * time.Nanosecond):
+	case <-time.After(time.Second):
 	}
 
 	assert.True(t, canceled)
 }
 
+func TestActor_StashUnstash(t *testing.T) {
+	for name, builder := range map[string]MailboxBuilderFunc{"queue": DefaultQueueMailbox, "chan": DefaultChanMailbox} {
+		t.Run(name, func(t *testing.T) {
+			actor, pid := setupActor(builder)
+			for _, msg := range []interface{}{"w1", "w2", "ready", "w3"} {
+				if !assert.Nil(t, Send(pid, msg)) {return}
+			}
+
+			// the work is deferred until the actor is ready
+			ready := false
+			var handled []interface{}
+			err := actor.ReceiveWithTimeout(time.Second, func(message interface{}) (loop bool) {
+				switch {
+				case message == "ready":
+					ready = true
+					assert.Nil(t, actor.UnstashAll())
+				case !ready:
+					assert.Nil(t, actor.Stash(message))
+				default:
+					handled = append(handled, message)
+				}
+				return len(handled) < 3
+			})
+			assert.Nil(t, err)
+			assert.Equal(t, []interface{}{"w1", "w2", "w3"}, handled)
+			assert.Nil(t, actor.UnstashAll())
+		})
+	}
+}
+
+func TestActor_StashCrash(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stash")
+	if !assert.Nil(t, err) {return}
+	defer os.RemoveAll(dir)
+	walMailbox := func() Mailbox {
+		m, err := mailbox.NewWALMailbox(dir, codec.Gob(), mailbox.DefaultSysMailboxCap, mailbox.DefaultMailboxTimeout, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return m
+	}
+	receive := func(actor *Actor, handler MessageHandler) {
+		err := actor.ReceiveWithTimeout(time.Second, handler)
+		assert.Nil(t, err)
+	}
+
+	actor, pid := setupActor(walMailbox)
+	if !assert.Nil(t, Send(pid, "work")) {return}
+	receive(actor, func(message interface{}) (loop bool) {
+		assert.Nil(t, actor.Stash(message))
+		return false
+	})
+	// crash before unstashing
+	actor.dispose()
+
+	// the stashed message hasn't been acknowledged, so it's replayed
+	actor, pid = setupActor(walMailbox)
+	receive(actor, func(message interface{}) (loop bool) {
+		assert.Equal(t, "work", message)
+		assert.Nil(t, actor.Stash(message))
+		return false
+	})
+	if !assert.Nil(t, actor.UnstashAll()) {return}
+	receive(actor, func(message interface{}) (loop bool) {
+		assert.Equal(t, "work", message)
+		return false
+	})
+	actor.dispose()
+
+	// once it's handled after being unstashed, it's not replayed anymore
+	actor, pid = setupActor(walMailbox)
+	defer actor.dispose()
+	if !assert.Nil(t, Send(pid, "next")) {return}
+	receive(actor, func(message interface{}) (loop bool) {
+		assert.Equal(t, "next", message)
+		return false
+	})
+}
+
+// unstashableMailbox hides the PrependMessages method of the mailbox it wraps.
+type unstashableMailbox struct {
+	Mailbox
+}
+
+func TestActor_StashErrors(t *testing.T) {
+	actor, _ := getActorForTest(t)
+	actor.SetStashCap(1)
+	assert.Nil(t, actor.Stash("first"))
+	assert.Equal(t, ErrStashOverflow, actor.Stash("second"))
+
+	actor, _ = setupActor(func() Mailbox {
+		return unstashableMailbox{Mailbox: DefaultQueueMailbox()}
+	})
+	assert.Nil(t, actor.Stash("first"))
+	assert.Equal(t, ErrUnstashNotSupported, actor.UnstashAll())
+}
+
+func TestActor_BecomeUnbecome(t *testing.T) {
+	actor, pid := getActorForTest(t)
+	for _, msg := range []interface{}{"lock", "open", "unlock", "open", "stop"} {
+		if !assert.Nil(t, Send(pid, msg)) {return}
+	}
+
+	var log []interface{}
+	var locked MessageHandler = func(message interface{}) (loop bool) {
+		if message == "unlock" {
+			actor.Unbecome()
+			return true
+		}
+		log = append(log, "locked: "+message.(string))
+		return true
+	}
+	err := actor.ReceiveWithTimeout(time.Second, func(message interface{}) (loop bool) {
+		switch message {
+		case "lock":
+			actor.Become(locked)
+		case "stop":
+			return false
+		default:
+			log = append(log, message)
+		}
+		return true
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{"locked: open", "open"}, log)
+
+	// unbecome never removes the handler given to receive
+	actor.Unbecome()
+	assert.Nil(t, Send(pid, "after"))
+	err = actor.ReceiveWithTimeout(time.Second, func(message interface{}) (loop bool) {
+		assert.Equal(t, "after", message)
+		return false
+	})
+	assert.Nil(t, err)
+}
+
+func TestActor_BecomeSystemMessages(t *testing.T) {
+	actor, pid := getActorForTest(t)
+	target, targetPID := getActorForTest(t)
+	if !assert.Nil(t, Send(pid, "become")) {return}
+
+	// the down message is passed to the current behaviour, not the one given to receive
+	var ref sysmsg.MonitorRef
+	var down interface{}
+	err := actor.ReceiveWithTimeout(time.Second, func(message interface{}) (loop bool) {
+		if !assert.Equal(t, "become", message) {return false}
+		actor.Become(func(message interface{}) (loop bool) {
+			down = message
+			return false
+		})
+		var err error
+		ref, err = actor.Monitor(targetPID)
+		assert.Nil(t, err)
+		go target.dispose()
+		return true
+	})
+	assert.Nil(t, err)
+	if !assert.IsType(t, sysmsg.Down{}, down) {return}
+	assert.Equal(t, ref, down.(sysmsg.Down).Ref)
+}
+
 func TestActor_systemMessageHandlerNormalExit(t *testing.T) {
 	actor, _ := getActorForTest(t)
 	var msgReceived bool
@@ -358,7 +561,7 @@ func TestActor_systemMessageHandlerNormalExit(t *testing.T) {
 
 	t.Run("msg from linked actor", func(t *testing.T) {
 		_, pid2 := getActorForTest(t)
-		msg := sysmsg.NewNormalExitMsg(pid2.InternalPID(), nil)
+		msg := sysmsg.NewNormalExitMsg(pid2, nil)
 		msgReceived = false
 
 		err := actor.Link(pid2)
@@ -375,16 +578,70 @@ func TestActor_systemMessageHandlerNormalExit(t *testing.T) {
 
 	t.Run("msg from a monitored actor", func(t *testing.T) {
 		_, pid2 := getActorForTest(t)
-		msg := sysmsg.NewNormalExitMsg(pid2.InternalPID(), nil)
+		msg := sysmsg.NewNormalExitMsg(pid2, nil)
 		msgReceived = false
 
-		err := actor.Monitor(pid2)
+		_, err := actor.Monitor(pid2)
 		if !assert.Nil(t, err) {return}
 
 		loop := actor.systemMessageHandler(msg)
 
-		assert.True(t, msgReceived)
+		// monitors get notified by Down messages, so exit messages from monitored actors must be ignored
+		assert.False(t, msgReceived)
+		assert.True(t, loop)
+	})
+}
+
+func TestActor_systemMessageHandlerDown(t *testing.T) {
+	actor, _ := getActorForTest(t)
+	var received interface{}
+
+	actor.msgHandler = func(message interface{}) (loop bool) {
+		received = message
+		return false
+	}
+
+	t.Run("down msg for an unknown monitor", func(t *testing.T) {
+		_, pid2 := getActorForTest(t)
+		received = nil
+
+		loop := actor.systemMessageHandler(sysmsg.Down{Ref: sysmsg.NewMonitorRef(), PID: pid2})
+
+		assert.Nil(t, received)
+		assert.True(t, loop)
+	})
+
+	t.Run("down msg for a monitored actor", func(t *testing.T) {
+		_, pid2 := getActorForTest(t)
+		received = nil
+
+		ref, err := actor.Monitor(pid2)
+		if !assert.Nil(t, err) {return}
+
+		down := sysmsg.Down{Ref: ref, PID: pid2}
+		loop := actor.systemMessageHandler(down)
+
+		assert.Equal(t, down, received)
 		assert.False(t, loop)
+
+		// the monitor is gone once its Down message is delivered
+		_, ok := actor.relationManager.Monitored(string(ref))
+		assert.False(t, ok)
+	})
+
+	t.Run("down msg for a demonitored actor", func(t *testing.T) {
+		_, pid2 := getActorForTest(t)
+		received = nil
+
+		ref, err := actor.Monitor(pid2)
+		if !assert.Nil(t, err) {return}
+		err = actor.Demonitor(ref)
+		if !assert.Nil(t, err) {return}
+
+		loop := actor.systemMessageHandler(sysmsg.Down{Ref: ref, PID: pid2})
+
+		assert.Nil(t, received)
+		assert.True(t, loop)
 	})
 }
 
@@ -411,21 +668,22 @@ func TestActor_systemMessageHandlerAbnormalMessage(t *testing.T) {
 
 	t.Run("sys msg from monitored actor", func(t *testing.T) {
 		_, pid2 := getActorForTest(t)
-		msg := sysmsg.NewAbnormalExitMsg(pid2.InternalPID(), nil, nil)
+		msg := sysmsg.NewAbnormalExitMsg(pid2, nil, nil)
 		msgReceived = false
 
-		err := actor.Monitor(pid2)
+		_, err := actor.Monitor(pid2)
 		if !assert.Nil(t, err) {return}
 
 		loop := actor.systemMessageHandler(msg)
 
-		assert.True(t, msgReceived)
-		assert.False(t, loop)
+		// monitors get notified by Down messages, so exit messages from monitored actors must be ignored
+		assert.False(t, msgReceived)
+		assert.True(t, loop)
 	})
 
 	t.Run("sys msg from linked actor & trapping exit messages", func(t *testing.T) {
 		_, pid2 := getActorForTest(t)
-		msg := sysmsg.NewAbnormalExitMsg(pid2.InternalPID(), nil, nil)
+		msg := sysmsg.NewAbnormalExitMsg(pid2, nil, nil)
 		msgReceived = false
 
 		actor.SetTrapExit(true)
@@ -442,7 +700,7 @@ func TestActor_systemMessageHandlerAbnormalMessage(t *testing.T) {
 
 	t.Run("sys msg from linked actor without trapping exit messages", func(t *testing.T) {
 		_, pid2 := getActorForTest(t)
-		msg := sysmsg.NewAbnormalExitMsg(pid2.InternalPID(), "just testing", nil)
+		msg := sysmsg.NewAbnormalExitMsg(pid2, reason.FromError(errors.New("just testing")), nil)
 		msgReceived = false
 
 		actor.SetTrapExit(false)
@@ -470,11 +728,22 @@ func TestActor_dispose(t *testing.T) {
 
 	var timeout = 10 * time.Millisecond
 
-	err := monitorActor.Monitor(pid)
+	_, err := monitorActor.Monitor(pid)
 	if !assert.Nil(t, err) {return}
 	err = actor.Link(lPID)
 	if !assert.Nil(t, err) {return}
 
+	// a monitor is triggered only once, so each of the exits below is tested on a new actor which is monitored by
+	// monitorActor and linked to linkedActor.
+	monitoredActor := func() *Actor {
+		actor, pid := getActorForTest(t)
+		_, err := monitorActor.Monitor(pid)
+		assert.Nil(t, err)
+		err = actor.Link(lPID)
+		assert.Nil(t, err)
+		return actor
+	}
+
 	// trapping exit messages so we can check if they have received the corresponding system
 	// message from our actor
 	monitorActor.SetTrapExit(true)
@@ -509,7 +778,7 @@ func TestActor_dispose(t *testing.T) {
 
 		err = monitorActor.ReceiveWithTimeout(timeout, func(message interface{}) (loop bool) {
 			assert.NotNil(t, message)
-			assert.IsType(t, sysmsg.NormalExit{}, message)
+			assert.IsType(t, sysmsg.Down{}, message)
 			return false
 		})
 		assert.Nil(t, err) // no timeout error, so the monitor actor has received the exit message
@@ -526,7 +795,7 @@ func TestActor_dispose(t *testing.T) {
 		defer func() {
 			err = monitorActor.ReceiveWithTimeout(timeout, func(message interface{}) (loop bool) {
 				assert.NotNil(t, message)
-				assert.IsType(t, sysmsg.AbnormalExit{}, message)
+				assert.IsType(t, sysmsg.Down{}, message)
 				return false
 			})
 			assert.Nil(t, err)
@@ -539,17 +808,18 @@ func TestActor_dispose(t *testing.T) {
 			assert.Nil(t, err)
 		}()
 
+		actor := monitoredActor()
 		defer actor.dispose()
 		// panic-ing with an AbnormalExit to simulate the situation
 		_, exitMsgSenderPID := getActorForTest(t)
-		panic(sysmsg.NewAbnormalExitMsg(exitMsgSenderPID.InternalPID(), "just testing", nil))
+		panic(sysmsg.NewAbnormalExitMsg(exitMsgSenderPID, reason.FromError(errors.New("just testing")), nil))
 	})
 
 	t.Run("exited because of receiving a NormalExit msg", func(t *testing.T) {
 		defer func() {
 			err = monitorActor.ReceiveWithTimeout(timeout, func(message interface{}) (loop bool) {
 				assert.NotNil(t, message)
-				assert.IsType(t, sysmsg.NormalExit{}, message)
+				assert.IsType(t, sysmsg.Down{}, message)
 				return false
 			})
 			assert.Nil(t, err)
@@ -562,15 +832,23 @@ func TestActor_dispose(t *testing.T) {
 			assert.Nil(t, err)
 		}()
 
+		actor := monitoredActor()
 		defer actor.dispose()
 		_, exitMsgSenderPID := getActorForTest(t)
-		panic(sysmsg.NewNormalExitMsg(exitMsgSenderPID.InternalPID(), nil))
+		panic(sysmsg.NewNormalExitMsg(exitMsgSenderPID, nil))
 	})
 
 	t.Run("exited because of panic-ing due to an unknown err", func(t *testing.T) {
 		defer func() {
 			err = monitorActor.ReceiveWithTimeout(timeout, func(message interface{}) (loop bool) {
 				assert.NotNil(t, message)
+				down, ok := message.(sysmsg.Down)
+				if !assert.True(t, ok) {return false}
+				// the panic's value and stack are kept in the exit reason
+				var panicReason reason.Panic
+				if !assert.True(t, errors.As(down.Reason, &panicReason)) {return false}
+				assert.Equal(t, "unknown situation", panicReason.Value)
+				assert.NotEmpty(t, panicReason.Stack)
 				return false
 			})
 			assert.Nil(t, err)
@@ -582,6 +860,7 @@ func TestActor_dispose(t *testing.T) {
 			assert.Nil(t, err)
 		}()
 
+		actor := monitoredActor()
 		defer actor.dispose()
 		panic("unknown situation")
 	})
@@ -590,7 +869,7 @@ func TestActor_dispose(t *testing.T) {
 		defer func() {
 			err = monitorActor.ReceiveWithTimeout(timeout, func(message interface{}) (loop bool) {
 				assert.NotNil(t, message)
-				assert.IsType(t, sysmsg.AbnormalExit{}, message)
+				assert.IsType(t, sysmsg.Down{}, message)
 				return false
 			})
 			assert.Nil(t, err)
@@ -602,8 +881,9 @@ func TestActor_dispose(t *testing.T) {
 			assert.Contains(t, err.Error(), "timeout")
 		}()
 
+		actor := monitoredActor()
 		defer actor.dispose()
-		panic(sysmsg.NewAbnormalExitMsg(lPID.InternalPID(), "testing", nil))
+		panic(sysmsg.NewAbnormalExitMsg(lPID, reason.FromError(errors.New("testing")), nil))
 	})
 }
 
@@ -0,0 +1,154 @@
+package actortest
+
+import (
+	"github.com/google/uuid"
+	"github.com/hedisam/goactor"
+	"github.com/hedisam/goactor/mailbox"
+	p "github.com/hedisam/goactor/pid"
+	"github.com/hedisam/goactor/reason"
+	"github.com/hedisam/goactor/supervisor"
+	"github.com/hedisam/goactor/supervisor/option"
+	"github.com/hedisam/goactor/supervisor/spec"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// echo sends each message it receives to the probe, and exits once it receives "exit" or panics on "panic".
+func echo(probe *Probe) goactor.ActorFunc {
+	return func(actor *goactor.Actor) {
+		_ = actor.Receive(func(message interface{}) (loop bool) {
+			switch message {
+			case "exit":
+				return false
+			case "panic":
+				panic("echo panicked")
+			}
+			_ = goactor.Send(probe.PID(), message)
+			return true
+		})
+	}
+}
+
+func TestProbe_ExpectMsg(t *testing.T) {
+	probe := NewProbe(t)
+	pid := goactor.Spawn(echo(probe), nil)
+
+	assert.Nil(t, goactor.Send(pid, "hello"))
+	assert.True(t, probe.ExpectMsg("hello"))
+	assert.True(t, probe.ExpectNoMsg(10*time.Millisecond))
+
+	// a failed expectation is reported to the test
+	inner := &testing.T{}
+	failing := NewProbe(inner)
+	failing.SetTimeout(10 * time.Millisecond)
+	assert.False(t, failing.ExpectMsg("never sent"))
+	assert.True(t, inner.Failed())
+}
+
+func TestProbe_ExpectExit(t *testing.T) {
+	probe := NewProbe(t)
+	pid, err := goactor.SpawnLink(probe.Actor(), echo(probe), nil)
+	if !assert.Nil(t, err) {return}
+	assert.Nil(t, goactor.Send(pid, "panic"))
+	exitReason, ok := probe.ExpectExit(pid)
+	if !assert.True(t, ok) {return}
+	assert.False(t, reason.IsNormal(exitReason))
+
+	pid, err = goactor.SpawnLink(probe.Actor(), echo(probe), nil)
+	if !assert.Nil(t, err) {return}
+	assert.Nil(t, goactor.Send(pid, "exit"))
+	exitReason, ok = probe.ExpectExit(pid)
+	assert.True(t, ok)
+	assert.True(t, reason.IsNormal(exitReason))
+}
+
+func TestProbe_ExpectDown(t *testing.T) {
+	probe := NewProbe(t)
+	pid := goactor.Spawn(echo(probe), nil)
+	ref, ok := probe.Monitor(pid)
+	if !assert.True(t, ok) {return}
+
+	assert.Nil(t, goactor.Send(pid, "exit"))
+	down, ok := probe.ExpectDown(pid)
+	assert.True(t, ok)
+	assert.Equal(t, ref, down.Ref)
+}
+
+func TestClock_ReceiveTimeout(t *testing.T) {
+	probe := NewProbe(t)
+	c := NewClock()
+	goactor.Spawn(func(actor *goactor.Actor) {
+		err := actor.ReceiveWithTimeout(time.Minute, func(message interface{}) (loop bool) {
+			return true
+		})
+		_ = goactor.Send(probe.PID(), err)
+	}, MailboxBuilder(c))
+
+	// the minute long timeout elapses as soon as the clock is advanced
+	c.BlockUntil(1)
+	assert.True(t, probe.ExpectNoMsg(10*time.Millisecond))
+	c.Advance(time.Minute)
+	assert.True(t, probe.ExpectMsg(mailbox.ErrMailboxReceiveTimeout))
+}
+
+func TestClock_SupervisorRestartsPeriod(t *testing.T) {
+	probe := NewProbe(t)
+	c := NewClock()
+	options := option.NewOptions(option.StrategyOptionOneForOne, 2, 5)
+	options.Clock = c
+	name := "crashing " + uuid.New().String()
+	worker := spec.NewWorkerSpec(name, spec.RestartAlways, func(actor *goactor.Actor) {
+		_ = goactor.Send(probe.PID(), actor.Self())
+		echo(probe)(actor)
+	})
+	sup, err := supervisor.Start(options, worker)
+	if !assert.Nil(t, err) {return}
+	if _, ok := probe.Monitor(sup.PID()); !assert.True(t, ok) {return}
+
+	// started waits for the child to be started, and for the supervisor to be done with its restart
+	started := func() *p.PID {
+		msg, ok := probe.ReceiveMsg()
+		if !assert.True(t, ok) || !assert.IsType(t, &p.PID{}, msg) {return nil}
+		_, err := sup.WhichChildren(time.Second)
+		assert.Nil(t, err)
+		return msg.(*p.PID)
+	}
+	crash := func(pid *p.PID) bool {
+		return pid != nil && assert.Nil(t, goactor.Send(pid, "panic"))
+	}
+
+	if !crash(started()) || !crash(started()) {return}
+	pid := started()
+	// the two restarts fall out of the period once the clock is advanced, so the supervisor doesn't give up
+	c.Advance(10 * time.Second)
+	if !crash(pid) || !crash(started()) || !crash(started()) {return}
+	_, ok := probe.ExpectDown(sup.PID())
+	assert.True(t, ok)
+}
+
+func TestAssertTree(t *testing.T) {
+	id := uuid.New().String()
+	idle := func(actor *goactor.Actor) {
+		_ = actor.Receive(func(message interface{}) (loop bool) {
+			return true
+		})
+	}
+	sup, err := supervisor.Start(option.OneForOneStrategyOption(),
+		spec.NewWorkerSpec("a "+id, spec.RestartAlways, idle),
+		spec.NewSupervisorSpec("sub "+id, spec.RestartAlways, option.OneForAllStrategyOption(),
+			spec.NewWorkerSpec("c "+id, spec.RestartAlways, idle),
+			spec.NewWorkerSpec("b "+id, spec.RestartAlways, idle),
+		),
+	)
+	if !assert.Nil(t, err) {return}
+
+	assert.True(t, AssertTree(t, sup,
+		Supervisor("sub "+id, Worker("b "+id), Worker("c "+id)),
+		Worker("a "+id),
+	))
+
+	inner := &testing.T{}
+	assert.False(t, AssertTree(inner, sup, Worker("a "+id)))
+	assert.True(t, inner.Failed())
+}
@@ -0,0 +1,24 @@
+package actortest
+
+import (
+	"github.com/hedisam/goactor"
+	"github.com/hedisam/goactor/clock"
+	"github.com/hedisam/goactor/mailbox"
+	"time"
+)
+
+// NewClock returns a fake clock which is set to a fixed time. It can be injected into mailboxes by MailboxBuilder,
+// into state machines by statem.Machine.Clock, and into supervisors by option.Options.Clock.
+func NewClock() *clock.Fake {
+	return clock.NewFake(time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC))
+}
+
+// MailboxBuilder returns a builder of channel mailboxes whose timeouts are measured by the given clock, so a receive
+// timeout only elapses once the clock is advanced. The receive timer is created when the receive begins, so a test
+// can wait for it by clock.Fake.BlockUntil. The mailboxes have no send timeout, since the clock doesn't move on its
+// own: sending to a full mailbox blocks until there's room.
+func MailboxBuilder(c clock.Clock) goactor.MailboxBuilderFunc {
+	return func() goactor.Mailbox {
+		return mailbox.NewChanMailbox(mailbox.DefaultUserMailboxCap, mailbox.DefaultSysMailboxCap, 0).SetClock(c)
+	}
+}
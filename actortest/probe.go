@@ -0,0 +1,167 @@
+// Package actortest helps testing actors: a probe actor which asserts the messages it receives, a fake clock to
+// inject into mailboxes, timers and supervisors, and helpers to assert the shape of a supervision tree.
+package actortest
+
+import (
+	"fmt"
+	"github.com/hedisam/goactor"
+	p "github.com/hedisam/goactor/pid"
+	"github.com/hedisam/goactor/reason"
+	"github.com/hedisam/goactor/sysmsg"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// DefaultTimeout is how long a probe waits for a message, unless it's changed by Probe.SetTimeout.
+const DefaultTimeout = 3 * time.Second
+
+// Probe is an actor driven by a test. It traps exits, so the exits of the actors it's linked to are received as
+// messages, and it's disposed once the test finishes.
+// The Expect methods report their failures to the test and return false, so a test can stop right away.
+type Probe struct {
+	t       testing.TB
+	actor   *goactor.Actor
+	timeout time.Duration
+}
+
+// NewProbe returns a new probe for the test.
+func NewProbe(t testing.TB) *Probe {
+	actor, dispose := goactor.NewParentActor(nil)
+	actor.SetTrapExit(true)
+	t.Cleanup(dispose)
+	return &Probe{t: t, actor: actor, timeout: DefaultTimeout}
+}
+
+// PID returns the probe's pid.
+func (probe *Probe) PID() *p.PID {
+	return probe.actor.Self()
+}
+
+// Actor returns the probe's actor, e.g. to be given as the parent to goactor.SpawnLink.
+func (probe *Probe) Actor() *goactor.Actor {
+	return probe.actor
+}
+
+// SetTimeout sets how long the probe waits for a message.
+func (probe *Probe) SetTimeout(timeout time.Duration) {
+	probe.timeout = timeout
+}
+
+// Link links the probe to the actor, so the probe receives its exit.
+func (probe *Probe) Link(pid *p.PID) bool {
+	probe.t.Helper()
+	err := probe.actor.Link(pid)
+	if err != nil {
+		probe.t.Errorf("probe failed to link: %v", err)
+		return false
+	}
+	return true
+}
+
+// Monitor makes the probe monitor the actor, so the probe receives its Down message.
+func (probe *Probe) Monitor(pid *p.PID) (sysmsg.MonitorRef, bool) {
+	probe.t.Helper()
+	ref, err := probe.actor.Monitor(pid)
+	if err != nil {
+		probe.t.Errorf("probe failed to monitor: %v", err)
+		return "", false
+	}
+	return ref, true
+}
+
+// ReceiveMsg returns the next message the probe receives, and false if none is received in time.
+func (probe *Probe) ReceiveMsg() (interface{}, bool) {
+	probe.t.Helper()
+	msg, err := probe.receive(probe.timeout)
+	if err != nil {
+		probe.t.Errorf("probe didn't receive any message: %v", err)
+		return nil, false
+	}
+	return msg, true
+}
+
+// ExpectMsg asserts that the next message the probe receives is equal to the expected one.
+func (probe *Probe) ExpectMsg(expected interface{}) bool {
+	probe.t.Helper()
+	msg, ok := probe.ReceiveMsg()
+	if !ok {
+		return false
+	}
+	if !reflect.DeepEqual(expected, msg) {
+		probe.t.Errorf("probe received an unexpected message\nexpected: %#v\nreceived: %#v", expected, msg)
+		return false
+	}
+	return true
+}
+
+// ExpectNoMsg asserts that the probe doesn't receive any message for the given duration.
+func (probe *Probe) ExpectNoMsg(d time.Duration) bool {
+	probe.t.Helper()
+	msg, err := probe.receive(d)
+	if err == nil {
+		probe.t.Errorf("probe received an unexpected message: %#v", msg)
+		return false
+	}
+	return true
+}
+
+// ExpectExit asserts that the next message the probe receives is the exit of the given linked actor, and returns
+// the exit's reason.
+func (probe *Probe) ExpectExit(pid *p.PID) (reason.Reason, bool) {
+	probe.t.Helper()
+	msg, ok := probe.ReceiveMsg()
+	if !ok {
+		return nil, false
+	}
+	var exit sysmsg.SystemMessage
+	switch m := msg.(type) {
+	case sysmsg.NormalExit:
+		exit = m
+	case sysmsg.AbnormalExit:
+		exit = m
+	default:
+		probe.t.Errorf("probe expected an exit message, received: %#v", msg)
+		return nil, false
+	}
+	if !p.Equal(exit.Sender(), pid) {
+		probe.t.Errorf("probe expected the exit of %s, received the exit of %s", describe(pid), describe(exit.Sender()))
+		return nil, false
+	}
+	return exit.Reason(), true
+}
+
+// ExpectDown asserts that the next message the probe receives is the Down message of the given monitored actor.
+func (probe *Probe) ExpectDown(pid *p.PID) (sysmsg.Down, bool) {
+	probe.t.Helper()
+	msg, ok := probe.ReceiveMsg()
+	if !ok {
+		return sysmsg.Down{}, false
+	}
+	down, ok := msg.(sysmsg.Down)
+	if !ok {
+		probe.t.Errorf("probe expected a down message, received: %#v", msg)
+		return sysmsg.Down{}, false
+	}
+	if !p.Equal(down.PID, pid) {
+		probe.t.Errorf("probe expected the down message of %s, received the one of %s", describe(pid), describe(down.PID))
+		return sysmsg.Down{}, false
+	}
+	return down, true
+}
+
+func (probe *Probe) receive(timeout time.Duration) (interface{}, error) {
+	var received interface{}
+	err := probe.actor.ReceiveWithTimeout(timeout, func(message interface{}) (loop bool) {
+		received = message
+		return false
+	})
+	return received, err
+}
+
+func describe(pid *p.PID) string {
+	if pid == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("actor %s", pid.ID())
+}
@@ -0,0 +1,109 @@
+package actortest
+
+import (
+	"fmt"
+	"github.com/hedisam/goactor/supervisor/supref"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Node is a child of a supervision tree.
+type Node struct {
+	Name       string
+	Supervisor bool
+	Dead       bool
+	// Children are the children of a supervisor node.
+	Children []Node
+}
+
+// Worker returns the node of a running worker.
+func Worker(name string) Node {
+	return Node{Name: name}
+}
+
+// Supervisor returns the node of a running supervisor with the given children.
+func Supervisor(name string, children ...Node) Node {
+	return Node{Name: name, Supervisor: true, Children: children}
+}
+
+// Tree returns the children of the supervisor, along with the children of its supervisor children, recursively.
+// The children of each supervisor are sorted by name.
+func Tree(sup *supref.SupRef, timeout time.Duration) ([]Node, error) {
+	children, err := sup.WhichChildren(timeout)
+	if err != nil {
+		return nil, fmt.Errorf("actortest: couldn't get the children of the supervisor %s: %w", sup.PID().ID(), err)
+	}
+
+	nodes := make([]Node, 0, len(children))
+	for _, child := range children {
+		node := Node{Name: child.Name, Supervisor: child.IsSupervisor, Dead: child.Dead}
+		if child.IsSupervisor && !child.Dead {
+			ref, err := supref.ToSupervisorRef(child.PID)
+			if err != nil {
+				return nil, fmt.Errorf("actortest: %w", err)
+			}
+			node.Children, err = Tree(ref, timeout)
+			if err != nil {
+				return nil, err
+			}
+		}
+		nodes = append(nodes, node)
+	}
+	sortNodes(nodes)
+	return nodes, nil
+}
+
+// AssertTree asserts that the supervision tree under the supervisor has the expected shape. The order of the
+// children doesn't matter.
+func AssertTree(t testing.TB, sup *supref.SupRef, expected ...Node) bool {
+	t.Helper()
+	actual, err := Tree(sup, DefaultTimeout)
+	if err != nil {
+		t.Errorf("%v", err)
+		return false
+	}
+
+	expected = append([]Node(nil), expected...)
+	sortNodes(expected)
+	if render(expected) != render(actual) {
+		t.Errorf("unexpected supervision tree\nexpected:\n%s\nactual:\n%s", render(expected), render(actual))
+		return false
+	}
+	return true
+}
+
+func sortNodes(nodes []Node) {
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Name < nodes[j].Name
+	})
+	for i := range nodes {
+		if len(nodes[i].Children) > 0 {
+			nodes[i].Children = append([]Node(nil), nodes[i].Children...)
+			sortNodes(nodes[i].Children)
+		}
+	}
+}
+
+// render returns the tree as indented lines, so two trees can be compared and printed.
+func render(nodes []Node) string {
+	var b strings.Builder
+	var walk func(nodes []Node, depth int)
+	walk = func(nodes []Node, depth int) {
+		for _, node := range nodes {
+			kind := "worker"
+			if node.Supervisor {
+				kind = "supervisor"
+			}
+			state := ""
+			if node.Dead {
+				state = " (dead)"
+			}
+			fmt.Fprintf(&b, "%s- %s %s%s\n", strings.Repeat("  ", depth), kind, node.Name, state)
+			walk(node.Children, depth+1)
+		}
+	}
+	walk(nodes, 0)
+	return b.String()
+}
@@ -0,0 +1,203 @@
+package agent
+
+import (
+	"fmt"
+	"github.com/hedisam/goactor"
+	p "github.com/hedisam/goactor/pid"
+	"github.com/hedisam/goactor/process"
+	"github.com/hedisam/goactor/reason"
+	"github.com/hedisam/goactor/supervisor/spec"
+	"github.com/hedisam/goactor/sysmsg"
+	"time"
+)
+
+var ErrUnknownResponse = fmt.Errorf("agent: unknown response")
+
+// Agent is a reference to an actor which holds a piece of state. The state is only accessed by the agent's actor,
+// so the functions passed to an agent are run one at a time, in the same order they were received. The agent exits
+// if any of them panics.
+type Agent struct {
+	pid *p.PID
+}
+
+type getRequest struct {
+	fn        func(state interface{}) interface{}
+	requester *p.PID
+}
+
+type updateRequest struct {
+	fn        func(state interface{}) interface{}
+	requester *p.PID
+}
+
+type getAndUpdateRequest struct {
+	fn        func(state interface{}) (interface{}, interface{})
+	requester *p.PID
+}
+
+type castRequest struct {
+	fn func(state interface{}) interface{}
+}
+
+type stopRequest struct {
+	requester *p.PID
+}
+
+type response struct {
+	value interface{}
+}
+
+// Start spawns a new agent whose initial state is returned by initFn. It returns once initFn has returned, and
+// returns an error if it panics.
+func Start(initFn func() interface{}) (*Agent, error) {
+	initErr := make(chan error, 1)
+	pid := goactor.Spawn(actorFunc(initFn, initErr), nil)
+	err := <-initErr
+	if err != nil {
+		return nil, fmt.Errorf("agent: init failed: %w", err)
+	}
+	return &Agent{pid: pid}, nil
+}
+
+// ChildSpec returns a worker spec which starts an agent under a supervisor. The agent is registered by the given name
+// so it can be found by FromName, even after it's been restarted with a fresh state.
+func ChildSpec(name string, restartWhen int, initFn func() interface{}) spec.WorkerSpec {
+	return spec.NewWorkerSpec(name, restartWhen, actorFunc(initFn, nil))
+}
+
+// FromName returns the agent which is registered by the given name.
+func FromName(name string) (*Agent, bool) {
+	pid, ok := process.WhereIs(name)
+	if !ok {
+		return nil, false
+	}
+	return &Agent{pid: pid}, true
+}
+
+// PID returns the agent's pid.
+func (a *Agent) PID() *p.PID {
+	return a.pid
+}
+
+// Get returns the value computed by fn from the agent's state.
+func (a *Agent) Get(fn func(state interface{}) interface{}, timeout time.Duration) (interface{}, error) {
+	return a.request(func(requester *p.PID) interface{} {
+		return getRequest{fn: fn, requester: requester}
+	}, timeout)
+}
+
+// Update replaces the agent's state by the one returned by fn, and returns once it's done.
+func (a *Agent) Update(fn func(state interface{}) interface{}, timeout time.Duration) error {
+	_, err := a.request(func(requester *p.PID) interface{} {
+		return updateRequest{fn: fn, requester: requester}
+	}, timeout)
+	return err
+}
+
+// GetAndUpdate replaces the agent's state by the second value returned by fn, and returns the first one.
+func (a *Agent) GetAndUpdate(fn func(state interface{}) (interface{}, interface{}), timeout time.Duration) (interface{}, error) {
+	return a.request(func(requester *p.PID) interface{} {
+		return getAndUpdateRequest{fn: fn, requester: requester}
+	}, timeout)
+}
+
+// Cast replaces the agent's state by the one returned by fn without waiting for it to be done.
+func (a *Agent) Cast(fn func(state interface{}) interface{}) error {
+	return goactor.Send(a.pid, castRequest{fn: fn})
+}
+
+// Stop stops the agent once it's done with the requests received before this one.
+func (a *Agent) Stop(timeout time.Duration) error {
+	_, err := a.request(func(requester *p.PID) interface{} {
+		return stopRequest{requester: requester}
+	}, timeout)
+	return err
+}
+
+func (a *Agent) request(build func(requester *p.PID) interface{}, timeout time.Duration) (interface{}, error) {
+	future := goactor.NewFutureActor()
+	// the agent is monitored, so the request fails with the agent's exit reason if it exits before replying. the
+	// future passes the agent's Down after its reply, if it has replied before exiting, e.g. to a stop request.
+	ref, err := future.Monitor(a.pid)
+	if err != nil {
+		return nil, fmt.Errorf("agent: couldn't monitor the agent: %w", err)
+	}
+	defer func() {
+		_ = future.Demonitor(ref)
+	}()
+	err = goactor.Send(a.pid, build(future.Self()))
+	if err != nil {
+		return nil, fmt.Errorf("agent: couldn't deliver request: %w", err)
+	}
+
+	var resp *response
+	var down *sysmsg.Down
+	var respErr error
+	err = future.ReceiveWithTimeout(timeout, func(message interface{}) (loop bool) {
+		switch msg := message.(type) {
+		case response:
+			resp = &msg
+		case sysmsg.Down:
+			down = &msg
+		default:
+			respErr = fmt.Errorf("%w: %v", ErrUnknownResponse, message)
+		}
+		return false
+	})
+	if err != nil {
+		return nil, fmt.Errorf("agent: request failed: %w", err)
+	} else if resp != nil {
+		return resp.value, nil
+	} else if down != nil {
+		return nil, fmt.Errorf("agent: the agent exited: %w", down.Reason)
+	}
+	return nil, respErr
+}
+
+// actorFunc returns the agent's actor func. initErr, if not nil, gets the outcome of initFn.
+func actorFunc(initFn func() interface{}, initErr chan<- error) goactor.ActorFunc {
+	return func(actor *goactor.Actor) {
+		state := initState(initFn, initErr)
+
+		_ = actor.Receive(func(message interface{}) (loop bool) {
+			switch msg := message.(type) {
+			case getRequest:
+				reply(msg.requester, msg.fn(state))
+			case updateRequest:
+				state = msg.fn(state)
+				reply(msg.requester, nil)
+			case getAndUpdateRequest:
+				var value interface{}
+				value, state = msg.fn(state)
+				reply(msg.requester, value)
+			case castRequest:
+				state = msg.fn(state)
+			case stopRequest:
+				reply(msg.requester, nil)
+				return false
+			}
+			return true
+		})
+	}
+}
+
+func initState(initFn func() interface{}, initErr chan<- error) (state interface{}) {
+	if initErr == nil {
+		return initFn()
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			exitReason := reason.FromPanic(r)
+			initErr <- exitReason
+			panic(exitReason)
+		}
+	}()
+	state = initFn()
+	initErr <- nil
+	return state
+}
+
+func reply(requester *p.PID, value interface{}) {
+	// the requester could have stopped waiting, so there's nothing to do if it fails.
+	_ = goactor.Send(requester, response{value: value})
+}
@@ -0,0 +1,164 @@
+package agent
+
+import (
+	"errors"
+	"github.com/hedisam/goactor/reason"
+	"github.com/hedisam/goactor/supervisor"
+	"github.com/hedisam/goactor/supervisor/option"
+	"github.com/hedisam/goactor/supervisor/spec"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+func increment(state interface{}) interface{} {
+	return state.(int) + 1
+}
+
+func current(state interface{}) interface{} {
+	return state
+}
+
+func TestStart(t *testing.T) {
+	agent, err := Start(func() interface{} {
+		return 10
+	})
+	if !assert.Nil(t, err) {return}
+	defer agent.Stop(time.Second)
+
+	value, err := agent.Get(current, time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, 10, value)
+
+	_, err = Start(func() interface{} {
+		panic("init failed")
+	})
+	var panicReason reason.Panic
+	if !assert.True(t, errors.As(err, &panicReason)) {return}
+	assert.Equal(t, "init failed", panicReason.Value)
+}
+
+func TestAgent_Update(t *testing.T) {
+	agent, err := Start(func() interface{} {
+		return 0
+	})
+	if !assert.Nil(t, err) {return}
+	defer agent.Stop(time.Second)
+
+	// the updates are serialized
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Nil(t, agent.Update(increment, time.Second))
+		}()
+	}
+	wg.Wait()
+
+	value, err := agent.Get(current, time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, 50, value)
+}
+
+func TestAgent_GetAndUpdate(t *testing.T) {
+	agent, err := Start(func() interface{} {
+		return 1
+	})
+	if !assert.Nil(t, err) {return}
+	defer agent.Stop(time.Second)
+
+	value, err := agent.GetAndUpdate(func(state interface{}) (interface{}, interface{}) {
+		return state, state.(int) * 2
+	}, time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, value)
+
+	value, err = agent.Get(current, time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, value)
+}
+
+func TestAgent_Cast(t *testing.T) {
+	agent, err := Start(func() interface{} {
+		return 0
+	})
+	if !assert.Nil(t, err) {return}
+	defer agent.Stop(time.Second)
+
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, agent.Cast(increment))
+	}
+	// Get is processed after the casts which were sent before it
+	value, err := agent.Get(current, time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, value)
+}
+
+func TestAgent_Stop(t *testing.T) {
+	agent, err := Start(func() interface{} {
+		return 0
+	})
+	if !assert.Nil(t, err) {return}
+
+	assert.Nil(t, agent.Stop(time.Second))
+	assert.Eventually(t, func() bool {
+		return agent.Cast(increment) != nil
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestAgent_Crash(t *testing.T) {
+	agent, err := Start(func() interface{} {
+		return 0
+	})
+	if !assert.Nil(t, err) {return}
+
+	// with no timeout, the request would wait forever if the agent's exit went unnoticed
+	result := make(chan error, 1)
+	go func() {
+		_, err := agent.Get(func(state interface{}) interface{} {
+			panic("get failed")
+		}, 0)
+		result <- err
+	}()
+	select {
+	case err = <-result:
+		var panicReason reason.Panic
+		if !assert.True(t, errors.As(err, &panicReason), err) {return}
+		assert.Equal(t, "get failed", panicReason.Value)
+	case <-time.After(time.Second):
+		t.Fatal("the request didn't fail")
+	}
+
+	// the agent has already exited
+	_, err = agent.Get(current, time.Second)
+	assert.NotNil(t, err)
+}
+
+func TestChildSpec(t *testing.T) {
+	name := "supervised agent"
+	sup, err := supervisor.Start(option.OneForOneStrategyOption(), ChildSpec(name, spec.RestartAlways, func() interface{} {
+		return 0
+	}))
+	if !assert.Nil(t, err) {return}
+
+	agent, ok := FromName(name)
+	if !assert.True(t, ok) {return}
+	assert.Nil(t, agent.Update(increment, time.Second))
+	value, err := agent.Get(current, time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, value)
+
+	// the agent is restarted with a fresh state
+	assert.Nil(t, sup.TerminateChild(name, time.Second))
+	assert.Nil(t, sup.RestartChild(name, time.Second))
+	agent, ok = FromName(name)
+	if !assert.True(t, ok) {return}
+	value, err = agent.Get(current, time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, value)
+
+	_, ok = FromName("unknown agent")
+	assert.False(t, ok)
+}
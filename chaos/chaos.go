@@ -0,0 +1,297 @@
+// Package chaos injects faults into supervision trees, to prove that they recover: it kills supervised workers, delays
+// and drops the messages sent to them, and fails their starts, according to a policy.
+//
+// The faults are picked by a pseudo-random generator seeded by the policy's seed. Which fault hits which message
+// depends on the order the actors send their messages in, so combined with sched.Run, and a fake clock for the
+// delays, the same seed injects the same faults.
+package chaos
+
+import (
+	"fmt"
+	"github.com/hedisam/goactor/clock"
+	"github.com/hedisam/goactor/internal/fault"
+	"github.com/hedisam/goactor/internal/intlpid"
+	"github.com/hedisam/goactor/internal/pidconv"
+	p "github.com/hedisam/goactor/pid"
+	"github.com/hedisam/goactor/reason"
+	"github.com/hedisam/goactor/sysmsg"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+var ErrInjected = fmt.Errorf("chaos: injected fault")
+var ErrAlreadyStarted = fmt.Errorf("chaos: a monkey has already been started")
+var ErrInvalidPolicy = fmt.Errorf("chaos: invalid policy")
+
+// Policy configures the faults injected by a Monkey.
+type Policy struct {
+	Seed int64
+	// KillInterval is how often one of the targeted workers is killed, if it's greater than zero. Workers can be
+	// killed on demand by Monkey.Kill too.
+	KillInterval time.Duration
+	// DropRate is the probability of a message sent to a targeted child being dropped.
+	DropRate float64
+	// DelayRate is the probability of a message sent to a targeted child being delayed, up to MaxDelay.
+	DelayRate float64
+	MaxDelay  time.Duration
+	// FailStartRate is the probability of the start of a targeted child failing, whether it's the first start or a
+	// restart.
+	FailStartRate float64
+	// Supervisors limits the faults to the children of these supervisors, and Specs to the children with these spec
+	// names. The children of all supervisors are targeted if both are empty.
+	Supervisors []*p.PID
+	Specs       []string
+	// Clock measures the kill interval and the delays, the real clock is used if it's nil.
+	Clock clock.Clock
+}
+
+// Stats are the number of faults a Monkey has injected.
+type Stats struct {
+	Kills        int
+	Drops        int
+	Delays       int
+	FailedStarts int
+}
+
+// Monkey injects faults into the targeted children of supervisors, from the moment it's started until it's stopped.
+// Only the children which are started while it's running are targeted.
+type Monkey struct {
+	policy Policy
+	clock  clock.Clock
+
+	mu   sync.Mutex
+	rand *rand.Rand
+	// children are the targeted children, in the order they were started.
+	children  []child
+	stats     Stats
+	killTimer clock.Timer
+	stopped   bool
+}
+
+type child struct {
+	supervisor *p.PID
+	pid        *p.PID
+}
+
+var (
+	startedMu sync.Mutex
+	started   *Monkey
+)
+
+// Start starts injecting faults according to the policy. Only one monkey can be running at a time.
+func Start(policy Policy) (*Monkey, error) {
+	err := policy.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	startedMu.Lock()
+	defer startedMu.Unlock()
+	if started != nil {
+		return nil, ErrAlreadyStarted
+	}
+
+	m := &Monkey{
+		policy: policy,
+		clock:  clock.OrReal(policy.Clock),
+		rand:   rand.New(rand.NewSource(policy.Seed)),
+	}
+	started = m
+	intlpid.SetSendFilter(m.filter)
+	fault.SetStartHook(startHook{m: m})
+	if policy.KillInterval > 0 {
+		m.killTimer = m.clock.AfterFunc(policy.KillInterval, m.killPeriodically)
+	}
+	return m, nil
+}
+
+// Stop stops injecting faults. The messages which have already been delayed are still delivered.
+func (m *Monkey) Stop() {
+	startedMu.Lock()
+	defer startedMu.Unlock()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.stopped {
+		return
+	}
+	m.stopped = true
+	if m.killTimer != nil {
+		m.killTimer.Stop()
+	}
+	if started == m {
+		started = nil
+		intlpid.SetSendFilter(nil)
+		fault.SetStartHook(nil)
+	}
+}
+
+// Stats returns the number of faults injected so far.
+func (m *Monkey) Stats() Stats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.stats
+}
+
+// Kill kills one of the targeted workers which are alive, picked at random, and returns its pid. It returns false if
+// there's none.
+// The worker is shut down by intlpid.Shutdown, the way supervisors shut down their children, and its supervisor gets a
+// KillExit message on its behalf, so the supervisor handles it like any other killed child. Supervisor children
+// aren't killed, they're targeted through their own children.
+func (m *Monkey) Kill() (*p.PID, bool) {
+	m.mu.Lock()
+	if m.stopped {
+		m.mu.Unlock()
+		return nil, false
+	}
+	m.prune()
+	var workers []child
+	for _, c := range m.children {
+		if !c.pid.IsSupervisor() {
+			workers = append(workers, c)
+		}
+	}
+	if len(workers) == 0 {
+		m.mu.Unlock()
+		return nil, false
+	}
+	victim := workers[m.rand.Intn(len(workers))]
+	m.stats.Kills++
+	m.mu.Unlock()
+
+	// the supervisor is told first, so it gets the KillExit before any exit message the worker sends while it's
+	// shutting down.
+	killed := sysmsg.NewKillMessage(victim.pid, reason.Killed, nil)
+	_ = intlpid.SendSystemMessage(pidconv.Internal(victim.supervisor), killed)
+	intlpid.Shutdown(pidconv.Internal(victim.pid), killed)
+	return victim.pid, true
+}
+
+func (m *Monkey) killPeriodically() {
+	m.Kill()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.stopped {
+		m.killTimer = m.clock.AfterFunc(m.policy.KillInterval, m.killPeriodically)
+	}
+}
+
+// filter drops or delays the messages sent to the targeted children.
+func (m *Monkey) filter(to intlpid.InternalPID, _ interface{}, push func() error) error {
+	m.mu.Lock()
+	if m.stopped || !m.targeted(to.ID()) {
+		m.mu.Unlock()
+		return push()
+	}
+	r := m.rand.Float64()
+	switch {
+	case r < m.policy.DropRate:
+		m.stats.Drops++
+		m.mu.Unlock()
+		return nil
+	case r < m.policy.DropRate+m.policy.DelayRate:
+		delay := time.Duration(m.rand.Int63n(int64(m.policy.MaxDelay))) + 1
+		m.stats.Delays++
+		m.mu.Unlock()
+		m.clock.AfterFunc(delay, func() {
+			_ = push()
+		})
+		return nil
+	}
+	m.mu.Unlock()
+	return push()
+}
+
+// targeted returns true if the pid belongs to one of the targeted children. It must be called with m.mu locked.
+func (m *Monkey) targeted(id string) bool {
+	for _, c := range m.children {
+		if c.pid.ID() == id {
+			return true
+		}
+	}
+	return false
+}
+
+// startHook fails the starts of the targeted children, and keeps track of the ones that are started.
+type startHook struct {
+	m *Monkey
+}
+
+func (h startHook) BeforeStart(supervisor *p.PID, name string) error {
+	m := h.m
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.stopped || !m.policy.targets(supervisor, name) {
+		return nil
+	}
+	if m.rand.Float64() < m.policy.FailStartRate {
+		m.stats.FailedStarts++
+		return fmt.Errorf("%w: start of the child %s failed", ErrInjected, name)
+	}
+	return nil
+}
+
+func (h startHook) Started(supervisor *p.PID, name string, pid *p.PID) {
+	m := h.m
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.stopped || !m.policy.targets(supervisor, name) {
+		return
+	}
+	m.prune()
+	m.children = append(m.children, child{supervisor: supervisor, pid: pid})
+}
+
+// prune forgets the children which have exited. It must be called with m.mu locked.
+func (m *Monkey) prune() {
+	alive := m.children[:0]
+	for _, c := range m.children {
+		if intlpid.IsAlive(pidconv.Internal(c.pid)) {
+			alive = append(alive, c)
+		}
+	}
+	m.children = alive
+}
+
+// targets returns true if the child of the supervisor with the given spec name is targeted by the policy.
+func (policy Policy) targets(supervisor *p.PID, name string) bool {
+	if len(policy.Supervisors) > 0 {
+		found := false
+		for _, sup := range policy.Supervisors {
+			if p.Equal(sup, supervisor) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(policy.Specs) > 0 {
+		for _, spec := range policy.Specs {
+			if spec == name {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func (policy Policy) validate() error {
+	for _, rate := range []float64{policy.DropRate, policy.DelayRate, policy.FailStartRate} {
+		if rate < 0 || rate > 1 {
+			return fmt.Errorf("%w: rates must be between 0 and 1", ErrInvalidPolicy)
+		}
+	}
+	if policy.DropRate+policy.DelayRate > 1 {
+		return fmt.Errorf("%w: the drop and delay rates add up to more than 1", ErrInvalidPolicy)
+	}
+	if policy.DelayRate > 0 && policy.MaxDelay <= 0 {
+		return fmt.Errorf("%w: messages can't be delayed without a max delay", ErrInvalidPolicy)
+	}
+	if policy.KillInterval < 0 {
+		return fmt.Errorf("%w: negative kill interval", ErrInvalidPolicy)
+	}
+	return nil
+}
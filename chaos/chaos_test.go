@@ -0,0 +1,154 @@
+package chaos
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"github.com/hedisam/goactor"
+	"github.com/hedisam/goactor/actortest"
+	p "github.com/hedisam/goactor/pid"
+	"github.com/hedisam/goactor/supervisor"
+	"github.com/hedisam/goactor/supervisor/option"
+	"github.com/hedisam/goactor/supervisor/spec"
+	"github.com/hedisam/goactor/supervisor/supref"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// startEcho starts a supervisor with a worker which tells the probe about each of its starts, and echoes the messages
+// it receives to the probe.
+func startEcho(probe *actortest.Probe) (*supref.SupRef, string, error) {
+	name := "echo " + uuid.New().String()
+	worker := spec.NewWorkerSpec(name, spec.RestartTransient, func(actor *goactor.Actor) {
+		_ = goactor.Send(probe.PID(), actor.Self())
+		_ = actor.Receive(func(message interface{}) (loop bool) {
+			_ = goactor.Send(probe.PID(), message)
+			return true
+		})
+	})
+	sup, err := supervisor.Start(option.OneForOneStrategyOption(), worker)
+	return sup, name, err
+}
+
+func TestMonkey_Kill(t *testing.T) {
+	probe := actortest.NewProbe(t)
+	m, err := Start(Policy{Seed: 1})
+	if !assert.Nil(t, err) {return}
+	defer m.Stop()
+
+	sup, name, err := startEcho(probe)
+	if !assert.Nil(t, err) {return}
+	first, ok := probe.ReceiveMsg()
+	if !assert.True(t, ok) {return}
+
+	killed, ok := m.Kill()
+	if !assert.True(t, ok) {return}
+	assert.Equal(t, first, killed)
+
+	// the supervisor restarts the killed worker, even though it's a transient one
+	second, ok := probe.ReceiveMsg()
+	if !assert.True(t, ok) {return}
+	assert.False(t, p.Equal(first.(*p.PID), second.(*p.PID)))
+	actortest.AssertTree(t, sup, actortest.Worker(name))
+	assert.Equal(t, Stats{Kills: 1}, m.Stats())
+}
+
+func TestMonkey_KillInterval(t *testing.T) {
+	probe := actortest.NewProbe(t)
+	c := actortest.NewClock()
+	m, err := Start(Policy{Seed: 1, KillInterval: time.Minute, Clock: c})
+	if !assert.Nil(t, err) {return}
+	defer m.Stop()
+
+	_, _, err = startEcho(probe)
+	if !assert.Nil(t, err) {return}
+	_, ok := probe.ReceiveMsg()
+	if !assert.True(t, ok) {return}
+
+	c.Advance(time.Minute)
+	_, ok = probe.ReceiveMsg()
+	assert.True(t, ok)
+	assert.Equal(t, 1, m.Stats().Kills)
+}
+
+func TestMonkey_DropAndDelay(t *testing.T) {
+	probe := actortest.NewProbe(t)
+	m, err := Start(Policy{Seed: 1, DropRate: 1})
+	if !assert.Nil(t, err) {return}
+
+	_, _, err = startEcho(probe)
+	if !assert.Nil(t, err) {return}
+	msg, ok := probe.ReceiveMsg()
+	if !assert.True(t, ok) {return}
+	worker := msg.(*p.PID)
+
+	assert.Nil(t, goactor.Send(worker, "dropped"))
+	assert.True(t, probe.ExpectNoMsg(20*time.Millisecond))
+	// messages sent to actors that aren't targeted go through
+	assert.Nil(t, goactor.Send(probe.PID(), "not targeted"))
+	assert.True(t, probe.ExpectMsg("not targeted"))
+	assert.Equal(t, Stats{Drops: 1}, m.Stats())
+	m.Stop()
+
+	c := actortest.NewClock()
+	m, err = Start(Policy{Seed: 1, DelayRate: 1, MaxDelay: time.Second, Clock: c})
+	if !assert.Nil(t, err) {return}
+	defer m.Stop()
+	_, _, err = startEcho(probe)
+	if !assert.Nil(t, err) {return}
+	msg, ok = probe.ReceiveMsg()
+	if !assert.True(t, ok) {return}
+	worker = msg.(*p.PID)
+
+	assert.Nil(t, goactor.Send(worker, "delayed"))
+	assert.True(t, probe.ExpectNoMsg(20*time.Millisecond))
+	c.Advance(time.Second)
+	assert.True(t, probe.ExpectMsg("delayed"))
+	assert.Equal(t, Stats{Delays: 1}, m.Stats())
+}
+
+func TestMonkey_FailStart(t *testing.T) {
+	probe := actortest.NewProbe(t)
+	m, err := Start(Policy{Seed: 1, FailStartRate: 1})
+	if !assert.Nil(t, err) {return}
+	defer m.Stop()
+
+	_, _, err = startEcho(probe)
+	assert.True(t, errors.Is(err, ErrInjected))
+	assert.Equal(t, Stats{FailedStarts: 1}, m.Stats())
+}
+
+func TestMonkey_Targets(t *testing.T) {
+	probe := actortest.NewProbe(t)
+	m, err := Start(Policy{Seed: 1, FailStartRate: 1, Specs: []string{"someone else"}})
+	if !assert.Nil(t, err) {return}
+	_, _, err = startEcho(probe)
+	assert.Nil(t, err)
+	m.Stop()
+
+	other, _, err := startEcho(probe)
+	if !assert.Nil(t, err) {return}
+	m, err = Start(Policy{Seed: 1, FailStartRate: 1, Supervisors: []*p.PID{other.PID()}})
+	if !assert.Nil(t, err) {return}
+	defer m.Stop()
+	_, _, err = startEcho(probe)
+	assert.Nil(t, err)
+	assert.Equal(t, Stats{}, m.Stats())
+}
+
+func TestStart_Errors(t *testing.T) {
+	_, err := Start(Policy{DropRate: 0.6, DelayRate: 0.6, MaxDelay: time.Second})
+	assert.True(t, errors.Is(err, ErrInvalidPolicy))
+	_, err = Start(Policy{DelayRate: 0.5})
+	assert.True(t, errors.Is(err, ErrInvalidPolicy))
+
+	m, err := Start(Policy{})
+	if !assert.Nil(t, err) {return}
+	_, err = Start(Policy{})
+	assert.Equal(t, ErrAlreadyStarted, err)
+	m.Stop()
+
+	m, err = Start(Policy{})
+	assert.Nil(t, err)
+	m.Stop()
+}
@@ -0,0 +1,71 @@
+// Package clock abstracts the passage of time for the mailboxes, timers and supervisors, so tests can replace the
+// real clock by a fake one which only moves when it's told to.
+package clock
+
+import (
+	"time"
+)
+
+// Clock tells the time and creates timers.
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+	NewTimer(d time.Duration) Timer
+	// AfterFunc calls f once the duration has elapsed.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a single event timer, see time.Timer.
+type Timer interface {
+	// C returns the channel the time is sent to once the timer fires. It's nil for the timers created by AfterFunc.
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+type realClock struct{}
+
+type realTimer struct {
+	timer *time.Timer
+}
+
+// Real returns the clock which is backed by the time package.
+func Real() Clock {
+	return realClock{}
+}
+
+// OrReal returns the given clock, or the real one if it's nil.
+func OrReal(c Clock) Clock {
+	if c == nil {
+		return realClock{}
+	}
+	return c
+}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) Since(t time.Time) time.Duration {
+	return time.Since(t)
+}
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{timer: time.NewTimer(d)}
+}
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return realTimer{timer: time.AfterFunc(d, f)}
+}
+
+func (t realTimer) C() <-chan time.Time {
+	return t.timer.C
+}
+
+func (t realTimer) Stop() bool {
+	return t.timer.Stop()
+}
+
+func (t realTimer) Reset(d time.Duration) bool {
+	return t.timer.Reset(d)
+}
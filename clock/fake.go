@@ -0,0 +1,132 @@
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Fake is a clock whose time only moves by Advance. The timers which are due are fired by Advance itself, in the
+// order of their deadlines, so once it returns their funcs have been called and their times have been sent.
+type Fake struct {
+	now    time.Time
+	timers map[*fakeTimer]struct{}
+	mu     sync.Mutex
+	// changed is broadcast whenever a timer is added, so BlockUntil can wait for it.
+	changed *sync.Cond
+}
+
+type fakeTimer struct {
+	clock    *Fake
+	deadline time.Time
+	c        chan time.Time
+	fn       func()
+}
+
+// NewFake returns a fake clock which is set to the given time.
+func NewFake(now time.Time) *Fake {
+	f := &Fake{now: now, timers: make(map[*fakeTimer]struct{})}
+	f.changed = sync.NewCond(&f.mu)
+	return f
+}
+
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *Fake) Since(t time.Time) time.Duration {
+	return f.Now().Sub(t)
+}
+
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	return f.addTimer(d, make(chan time.Time, 1), nil)
+}
+
+func (f *Fake) AfterFunc(d time.Duration, fn func()) Timer {
+	return f.addTimer(d, nil, fn)
+}
+
+func (f *Fake) addTimer(d time.Duration, c chan time.Time, fn func()) *fakeTimer {
+	f.mu.Lock()
+	t := &fakeTimer{clock: f, deadline: f.now.Add(d), c: c, fn: fn}
+	f.timers[t] = struct{}{}
+	f.changed.Broadcast()
+	f.mu.Unlock()
+	if d <= 0 {
+		f.Advance(0)
+	}
+	return t
+}
+
+// Advance moves the clock forward and fires the timers which are due.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	f.now = f.now.Add(d)
+	now := f.now
+	var due []*fakeTimer
+	for t := range f.timers {
+		if !t.deadline.After(now) {
+			due = append(due, t)
+			delete(f.timers, t)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].deadline.Before(due[j].deadline)
+	})
+	f.mu.Unlock()
+
+	for _, t := range due {
+		if t.fn != nil {
+			t.fn()
+			continue
+		}
+		select {
+		case t.c <- now:
+		default:
+		}
+	}
+}
+
+// Timers returns the number of timers which are waiting to fire.
+func (f *Fake) Timers() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.timers)
+}
+
+// BlockUntil blocks until at least n timers are waiting to fire. It lets a test wait for the code under test to set
+// its timers before advancing the clock.
+func (f *Fake) BlockUntil(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for len(f.timers) < n {
+		f.changed.Wait()
+	}
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	_, active := t.clock.timers[t]
+	delete(t.clock.timers, t)
+	return active
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.clock.mu.Lock()
+	_, active := t.clock.timers[t]
+	t.deadline = t.clock.now.Add(d)
+	t.clock.timers[t] = struct{}{}
+	t.clock.changed.Broadcast()
+	t.clock.mu.Unlock()
+	if d <= 0 {
+		t.clock.Advance(0)
+	}
+	return active
+}
@@ -0,0 +1,68 @@
+package clock
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestFake_Timers(t *testing.T) {
+	start := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	c := NewFake(start)
+
+	timer := c.NewTimer(time.Second)
+	var fired []string
+	c.AfterFunc(2*time.Second, func() {
+		fired = append(fired, "func")
+	})
+	stopped := c.AfterFunc(time.Second, func() {
+		fired = append(fired, "stopped")
+	})
+	assert.True(t, stopped.Stop())
+	assert.False(t, stopped.Stop())
+	assert.Equal(t, 2, c.Timers())
+
+	c.Advance(999 * time.Millisecond)
+	select {
+	case <-timer.C():
+		t.Fatal("the timer fired early")
+	default:
+	}
+
+	c.Advance(time.Millisecond)
+	assert.Equal(t, start.Add(time.Second), <-timer.C())
+	assert.Equal(t, time.Second, c.Since(start))
+	assert.Empty(t, fired)
+
+	// a fired timer can be reset
+	assert.False(t, timer.Reset(5*time.Second))
+	c.Advance(time.Second)
+	assert.Equal(t, []string{"func"}, fired)
+	assert.Equal(t, 1, c.Timers())
+	c.Advance(4 * time.Second)
+	assert.Equal(t, start.Add(6*time.Second), <-timer.C())
+	assert.Equal(t, 0, c.Timers())
+}
+
+func TestFake_BlockUntil(t *testing.T) {
+	c := NewFake(time.Now())
+	done := make(chan struct{})
+	go func() {
+		c.BlockUntil(2)
+		close(done)
+	}()
+
+	c.NewTimer(time.Second)
+	c.AfterFunc(time.Second, func() {})
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("BlockUntil didn't return")
+	}
+}
+
+func TestOrReal(t *testing.T) {
+	assert.Equal(t, Real(), OrReal(nil))
+	c := NewFake(time.Now())
+	assert.Equal(t, c, OrReal(c))
+}
@@ -0,0 +1,114 @@
+// Package codec converts messages and events to bytes and back, for the packages that keep them outside the
+// process's memory, e.g. the file journal of the persistence package.
+package codec
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var ErrUnregisteredType = fmt.Errorf("codec: type is not registered")
+
+// Codec encodes values of any registered type, so they can be decoded to the same type.
+type Codec interface {
+	Encode(value interface{}) ([]byte, error)
+	Decode(data []byte) (interface{}, error)
+}
+
+type gobCodec struct{}
+
+// gobEnvelope lets gob keep the dynamic type of the encoded value.
+type gobEnvelope struct {
+	Value interface{}
+}
+
+// Gob returns a codec which uses encoding/gob. The concrete types of the values must be registered by gob.Register.
+func Gob() Codec {
+	return gobCodec{}
+}
+
+func (gobCodec) Encode(value interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(gobEnvelope{Value: value})
+	if err != nil {
+		return nil, fmt.Errorf("codec: gob encode failed: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Decode(data []byte) (interface{}, error) {
+	var envelope gobEnvelope
+	err := gob.NewDecoder(bytes.NewReader(data)).Decode(&envelope)
+	if err != nil {
+		return nil, fmt.Errorf("codec: gob decode failed: %w", err)
+	}
+	return envelope.Value, nil
+}
+
+// JSONCodec is a codec which uses encoding/json. Each type is encoded along with the name it's registered by.
+type JSONCodec struct {
+	types map[string]reflect.Type
+	names map[reflect.Type]string
+	sync.RWMutex
+}
+
+type jsonEnvelope struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+// JSON returns a codec which uses encoding/json. The types of the values must be registered by JSONCodec.Register.
+func JSON() *JSONCodec {
+	return &JSONCodec{types: make(map[string]reflect.Type), names: make(map[reflect.Type]string)}
+}
+
+// Register registers the type of the sample value by the given name. The name is written along with each value of
+// the type, so it must not change as long as there are encoded values of the type.
+func (c *JSONCodec) Register(name string, sample interface{}) {
+	c.Lock()
+	defer c.Unlock()
+
+	t := reflect.TypeOf(sample)
+	c.types[name] = t
+	c.names[t] = name
+}
+
+func (c *JSONCodec) Encode(value interface{}) ([]byte, error) {
+	c.RLock()
+	name, ok := c.names[reflect.TypeOf(value)]
+	c.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrUnregisteredType, value)
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("codec: json encode failed: %w", err)
+	}
+	return json.Marshal(jsonEnvelope{Type: name, Value: raw})
+}
+
+func (c *JSONCodec) Decode(data []byte) (interface{}, error) {
+	var envelope jsonEnvelope
+	err := json.Unmarshal(data, &envelope)
+	if err != nil {
+		return nil, fmt.Errorf("codec: json decode failed: %w", err)
+	}
+	c.RLock()
+	t, ok := c.types[envelope.Type]
+	c.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnregisteredType, envelope.Type)
+	}
+
+	value := reflect.New(t)
+	err = json.Unmarshal(envelope.Value, value.Interface())
+	if err != nil {
+		return nil, fmt.Errorf("codec: json decode failed: %w", err)
+	}
+	return value.Elem().Interface(), nil
+}
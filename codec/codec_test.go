@@ -0,0 +1,55 @@
+package codec
+
+import (
+	"encoding/gob"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type deposited struct {
+	Account string
+	Amount  int
+}
+
+type withdrawn struct {
+	Account string
+	Amount  int
+}
+
+func TestGob(t *testing.T) {
+	gob.Register(deposited{})
+	c := Gob()
+
+	for _, value := range []interface{}{deposited{Account: "a", Amount: 10}, "text", 42} {
+		data, err := c.Encode(value)
+		if !assert.Nil(t, err) {return}
+		decoded, err := c.Decode(data)
+		assert.Nil(t, err)
+		assert.Equal(t, value, decoded)
+	}
+
+	_, err := c.Encode(withdrawn{})
+	assert.NotNil(t, err)
+	_, err = c.Decode([]byte("garbage"))
+	assert.NotNil(t, err)
+}
+
+func TestJSON(t *testing.T) {
+	c := JSON()
+	c.Register("deposited", deposited{})
+	c.Register("withdrawn", &withdrawn{})
+
+	for _, value := range []interface{}{deposited{Account: "a", Amount: 10}, &withdrawn{Account: "b", Amount: 5}} {
+		data, err := c.Encode(value)
+		if !assert.Nil(t, err) {return}
+		decoded, err := c.Decode(data)
+		assert.Nil(t, err)
+		assert.Equal(t, value, decoded)
+	}
+
+	_, err := c.Encode("not registered")
+	assert.True(t, errors.Is(err, ErrUnregisteredType))
+	_, err = c.Decode([]byte(`{"type":"unknown","value":{}}`))
+	assert.True(t, errors.Is(err, ErrUnregisteredType))
+}
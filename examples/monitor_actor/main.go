@@ -11,7 +11,7 @@ func main() {
 	defer dispose()
 
 	abnormalPID := goactor.Spawn(abnormalActor, nil)
-	_ = parent.Monitor(abnormalPID)
+	_, _ = parent.Monitor(abnormalPID)
 
 	err := goactor.Send(abnormalPID, "Hi, I think you should panic :)")
 	if err != nil {
@@ -18,7 +18,10 @@ func main() {
 		log.Println(err)
 	}
 
-	process.Register("echo", echoPID)
+	err = process.Register("echo", echoPID)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	err = goactor.SendNamed("echo", "a msg to a named actor named echo")
 	if err != nil {
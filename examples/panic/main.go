@@ -12,7 +12,7 @@ func main() {
 	parent, dispose := goactor.NewParentActor(nil)
 	defer dispose()
 
-	err := parent.Monitor(pid)
+	_, err := parent.Monitor(pid)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -1,8 +1,14 @@
 package goactor
 
 import (
+	"errors"
+	"fmt"
 	"github.com/hedisam/goactor/internal/intlpid"
+	"github.com/hedisam/goactor/internal/pidconv"
+	"github.com/hedisam/goactor/internal/relations"
 	"github.com/hedisam/goactor/pid"
+	"github.com/hedisam/goactor/reason"
+	"github.com/hedisam/goactor/sysmsg"
 	"time"
 )
 
@@ -10,12 +16,17 @@ type FutureActor struct {
 	mailbox Mailbox
 	self    *pid.PID
 	msgHandler MessageHandler
+	// monitored keeps the actors monitored by the future by their monitor references.
+	monitored map[sysmsg.MonitorRef]*pid.PID
+	// deferred keeps the Down messages which wait for the user messages received before them.
+	deferred []sysmsg.Down
 }
 
 func newFutureActor(mailbox Mailbox, self intlpid.InternalPID) *FutureActor {
 	return &FutureActor{
-		mailbox: mailbox,
-		self:    pid.ToPID(self),
+		mailbox:   mailbox,
+		self:      pidconv.ToPID(self),
+		monitored: make(map[sysmsg.MonitorRef]*pid.PID),
 	}
 }
 
@@ -26,16 +37,82 @@ func (a *FutureActor) Self() *pid.PID {
 func (a *FutureActor) Receive(handler MessageHandler) error {
 	defer a.dispose()
 	a.msgHandler = handler
-	return a.mailbox.Receive(handler, a.systemMessageHandler)
+	return a.mailbox.Receive(a.messageHandler, a.systemMessageHandler)
 }
 
 func (a *FutureActor) ReceiveWithTimeout(timeout time.Duration, handler MessageHandler) error {
 	defer a.dispose()
 	a.msgHandler = handler
-	return a.mailbox.ReceiveWithTimeout(timeout, handler, a.systemMessageHandler)
+	return a.mailbox.ReceiveWithTimeout(timeout, a.messageHandler, a.systemMessageHandler)
+}
+
+// Monitor monitors the given actor, whose Down message is passed to the future's handler, e.g. so a request fails
+// once the actor it's waiting for exits. It's a noproc Down if the actor has already exited.
+// System messages are received first, so a Down is held back until the user messages which were waiting when it
+// arrived are handled. Therefore a reply sent by the actor before it exited is always received before its Down.
+func (a *FutureActor) Monitor(target *pid.PID) (sysmsg.MonitorRef, error) {
+	if target == nil {
+		return "", ErrMonitorNilTargetPID
+	}
+	ref := sysmsg.NewMonitorRef()
+	err := intlpid.AddMonitor(pidconv.Internal(target), pidconv.Internal(a.self), string(ref))
+	if errors.Is(err, relations.ErrDisposed) {
+		err = intlpid.SendSystemMessage(pidconv.Internal(a.self), sysmsg.Down{Ref: ref, PID: target, Reason: reason.NoProc})
+		if err != nil {
+			return "", fmt.Errorf("failed to deliver the noproc down message: %w", err)
+		}
+		return ref, nil
+	} else if err != nil {
+		return "", fmt.Errorf("failed to monitor: %w", err)
+	}
+	a.monitored[ref] = target
+	return ref, nil
+}
+
+// Demonitor stops monitoring the actor which was monitored by the given reference.
+func (a *FutureActor) Demonitor(ref sysmsg.MonitorRef) error {
+	if ref == "" {
+		return ErrDemonitorEmptyRef
+	}
+	target, ok := a.monitored[ref]
+	if !ok {
+		return nil
+	}
+	delete(a.monitored, ref)
+	// it's fine if the target has already exited.
+	err := intlpid.RemoveMonitor(pidconv.Internal(target), string(ref))
+	if err != nil && !errors.Is(err, relations.ErrDisposed) {
+		return fmt.Errorf("failed to demonitor: %w", err)
+	}
+	return nil
+}
+
+// pending returns the number of user messages waiting in the future's mailbox.
+func (a *FutureActor) pending() int {
+	n, _ := intlpid.MailboxLen(pidconv.Internal(a.self))
+	return n
+}
+
+func (a *FutureActor) messageHandler(msg interface{}) (loop bool) {
+	if !a.msgHandler(msg) {
+		return false
+	}
+	// pass the Down messages held back once the user messages received before them are handled.
+	for len(a.deferred) > 0 && a.pending() == 0 {
+		down := a.deferred[0]
+		a.deferred = a.deferred[1:]
+		if !a.msgHandler(down) {
+			return false
+		}
+	}
+	return true
 }
 
 func (a *FutureActor) systemMessageHandler(sysMsg interface{}) (loop bool) {
+	if down, ok := sysMsg.(sysmsg.Down); ok && (a.pending() > 0 || len(a.deferred) > 0) {
+		a.deferred = append(a.deferred, down)
+		return true
+	}
 	return a.msgHandler(sysMsg)
 }
 
@@ -2,6 +2,9 @@ package goactor
 
 import (
 	"github.com/hedisam/goactor/internal/intlpid"
+	"github.com/hedisam/goactor/internal/pidconv"
+	"github.com/hedisam/goactor/reason"
+	"github.com/hedisam/goactor/sysmsg"
 	"github.com/stretchr/testify/assert"
 	"sync"
 	"testing"
@@ -48,7 +51,7 @@ func TestFutureActor_ReceiveWithTimeout(t *testing.T) {
 	time.AfterFunc(timeout * 2, func() {
 		defer wg.Done()
 		msg := "this message will not get delivered"
-		err = Send(future.Self(), msg)
+		err := Send(future.Self(), msg)
 		assert.NotNil(t, err)
 	})
 
@@ -69,7 +72,7 @@ func TestFutureActor_systemMessageHandler(t *testing.T) {
 
 	var received interface{}
 	msg := "This is a system message"
-	err := intlpid.SendSystemMessage(future.Self().InternalPID(), msg)
+	err := intlpid.SendSystemMessage(pidconv.Internal(future.Self()), msg)
 	if !assert.Nil(t, err) {return}
 
 	err = future.ReceiveWithTimeout(10 * time.Millisecond, func(message interface{}) (loop bool) {
@@ -88,5 +91,61 @@ func TestFutureActor_Self(t *testing.T) {
 
 	self := future.Self()
 	if !assert.NotNil(t, self) {return}
-	assert.NotNil(t, self.InternalPID())
+	assert.NotNil(t, pidconv.Internal(self))
+}
+
+func TestFutureActor_Monitor(t *testing.T) {
+	t.Run("reply received before the down", func(t *testing.T) {
+		target, dispose := NewParentActor(nil)
+		future := NewFutureActor()
+		ref, err := future.Monitor(target.Self())
+		if !assert.Nil(t, err) {return}
+
+		// the target replies, then exits. both are waiting in the future's mailbox before it receives
+		if !assert.Nil(t, Send(future.Self(), "reply")) {return}
+		dispose()
+
+		var received []interface{}
+		err = future.ReceiveWithTimeout(100 * time.Millisecond, func(message interface{}) (loop bool) {
+			received = append(received, message)
+			return len(received) < 2
+		})
+		if !assert.Nil(t, err) {return}
+		if !assert.Len(t, received, 2) {return}
+		assert.Equal(t, "reply", received[0])
+		if !assert.IsType(t, sysmsg.Down{}, received[1]) {return}
+		assert.Equal(t, ref, received[1].(sysmsg.Down).Ref)
+	})
+
+	t.Run("exited actor", func(t *testing.T) {
+		target, dispose := NewParentActor(nil)
+		dispose()
+
+		future := NewFutureActor()
+		ref, err := future.Monitor(target.Self())
+		if !assert.Nil(t, err) {return}
+
+		err = future.ReceiveWithTimeout(100 * time.Millisecond, func(message interface{}) (loop bool) {
+			if !assert.IsType(t, sysmsg.Down{}, message) {return false}
+			assert.Equal(t, ref, message.(sysmsg.Down).Ref)
+			assert.Equal(t, reason.NoProc, message.(sysmsg.Down).Reason)
+			return false
+		})
+		assert.Nil(t, err)
+	})
+
+	t.Run("demonitor", func(t *testing.T) {
+		target, dispose := NewParentActor(nil)
+		future := NewFutureActor()
+		ref, err := future.Monitor(target.Self())
+		if !assert.Nil(t, err) {return}
+		if !assert.Nil(t, future.Demonitor(ref)) {return}
+		dispose()
+
+		err = future.ReceiveWithTimeout(10 * time.Millisecond, func(message interface{}) (loop bool) {
+			t.Errorf("unexpected message: %v", message)
+			return false
+		})
+		assert.NotNil(t, err)
+	})
 }
@@ -0,0 +1,168 @@
+package genevent
+
+import (
+	"fmt"
+	"github.com/hedisam/goactor"
+	p "github.com/hedisam/goactor/pid"
+	"github.com/hedisam/goactor/process"
+	"github.com/hedisam/goactor/supervisor/spec"
+	"time"
+)
+
+var ErrHandlerExists = fmt.Errorf("genevent: a handler with the same id already exists")
+var ErrHandlerNotFound = fmt.Errorf("genevent: handler not found")
+var ErrNilHandler = fmt.Errorf("genevent: handler is nil")
+var ErrNilOwner = fmt.Errorf("genevent: owner is nil")
+var ErrUnknownResponse = fmt.Errorf("genevent: unknown response")
+
+// ErrRemoveHandler can be returned, or wrapped, by a handler to have itself removed from the manager. Any other error,
+// or a panic, removes the handler too, but it's reported as a failure.
+var ErrRemoveHandler = fmt.Errorf("genevent: remove handler")
+
+// Handler handles the events of a manager. A manager calls its handlers one at a time, so a handler doesn't need to
+// guard its state.
+type Handler interface {
+	HandleEvent(event interface{}) error
+	HandleCall(request interface{}) (interface{}, error)
+}
+
+// Terminator can be implemented by a handler to be told once it's removed from its manager, and why.
+type Terminator interface {
+	Terminate(reason error)
+}
+
+// HandlerRemoved is sent to the owner of a handler added by AddSupHandler once the handler is removed for any reason
+// other than DeleteHandler.
+type HandlerRemoved struct {
+	Manager *p.PID
+	ID      string
+	Reason  error
+}
+
+// Manager is a reference to an event manager's actor. The events sent to a manager are passed to each of its handlers
+// in the order the handlers were added.
+type Manager struct {
+	pid *p.PID
+}
+
+// Start spawns a new event manager with no handlers.
+func Start() *Manager {
+	return &Manager{pid: goactor.Spawn(run, nil)}
+}
+
+// ChildSpec returns a worker spec which starts an event manager under a supervisor. The manager is registered by the
+// given name so it can be found by FromName. A restarted manager has no handlers.
+func ChildSpec(name string, restartWhen int) spec.WorkerSpec {
+	return spec.NewWorkerSpec(name, restartWhen, run)
+}
+
+// FromName returns the event manager which is registered by the given name.
+func FromName(name string) (*Manager, bool) {
+	pid, ok := process.WhereIs(name)
+	if !ok {
+		return nil, false
+	}
+	return &Manager{pid: pid}, true
+}
+
+// PID returns the manager's pid.
+func (m *Manager) PID() *p.PID {
+	return m.pid
+}
+
+// AddHandler adds a handler by the given id.
+func (m *Manager) AddHandler(id string, handler Handler, timeout time.Duration) error {
+	if handler == nil {
+		return ErrNilHandler
+	}
+	_, err := m.request(func(requester *p.PID) interface{} {
+		return addRequest{id: id, handler: handler, requester: requester}
+	}, timeout)
+	return err
+}
+
+// AddSupHandler adds a handler which is tied to its owner. The manager monitors the owner and removes the handler
+// once the owner exits, while the owner is sent a HandlerRemoved message if the handler is removed due to an error.
+func (m *Manager) AddSupHandler(id string, handler Handler, owner *p.PID, timeout time.Duration) error {
+	if handler == nil {
+		return ErrNilHandler
+	} else if owner == nil {
+		return ErrNilOwner
+	}
+	_, err := m.request(func(requester *p.PID) interface{} {
+		return addRequest{id: id, handler: handler, owner: owner, requester: requester}
+	}, timeout)
+	return err
+}
+
+// DeleteHandler removes the handler of the given id.
+func (m *Manager) DeleteHandler(id string, timeout time.Duration) error {
+	_, err := m.request(func(requester *p.PID) interface{} {
+		return deleteRequest{id: id, requester: requester}
+	}, timeout)
+	return err
+}
+
+// Handlers returns the id of the manager's handlers.
+func (m *Manager) Handlers(timeout time.Duration) ([]string, error) {
+	value, err := m.request(func(requester *p.PID) interface{} {
+		return handlersRequest{requester: requester}
+	}, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return value.([]string), nil
+}
+
+// Notify sends an event to the manager without waiting for the handlers.
+func (m *Manager) Notify(event interface{}) error {
+	return goactor.Send(m.pid, notifyRequest{event: event})
+}
+
+// SyncNotify sends an event to the manager and returns once all the handlers have handled it.
+func (m *Manager) SyncNotify(event interface{}, timeout time.Duration) error {
+	_, err := m.request(func(requester *p.PID) interface{} {
+		return notifyRequest{event: event, requester: requester}
+	}, timeout)
+	return err
+}
+
+// CallHandler sends a request to the handler of the given id and returns its response.
+func (m *Manager) CallHandler(id string, request interface{}, timeout time.Duration) (interface{}, error) {
+	return m.request(func(requester *p.PID) interface{} {
+		return callRequest{id: id, request: request, requester: requester}
+	}, timeout)
+}
+
+// Stop stops the manager once it's done with the messages received before this one. Its handlers are terminated.
+func (m *Manager) Stop(timeout time.Duration) error {
+	_, err := m.request(func(requester *p.PID) interface{} {
+		return stopRequest{requester: requester}
+	}, timeout)
+	return err
+}
+
+func (m *Manager) request(build func(requester *p.PID) interface{}, timeout time.Duration) (interface{}, error) {
+	future := goactor.NewFutureActor()
+	err := goactor.Send(m.pid, build(future.Self()))
+	if err != nil {
+		return nil, fmt.Errorf("genevent: couldn't deliver request: %w", err)
+	}
+
+	var resp *response
+	var respErr error
+	err = future.ReceiveWithTimeout(timeout, func(message interface{}) (loop bool) {
+		if r, ok := message.(response); ok {
+			resp = &r
+			return false
+		}
+		respErr = fmt.Errorf("%w: %v", ErrUnknownResponse, message)
+		return false
+	})
+	if err != nil {
+		return nil, fmt.Errorf("genevent: request failed: %w", err)
+	} else if respErr != nil {
+		return nil, respErr
+	}
+	return resp.value, resp.err
+}
@@ -0,0 +1,205 @@
+package genevent
+
+import (
+	"errors"
+	"fmt"
+	"github.com/hedisam/goactor"
+	"github.com/hedisam/goactor/reason"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// recorder keeps the events it receives. If failOn is set, it fails on that event by its name: "fail" returns an
+// error, "panic" panics and "remove" removes the handler.
+type recorder struct {
+	events     []interface{}
+	failOn     string
+	terminated chan error
+}
+
+func newRecorder() *recorder {
+	return &recorder{terminated: make(chan error, 1)}
+}
+
+func (r *recorder) HandleEvent(event interface{}) error {
+	if event != r.failOn {
+		r.events = append(r.events, event)
+		return nil
+	}
+	switch event {
+	case "fail":
+		return errors.New("failed")
+	case "panic":
+		panic("handler panicked")
+	case "remove":
+		return ErrRemoveHandler
+	}
+	return nil
+}
+
+func (r *recorder) HandleCall(request interface{}) (interface{}, error) {
+	switch request {
+	case "panic":
+		panic("handler panicked")
+	case "remove":
+		return len(r.events), fmt.Errorf("done: %w", ErrRemoveHandler)
+	}
+	return len(r.events), nil
+}
+
+func (r *recorder) Terminate(reason error) {
+	r.terminated <- reason
+}
+
+func TestManager_Handlers(t *testing.T) {
+	manager := Start()
+	defer manager.Stop(time.Second)
+
+	assert.Nil(t, manager.AddHandler("first", newRecorder(), time.Second))
+	assert.Nil(t, manager.AddHandler("second", newRecorder(), time.Second))
+	assert.Equal(t, ErrHandlerExists, manager.AddHandler("first", newRecorder(), time.Second))
+	assert.Equal(t, ErrNilHandler, manager.AddHandler("nil", nil, time.Second))
+
+	ids, err := manager.Handlers(time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"first", "second"}, ids)
+
+	assert.Nil(t, manager.DeleteHandler("first", time.Second))
+	assert.Equal(t, ErrHandlerNotFound, manager.DeleteHandler("first", time.Second))
+	ids, err = manager.Handlers(time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"second"}, ids)
+}
+
+func TestManager_Notify(t *testing.T) {
+	manager := Start()
+	defer manager.Stop(time.Second)
+
+	handlers := []*recorder{newRecorder(), newRecorder()}
+	assert.Nil(t, manager.AddHandler("first", handlers[0], time.Second))
+	assert.Nil(t, manager.AddHandler("second", handlers[1], time.Second))
+
+	assert.Nil(t, manager.Notify("a"))
+	assert.Nil(t, manager.SyncNotify("b", time.Second))
+	for _, id := range []string{"first", "second"} {
+		count, err := manager.CallHandler(id, "count", time.Second)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, count)
+	}
+	assert.Equal(t, []interface{}{"a", "b"}, handlers[0].events)
+
+	_, err := manager.CallHandler("unknown", "count", time.Second)
+	assert.Equal(t, ErrHandlerNotFound, err)
+}
+
+func TestManager_FailingHandler(t *testing.T) {
+	manager := Start()
+	defer manager.Stop(time.Second)
+
+	for _, event := range []string{"fail", "panic", "remove"} {
+		handler := newRecorder()
+		handler.failOn = event
+		assert.Nil(t, manager.AddHandler(event, handler, time.Second))
+		assert.Nil(t, manager.AddHandler("healthy", newRecorder(), time.Second))
+
+		// the failing handler is removed while the manager and the other handlers keep running
+		assert.Nil(t, manager.SyncNotify(event, time.Second))
+		ids, err := manager.Handlers(time.Second)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"healthy"}, ids)
+
+		select {
+		case why := <-handler.terminated:
+			if event == "remove" {
+				assert.Equal(t, ErrRemoveHandler, why)
+			} else {
+				assert.NotNil(t, why)
+			}
+		case <-time.After(time.Second):
+			t.Errorf("handler %s was not terminated", event)
+		}
+		assert.Nil(t, manager.DeleteHandler("healthy", time.Second))
+	}
+
+	assert.Nil(t, manager.AddHandler("panicking call", newRecorder(), time.Second))
+	_, err := manager.CallHandler("panicking call", "panic", time.Second)
+	var panicReason reason.Panic
+	assert.True(t, errors.As(err, &panicReason))
+	assert.Equal(t, ErrHandlerNotFound, manager.DeleteHandler("panicking call", time.Second))
+}
+
+func TestManager_CallRemovesHandler(t *testing.T) {
+	manager := Start()
+	defer manager.Stop(time.Second)
+
+	handler := newRecorder()
+	assert.Nil(t, manager.AddHandler("first", handler, time.Second))
+
+	// a handler wrapping ErrRemoveHandler is removed without failing the call
+	count, err := manager.CallHandler("first", "remove", time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, count)
+	ids, err := manager.Handlers(time.Second)
+	assert.Nil(t, err)
+	assert.Empty(t, ids)
+
+	select {
+	case why := <-handler.terminated:
+		assert.True(t, errors.Is(why, ErrRemoveHandler), why)
+	case <-time.After(time.Second):
+		t.Error("handler was not terminated")
+	}
+}
+
+func TestManager_AddSupHandler(t *testing.T) {
+	manager := Start()
+	defer manager.Stop(time.Second)
+
+	owner, dispose := goactor.NewParentActor(nil)
+	defer dispose()
+
+	assert.Equal(t, ErrNilOwner, manager.AddSupHandler("supervised", newRecorder(), nil, time.Second))
+	handler := newRecorder()
+	handler.failOn = "panic"
+	assert.Nil(t, manager.AddSupHandler("supervised", handler, owner.Self(), time.Second))
+	assert.Nil(t, manager.Notify("panic"))
+
+	// the owner is told about the removal
+	err := owner.ReceiveWithTimeout(time.Second, func(message interface{}) (loop bool) {
+		removed, ok := message.(HandlerRemoved)
+		if !assert.True(t, ok) {return false}
+		assert.Equal(t, "supervised", removed.ID)
+		assert.Equal(t, manager.PID().ID(), removed.Manager.ID())
+		var panicReason reason.Panic
+		assert.True(t, errors.As(removed.Reason, &panicReason))
+		return false
+	})
+	assert.Nil(t, err)
+
+	// the handler is removed once its owner exits
+	otherOwner, disposeOther := goactor.NewParentActor(nil)
+	handler = newRecorder()
+	assert.Nil(t, manager.AddSupHandler("owned", handler, otherOwner.Self(), time.Second))
+	disposeOther()
+	select {
+	case <-handler.terminated:
+	case <-time.After(time.Second):
+		t.Fatal("the handler was not removed after its owner exited")
+	}
+	ids, err := manager.Handlers(time.Second)
+	assert.Nil(t, err)
+	assert.Empty(t, ids)
+}
+
+func TestManager_Stop(t *testing.T) {
+	manager := Start()
+	handler := newRecorder()
+	assert.Nil(t, manager.AddHandler("handler", handler, time.Second))
+
+	assert.Nil(t, manager.Stop(time.Second))
+	assert.Equal(t, reason.Shutdown, <-handler.terminated)
+	assert.Eventually(t, func() bool {
+		return manager.Notify("event") != nil
+	}, time.Second, 5*time.Millisecond)
+}
@@ -0,0 +1,199 @@
+package genevent
+
+import (
+	"errors"
+	"github.com/hedisam/goactor"
+	p "github.com/hedisam/goactor/pid"
+	"github.com/hedisam/goactor/reason"
+	"github.com/hedisam/goactor/sysmsg"
+	"log"
+)
+
+type handlerEntry struct {
+	id      string
+	handler Handler
+	// owner and ownerRef are only set for supervised handlers.
+	owner    *p.PID
+	ownerRef sysmsg.MonitorRef
+}
+
+// manager is the event manager's actor. It keeps the handlers in the order they were added.
+type manager struct {
+	actor    *goactor.Actor
+	handlers []*handlerEntry
+}
+
+func run(actor *goactor.Actor) {
+	m := &manager{actor: actor}
+	defer m.removeAll(reason.Shutdown)
+
+	_ = actor.Receive(m.handle)
+}
+
+func (m *manager) handle(message interface{}) (loop bool) {
+	switch msg := message.(type) {
+	case addRequest:
+		m.reply(msg.requester, response{err: m.add(msg)})
+	case deleteRequest:
+		h := m.get(msg.id)
+		if h == nil {
+			m.reply(msg.requester, response{err: ErrHandlerNotFound})
+			return true
+		}
+		m.remove(h, nil, false)
+		m.reply(msg.requester, response{})
+	case handlersRequest:
+		ids := make([]string, 0, len(m.handlers))
+		for _, h := range m.handlers {
+			ids = append(ids, h.id)
+		}
+		m.reply(msg.requester, response{value: ids})
+	case notifyRequest:
+		m.notify(msg.event)
+		if msg.requester != nil {
+			m.reply(msg.requester, response{})
+		}
+	case callRequest:
+		m.reply(msg.requester, m.call(msg))
+	case stopRequest:
+		m.removeAll(reason.Shutdown)
+		m.reply(msg.requester, response{})
+		return false
+	case sysmsg.Down:
+		for _, h := range m.handlers {
+			if h.owner != nil && h.ownerRef == msg.Ref {
+				// the owner has exited, so there's no one to report to.
+				h.owner = nil
+				m.remove(h, msg.Reason, false)
+				break
+			}
+		}
+	default:
+		log.Printf("[!] event manager %s received an unknown message: %v\n", m.actor.Self().ID(), message)
+	}
+	return true
+}
+
+func (m *manager) add(req addRequest) error {
+	if m.get(req.id) != nil {
+		return ErrHandlerExists
+	}
+	h := &handlerEntry{id: req.id, handler: req.handler}
+	if req.owner != nil {
+		ref, err := m.actor.Monitor(req.owner)
+		if err != nil {
+			return err
+		}
+		h.owner = req.owner
+		h.ownerRef = ref
+	}
+	m.handlers = append(m.handlers, h)
+	return nil
+}
+
+func (m *manager) get(id string) *handlerEntry {
+	for _, h := range m.handlers {
+		if h.id == id {
+			return h
+		}
+	}
+	return nil
+}
+
+// notify passes the event to the handlers. A handler which fails is removed.
+func (m *manager) notify(event interface{}) {
+	handlers := append([]*handlerEntry(nil), m.handlers...)
+	for _, h := range handlers {
+		err := safely(func() error {
+			return h.handler.HandleEvent(event)
+		})
+		if err != nil {
+			m.fail(h, err)
+		}
+	}
+}
+
+func (m *manager) call(req callRequest) response {
+	h := m.get(req.id)
+	if h == nil {
+		return response{err: ErrHandlerNotFound}
+	}
+	var value interface{}
+	err := safely(func() error {
+		var err error
+		value, err = h.handler.HandleCall(req.request)
+		return err
+	})
+	if err == nil {
+		return response{value: value}
+	}
+	m.fail(h, err)
+	if errors.Is(err, ErrRemoveHandler) {
+		return response{value: value}
+	}
+	return response{value: value, err: err}
+}
+
+// fail removes a handler which has returned an error or panicked.
+func (m *manager) fail(h *handlerEntry, err error) {
+	if !errors.Is(err, ErrRemoveHandler) {
+		log.Printf("[!] event manager %s removed handler %s, reason: %v\n", m.actor.Self().ID(), h.id, err)
+	}
+	m.remove(h, err, true)
+}
+
+// remove removes the handler and terminates it. If report is set, the owner of a supervised handler is sent a
+// HandlerRemoved message.
+func (m *manager) remove(h *handlerEntry, why error, report bool) {
+	for i := range m.handlers {
+		if m.handlers[i] == h {
+			m.handlers = append(m.handlers[:i], m.handlers[i+1:]...)
+			break
+		}
+	}
+	if terminator, ok := h.handler.(Terminator); ok {
+		err := safely(func() error {
+			terminator.Terminate(why)
+			return nil
+		})
+		if err != nil {
+			log.Printf("[!] event manager %s: handler %s failed to terminate: %v\n", m.actor.Self().ID(), h.id, err)
+		}
+	}
+
+	if h.owner == nil {
+		return
+	}
+	_ = m.actor.Demonitor(h.ownerRef)
+	if report {
+		removed := HandlerRemoved{Manager: m.actor.Self(), ID: h.id, Reason: why}
+		err := goactor.Send(h.owner, removed)
+		if err != nil {
+			log.Printf("[!] event manager %s couldn't report the removal of handler %s: %v\n", m.actor.Self().ID(), h.id, err)
+		}
+	}
+}
+
+// removeAll removes all the handlers, reporting the given reason to the owners of the supervised handlers.
+func (m *manager) removeAll(why error) {
+	for len(m.handlers) > 0 {
+		m.remove(m.handlers[0], why, true)
+	}
+}
+
+// safely runs fn and converts its panic, if any, to an error.
+func safely(fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = reason.FromPanic(r)
+		}
+	}()
+	return fn()
+}
+
+func (m *manager) reply(requester *p.PID, resp response) {
+	err := goactor.Send(requester, resp)
+	if err != nil {
+		log.Printf("[!] event manager %s couldn't send back a response: %v\n", m.actor.Self().ID(), err)
+	}
+}
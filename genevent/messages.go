@@ -0,0 +1,41 @@
+package genevent
+
+import p "github.com/hedisam/goactor/pid"
+
+type addRequest struct {
+	id      string
+	handler Handler
+	// owner is the owner of a supervised handler.
+	owner     *p.PID
+	requester *p.PID
+}
+
+type deleteRequest struct {
+	id        string
+	requester *p.PID
+}
+
+type handlersRequest struct {
+	requester *p.PID
+}
+
+// notifyRequest carries an event. The requester, if any, is replied once the event is handled.
+type notifyRequest struct {
+	event     interface{}
+	requester *p.PID
+}
+
+type callRequest struct {
+	id        string
+	request   interface{}
+	requester *p.PID
+}
+
+type stopRequest struct {
+	requester *p.PID
+}
+
+type response struct {
+	value interface{}
+	err   error
+}
@@ -5,5 +5,5 @@ go 1.15
 require (
 	github.com/Workiva/go-datastructures v1.0.52
 	github.com/google/uuid v1.1.2
-	github.com/stretchr/testify v1.6.1 // indirect
+	github.com/stretchr/testify v1.6.1
 )
@@ -1,12 +1,16 @@
 package goactor
 
 import (
+	"context"
 	"fmt"
 	"github.com/hedisam/goactor/internal/intlpid"
+	"github.com/hedisam/goactor/internal/pidconv"
 	"github.com/hedisam/goactor/internal/relations"
 	"github.com/hedisam/goactor/mailbox"
 	p "github.com/hedisam/goactor/pid"
 	"github.com/hedisam/goactor/process"
+	"github.com/hedisam/goactor/sched"
+	"github.com/hedisam/goactor/sysmsg"
 )
 
 func DefaultQueueMailbox() Mailbox {
@@ -35,11 +39,52 @@ func NewFutureActor() *FutureActor {
 
 func Spawn(fn ActorFunc, mailboxBuilder MailboxBuilderFunc) *p.PID {
 	actor, pid := setupActor(mailboxBuilder)
-	go spawn(fn, actor)
+	sched.Go(func() {
+		spawn(fn, actor)
+	})
 
 	return pid
 }
 
+// SpawnLink spawns a new actor which is linked to the parent before its ActorFunc starts running, so the parent
+// gets notified even if the new actor exits right away.
+func SpawnLink(parent Linker, fn ActorFunc, mailboxBuilder MailboxBuilderFunc) (*p.PID, error) {
+	if parent == nil {
+		return nil, ErrSpawnNilParent
+	}
+	actor, pid := setupActor(mailboxBuilder)
+	err := parent.Link(pid)
+	if err != nil {
+		actor.shutdown()
+		return nil, fmt.Errorf("spawn link failed: %w", err)
+	}
+	sched.Go(func() {
+		spawn(fn, actor)
+	})
+
+	return pid, nil
+}
+
+// SpawnMonitor spawns a new actor which is monitored by the parent before its ActorFunc starts running, so the
+// parent gets notified even if the new actor exits right away. It returns the new actor's pid along with the
+// monitor's reference.
+func SpawnMonitor(parent Monitorer, fn ActorFunc, mailboxBuilder MailboxBuilderFunc) (*p.PID, sysmsg.MonitorRef, error) {
+	if parent == nil {
+		return nil, "", ErrSpawnNilParent
+	}
+	actor, pid := setupActor(mailboxBuilder)
+	ref, err := parent.Monitor(pid)
+	if err != nil {
+		actor.shutdown()
+		return nil, "", fmt.Errorf("spawn monitor failed: %w", err)
+	}
+	sched.Go(func() {
+		spawn(fn, actor)
+	})
+
+	return pid, ref, nil
+}
+
 func Send(pid *p.PID, msg interface{}) error {
 	if pid == nil {
 		return ErrSendNilPID
@@ -47,7 +92,7 @@ func Send(pid *p.PID, msg interface{}) error {
 	if pid.IsSupervisor() {
 		return ErrSendToSupervisor
 	}
-	err := intlpid.SendMessage(pid.InternalPID(), msg)
+	err := intlpid.SendMessage(pidconv.Internal(pid), msg)
 	if err != nil {
 		return fmt.Errorf("send failed: %w", err)
 	}
@@ -62,18 +107,38 @@ func SendNamed(name string, msg interface{}) error {
 	return Send(pid, msg)
 }
 
+// SendVia sends the message to the actor which is registered by the via name in its registry.
+func SendVia(via process.Via, msg interface{}) error {
+	pid, ok := via.WhereIs()
+	if !ok {
+		return ErrSendNameNotFound
+	}
+	return Send(pid, msg)
+}
+
 func setupActor(mailboxBuilder MailboxBuilderFunc) (*Actor, *p.PID) {
+	return setupActorWithContext(context.Background(), mailboxBuilder)
+}
+
+// setupActorWithContext sets up a new actor whose context is derived from the given one.
+func setupActorWithContext(ctx context.Context, mailboxBuilder MailboxBuilderFunc) (*Actor, *p.PID) {
 	if mailboxBuilder == nil {
 		mailboxBuilder = DefaultQueueMailbox
 	}
-	m := mailboxBuilder()
+	var m Mailbox
+	if s := sched.Current(); s != nil {
+		// a deterministic run needs its actors to use the scheduler's mailboxes
+		m = s.NewMailbox()
+	} else {
+		m = mailboxBuilder()
+	}
 
 	relationManager := relations.NewRelation()
 
-	actor := newActor(m, relationManager)
+	actor := newActor(ctx, m, relationManager)
 
 	localPID := intlpid.NewLocalPID(m, relationManager, false, actor.shutdown)
-	pid := p.ToPID(localPID)
+	pid := pidconv.ToPID(localPID)
 	actor.self = pid
 
 	return actor, pid
@@ -81,7 +146,10 @@ func setupActor(mailboxBuilder MailboxBuilderFunc) (*Actor, *p.PID) {
 
 func setupFutureActor() *FutureActor {
 	noShutdown := func() {}
-	m := mailbox.NewQueueMailbox(1, 1, mailbox.DefaultMailboxTimeout, mailbox.DefaultGoSchedulerInterval)
+	var m Mailbox = mailbox.NewQueueMailbox(1, 1, mailbox.DefaultMailboxTimeout, mailbox.DefaultGoSchedulerInterval)
+	if s := sched.Current(); s != nil {
+		m = s.NewMailbox()
+	}
 	localPID := intlpid.NewLocalPID(m, nil, false, noShutdown)
 	featureActor := newFutureActor(m, localPID)
 	return featureActor
@@ -89,5 +157,6 @@ func setupFutureActor() *FutureActor {
 
 func spawn(fn ActorFunc, actor *Actor) {
 	defer actor.dispose()
+	actor.watchParent()
 	fn(actor)
 }
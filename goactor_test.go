@@ -1,7 +1,11 @@
 package goactor
 
 import (
+	"errors"
+	"github.com/hedisam/goactor/clock"
 	"github.com/hedisam/goactor/internal/intlpid"
+	"github.com/hedisam/goactor/internal/pidconv"
+	"github.com/hedisam/goactor/mailbox"
 	p "github.com/hedisam/goactor/pid"
 	"github.com/hedisam/goactor/process"
 	"github.com/hedisam/goactor/sysmsg"
@@ -19,7 +23,7 @@ func TestSend(t *testing.T) {
 
 	t.Run("sending msg to a supervisor", func(t *testing.T) {
 		iPID := intlpid.NewLocalPID(nil, nil, true, func(){})
-		pid := p.ToPID(iPID)
+		pid := pidconv.ToPID(iPID)
 		if !assert.NotNil(t, pid) {return}
 
 		err := Send(pid, "we can't directly send msg to a supervisor")
@@ -45,15 +49,23 @@ func TestSend(t *testing.T) {
 	})
 }
 
+// frozenChanMailbox returns a channel mailbox whose receive timeouts never elapse, since its clock never moves.
+func frozenChanMailbox() Mailbox {
+	return mailbox.NewChanMailbox(
+		mailbox.DefaultUserMailboxCap,
+		mailbox.DefaultSysMailboxCap,
+		mailbox.DefaultMailboxTimeout).SetClock(clock.NewFake(time.Now()))
+}
+
 func TestSendNamed(t *testing.T) {
-	actor, pid := setupActor(DefaultChanMailbox)
+	actor, pid := setupActor(frozenChanMailbox)
 	if !assert.NotNil(t, actor) {return}
 	if !assert.NotNil(t, pid) {return}
 
 	var name = "my_actor"
 
 	t.Run("send to a registered actor", func(t *testing.T) {
-		process.Register(name, pid)
+		if !assert.Nil(t, process.Register(name, pid)) {return}
 		msg := "Hi you named actor"
 		var received interface{}
 
@@ -125,7 +137,7 @@ func TestNewParentActor(t *testing.T) {
 		monitor, _ := setupActor(nil)
 		assert.NotNil(t, monitor)
 
-		err := monitor.Monitor(actor.Self())
+		_, err := monitor.Monitor(actor.Self())
 		assert.Nil(t, err)
 
 		defer func() {
@@ -136,7 +148,7 @@ func TestNewParentActor(t *testing.T) {
 			err = monitor.ReceiveWithTimeout(time.Millisecond, func(message interface{}) (loop bool) {
 				// the monitor should get notified about the actor's panic
 				assert.NotNil(t, message)
-				assert.IsType(t, sysmsg.AbnormalExit{}, message)
+				assert.IsType(t, sysmsg.Down{}, message)
 				return false
 			})
 			assert.Nil(t, err)
@@ -165,7 +177,7 @@ func TestSpawn(t *testing.T) {
 		select {
 		case <-fnChan:
 			invoked = true
-		case <-time.After(10 * time.Nanosecond):
+		case <-time.After(time.Second):
 		}
 		assert.True(t, invoked)
 	})
@@ -215,7 +227,7 @@ func TestSpawn(t *testing.T) {
 		assert.NotNil(t, dispose)
 		defer dispose()
 
-		err := parent.Monitor(pid)
+		_, err := parent.Monitor(pid)
 		if !assert.Nil(t, err) {return}
 
 		err = Send(pid, "asking the actor to panic")
@@ -223,7 +235,7 @@ func TestSpawn(t *testing.T) {
 
 		err = parent.ReceiveWithTimeout(time.Millisecond*10, func(message interface{}) (loop bool) {
 			assert.NotNil(t, message)
-			assert.IsType(t, sysmsg.AbnormalExit{}, message)
+			assert.IsType(t, sysmsg.Down{}, message)
 			return false
 		})
 		// timeout should not get triggered because we're supposed to receive the exit message
@@ -249,3 +261,107 @@ func TestSpawn(t *testing.T) {
 
 
 
+func TestSpawnLink(t *testing.T) {
+	t.Run("nil parent", func(t *testing.T) {
+		pid, err := SpawnLink(nil, func(a *Actor) {}, nil)
+		assert.Nil(t, pid)
+		assert.Equal(t, ErrSpawnNilParent, err)
+	})
+
+	t.Run("linked before the actor's fn runs", func(t *testing.T) {
+		parent, dispose := NewParentActor(nil)
+		defer dispose()
+		parent.SetTrapExit(true)
+
+		// the child exits right away, yet the parent must receive its exit message
+		pid, err := SpawnLink(parent, func(a *Actor) {
+			panic("exiting right away")
+		}, nil)
+		if !assert.Nil(t, err) {return}
+		assert.NotNil(t, pid)
+
+		err = parent.ReceiveWithTimeout(100*time.Millisecond, func(message interface{}) (loop bool) {
+			if !assert.IsType(t, sysmsg.AbnormalExit{}, message) {return false}
+			assert.True(t, p.Equal(pid, message.(sysmsg.AbnormalExit).Sender()))
+			return false
+		})
+		assert.Nil(t, err)
+	})
+
+	t.Run("disposed parent", func(t *testing.T) {
+		parent, dispose := NewParentActor(nil)
+		dispose()
+
+		invoked := make(chan struct{}, 1)
+		pid, err := SpawnLink(parent, func(a *Actor) {
+			invoked <- struct{}{}
+		}, nil)
+		assert.NotNil(t, err)
+		assert.Nil(t, pid)
+
+		select {
+		case <-invoked:
+			t.Error("the actor's fn should not run when linking fails")
+		case <-time.After(10 * time.Millisecond):
+		}
+	})
+}
+
+func TestSpawnMonitor(t *testing.T) {
+	t.Run("nil parent", func(t *testing.T) {
+		pid, ref, err := SpawnMonitor(nil, func(a *Actor) {}, nil)
+		assert.Nil(t, pid)
+		assert.Empty(t, ref)
+		assert.Equal(t, ErrSpawnNilParent, err)
+	})
+
+	t.Run("monitored before the actor's fn runs", func(t *testing.T) {
+		parent, dispose := NewParentActor(nil)
+		defer dispose()
+
+		pid, ref, err := SpawnMonitor(parent, func(a *Actor) {}, nil)
+		if !assert.Nil(t, err) {return}
+		assert.NotNil(t, pid)
+
+		err = parent.ReceiveWithTimeout(100*time.Millisecond, func(message interface{}) (loop bool) {
+			if !assert.IsType(t, sysmsg.Down{}, message) {return false}
+			down := message.(sysmsg.Down)
+			assert.Equal(t, ref, down.Ref)
+			assert.Equal(t, pid, down.PID)
+			return false
+		})
+		assert.Nil(t, err)
+	})
+
+	t.Run("any monitorer", func(t *testing.T) {
+		// the parent fails to monitor, the actor is shut down before it runs
+		parent := &failingMonitorer{}
+		invoked := make(chan struct{}, 1)
+		pid, ref, err := SpawnMonitor(parent, func(a *Actor) {
+			invoked <- struct{}{}
+		}, nil)
+		assert.Equal(t, errMonitorFailed, errors.Unwrap(err))
+		assert.Nil(t, pid)
+		assert.Empty(t, ref)
+		if !assert.NotNil(t, parent.monitored) {return}
+		assert.False(t, intlpid.IsAlive(pidconv.Internal(parent.monitored)))
+
+		select {
+		case <-invoked:
+			t.Error("the actor's fn should not run when monitoring fails")
+		case <-time.After(10 * time.Millisecond):
+		}
+	})
+}
+
+var errMonitorFailed = errors.New("monitor failed")
+
+// failingMonitorer is a Monitorer that isn't an actor, and fails to monitor.
+type failingMonitorer struct {
+	monitored *p.PID
+}
+
+func (m *failingMonitorer) Monitor(pid *p.PID) (sysmsg.MonitorRef, error) {
+	m.monitored = pid
+	return "", errMonitorFailed
+}
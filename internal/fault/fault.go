@@ -0,0 +1,33 @@
+// Package fault lets the chaos package hook into supervisors, to inject faults into the starts of their children.
+package fault
+
+import (
+	p "github.com/hedisam/goactor/pid"
+	"sync/atomic"
+)
+
+// StartHook is called by supervisors around the start of each of their children.
+type StartHook interface {
+	// BeforeStart is called before the child gets started. A returned error fails the start, as if it was returned by
+	// the child spec's StartLink.
+	BeforeStart(supervisor *p.PID, name string) error
+	// Started is called with the child's pid once it's been started.
+	Started(supervisor *p.PID, name string, child *p.PID)
+}
+
+type startHookHolder struct {
+	hook StartHook
+}
+
+var startHook atomic.Value
+
+// SetStartHook sets the hook of the children starts. A nil hook removes the current one.
+func SetStartHook(hook StartHook) {
+	startHook.Store(startHookHolder{hook: hook})
+}
+
+// CurrentStartHook returns the hook of the children starts, or nil if there's none.
+func CurrentStartHook() StartHook {
+	holder, _ := startHook.Load().(startHookHolder)
+	return holder.hook
+}
@@ -16,12 +16,23 @@ func Unlink(from, who InternalPID) error {
 	return from.unlink(who)
 }
 
-func AddMonitor(to, parent InternalPID) error {
-	return to.addMonitor(parent)
+func AddMonitor(to, parent InternalPID, ref string) error {
+	return to.addMonitor(ref, parent)
 }
 
-func RemoveMonitor(from, parent InternalPID) error {
-	return from.remMonitor(parent)
+func RemoveMonitor(from InternalPID, ref string) error {
+	return from.remMonitor(ref)
+}
+
+// MailboxLen returns the number of user messages waiting in the pid's mailbox. It returns false if the mailbox can not
+// report its length.
+func MailboxLen(pid InternalPID) (int, bool) {
+	return pid.mailboxLen()
+}
+
+// IsAlive returns false once the pid's actor has exited.
+func IsAlive(pid InternalPID) bool {
+	return pid.isAlive()
 }
 
 func Shutdown(who InternalPID, reason interface{}) {
@@ -0,0 +1,24 @@
+package intlpid
+
+import "sync/atomic"
+
+// SendFilter gets the user messages sent to local pids before they're pushed into their mailboxes, e.g. to inject
+// faults in tests. push pushes the message into the pid's mailbox; the filter can call it later, or not at all to
+// drop the message.
+type SendFilter func(to InternalPID, msg interface{}, push func() error) error
+
+type sendFilterHolder struct {
+	filter SendFilter
+}
+
+var sendFilter atomic.Value
+
+// SetSendFilter sets the filter of the user messages sent to local pids. A nil filter removes the current one.
+func SetSendFilter(filter SendFilter) {
+	sendFilter.Store(sendFilterHolder{filter: filter})
+}
+
+func currentSendFilter() SendFilter {
+	holder, _ := sendFilter.Load().(sendFilterHolder)
+	return holder.filter
+}
@@ -35,6 +35,11 @@ func (l *LocalPID) ID() string {
 }
 
 func (l *LocalPID) sendMessage(msg interface{}) error {
+	if filter := currentSendFilter(); filter != nil {
+		return filter(l, msg, func() error {
+			return l.m.PushMessage(msg)
+		})
+	}
 	return l.m.PushMessage(msg)
 }
 
@@ -50,10 +55,26 @@ func (l *LocalPID) unlink(who InternalPID) error {
 	return l.relManager.RemoveLink(who)
 }
 
-func (l *LocalPID) addMonitor(parent InternalPID) error {
-	return l.relManager.AddMonitor(parent)
+func (l *LocalPID) addMonitor(ref string, parent InternalPID) error {
+	return l.relManager.AddMonitor(ref, parent)
+}
+
+func (l *LocalPID) remMonitor(ref string) error {
+	return l.relManager.RemoveMonitor(ref)
 }
 
-func (l *LocalPID) remMonitor(parent InternalPID) error {
-	return l.relManager.RemoveMonitor(parent)
+func (l *LocalPID) isAlive() bool {
+	m, ok := l.m.(disposableMailbox)
+	if !ok {
+		return true
+	}
+	return !m.Disposed()
+}
+
+func (l *LocalPID) mailboxLen() (int, bool) {
+	m, ok := l.m.(measurableMailbox)
+	if !ok {
+		return 0, false
+	}
+	return m.Len(), true
 }
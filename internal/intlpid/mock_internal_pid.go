@@ -5,7 +5,8 @@ import (
 )
 
 type MockInternalPID struct {
-	id string
+	id   string
+	dead bool
 }
 
 func NewMockInternalPID() *MockInternalPID {
@@ -36,14 +37,27 @@ func (pid *MockInternalPID) unlink(who InternalPID) error {
 	return nil
 }
 
-func (pid *MockInternalPID) addMonitor(parent InternalPID) error {
+func (pid *MockInternalPID) addMonitor(ref string, parent InternalPID) error {
 	return nil
 }
 
-func (pid *MockInternalPID) remMonitor(parent InternalPID) error {
+func (pid *MockInternalPID) remMonitor(ref string) error {
 	return nil
 }
 
+// Kill makes the pid report itself as dead.
+func (pid *MockInternalPID) Kill() {
+	pid.dead = true
+}
+
+func (pid *MockInternalPID) isAlive() bool {
+	return !pid.dead
+}
+
+func (pid *MockInternalPID) mailboxLen() (int, bool) {
+	return 0, true
+}
+
 func (pid *MockInternalPID) shutdown(_ interface{}) {
 
 }
@@ -9,8 +9,10 @@ type InternalPID interface {
 
 	link(to InternalPID) error
 	unlink(who InternalPID) error
-	addMonitor(parent InternalPID) error
-	remMonitor(parent InternalPID) error
+	addMonitor(ref string, parent InternalPID) error
+	remMonitor(ref string) error
+	mailboxLen() (int, bool)
+	isAlive() bool
 
 	// Shutdown will shutdown the actor by closing its context's done channel. We're not disposing the mailbox,
 	// so we'll be able to receive the system message that's causing the shutdown and notifying related actors with
@@ -23,11 +25,22 @@ type relationManager interface {
 	AddLink(pid InternalPID) error
 	RemoveLink(pid InternalPID) error
 
-	AddMonitor(pid InternalPID) error
-	RemoveMonitor(pid InternalPID) error
+	AddMonitor(ref string, pid InternalPID) error
+	RemoveMonitor(ref string) error
 }
 
 type mailbox interface {
 	PushMessage(msg interface{}) error
 	PushSystemMessage(msg interface{}) error
 }
+
+// measurableMailbox is implemented by the mailboxes that can report the number of their pending user messages.
+type measurableMailbox interface {
+	Len() int
+}
+
+// disposableMailbox is implemented by the mailboxes that can report whether they've been disposed, which happens once
+// their actor exits.
+type disposableMailbox interface {
+	Disposed() bool
+}
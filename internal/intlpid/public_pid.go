@@ -0,0 +1,22 @@
+package intlpid
+
+// PID is the underlying type of the pid package's PID, which is the process identifier exposed to the users. It
+// wraps an InternalPID which is only reachable by goactor's own packages, see the pidconv package.
+type PID struct {
+	iPID InternalPID
+}
+
+func ToPID(iPID InternalPID) *PID {
+	if iPID == nil {
+		return nil
+	}
+	return &PID{iPID: iPID}
+}
+
+// Internal returns the InternalPID wrapped by the given pid.
+func Internal(pid *PID) InternalPID {
+	if pid == nil {
+		return nil
+	}
+	return pid.iPID
+}
@@ -0,0 +1,18 @@
+// Package pidconv converts between the pids exposed to the users and the internal ones. It can't be part of the
+// intlpid package, since the pid package depends on it.
+package pidconv
+
+import (
+	"github.com/hedisam/goactor/internal/intlpid"
+	p "github.com/hedisam/goactor/pid"
+)
+
+// ToPID wraps the internal pid in a pid.PID. It returns nil if iPID is nil.
+func ToPID(iPID intlpid.InternalPID) *p.PID {
+	return (*p.PID)(intlpid.ToPID(iPID))
+}
+
+// Internal returns the InternalPID wrapped by the given pid, or nil if pid is nil.
+func Internal(pid *p.PID) intlpid.InternalPID {
+	return intlpid.Internal((*intlpid.PID)(pid))
+}
@@ -28,3 +28,36 @@ func (i *RelationIterator) Value() p.InternalPID {
 	i.pos++
 	return value
 }
+
+// Monitor is a monitor relation identified by its unique reference.
+type Monitor struct {
+	Ref string
+	PID p.InternalPID
+}
+
+type MonitorIterator struct {
+	data   []Monitor
+	pos    int
+	length int
+}
+
+func NewMonitorIterator(data []Monitor) *MonitorIterator {
+	return &MonitorIterator{
+		data:   data,
+		pos:    0,
+		length: len(data),
+	}
+}
+
+func (i *MonitorIterator) HasNext() bool {
+	return i.length > 0 && i.pos < i.length
+}
+
+func (i *MonitorIterator) Value() Monitor {
+	if !i.HasNext() {
+		return Monitor{}
+	}
+	value := i.data[i.pos]
+	i.pos++
+	return value
+}
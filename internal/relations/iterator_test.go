@@ -43,3 +43,29 @@ func TestRelationIterator(t *testing.T) {
 	})
 
 }
+
+
+func TestMonitorIterator(t *testing.T) {
+	t.Run("zero items", func(t *testing.T) {
+		it := NewMonitorIterator([]Monitor{})
+
+		assert.False(t, it.HasNext())
+		assert.Equal(t, Monitor{}, it.Value())
+	})
+
+	t.Run("multiple items", func(t *testing.T) {
+		monitors := []Monitor{
+			{Ref: "ref1", PID: getNewMockPID()},
+			{Ref: "ref2", PID: getNewMockPID()},
+		}
+
+		it := NewMonitorIterator(monitors)
+
+		for i := range monitors {
+			assert.True(t, it.HasNext())
+			assert.Equal(t, monitors[i], it.Value())
+		}
+
+		assert.False(t, it.HasNext())
+	})
+}
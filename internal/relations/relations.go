@@ -17,9 +17,13 @@ const (
 	NoRelation
 )
 
+var ErrDisposed = fmt.Errorf("disposed relation manager")
+
 type Relations struct {
-	linkedActors    RelationMap
-	monitorActors   RelationMap
+	linkedActors RelationMap
+	// monitorActors are the actors monitoring us, indexed by their monitor reference
+	monitorActors RelationMap
+	// monitoredActors are the actors monitored by us, indexed by their monitor reference
 	monitoredActors RelationMap
 	sync.RWMutex
 	disposed int32
@@ -33,7 +37,11 @@ func NewRelation() *Relations {
 	}
 }
 
+// Dispose marks the relation manager as disposed. We acquire the write-lock so no relation can be added after the
+// actor has started notifying its related actors.
 func (r *Relations) Dispose() {
+	r.Lock()
+	defer r.Unlock()
 	atomic.StoreInt32(&r.disposed, 1)
 }
 
@@ -46,9 +54,9 @@ func (r *Relations) RelationType(pid p.InternalPID) RelationType {
 	defer r.RUnlock()
 	if _, ok := r.linkedActors[pid.ID()]; ok {
 		return LinkedRelation
-	} else if _, ok = r.monitoredActors[pid.ID()]; ok {
+	} else if containsPID(r.monitoredActors, pid) {
 		return MonitoredRelation
-	} else if _, ok = r.monitorActors[pid.ID()]; ok {
+	} else if containsPID(r.monitorActors, pid) {
 		return MonitorRelation
 	}
 	return NoRelation
@@ -58,11 +66,11 @@ func (r *Relations) AddLink(to p.InternalPID) error {
 	if to == nil {
 		return fmt.Errorf("AddLink failed: nil pid")
 	}
-	if atomic.LoadInt32(&r.disposed) == 1 {
-		return fmt.Errorf("AddLink failed: disposed relation manager")
-	}
 	r.Lock()
 	defer r.Unlock()
+	if atomic.LoadInt32(&r.disposed) == 1 {
+		return fmt.Errorf("AddLink failed: %w", ErrDisposed)
+	}
 
 	r.linkedActors[to.ID()] = to
 	return nil
@@ -72,69 +80,84 @@ func (r *Relations) RemoveLink(from p.InternalPID) error {
 	if from == nil {
 		return fmt.Errorf("RemoveLink failed: nil pid")
 	}
-	if atomic.LoadInt32(&r.disposed) == 1 {
-		return fmt.Errorf("RemoveLink failed: disposed relation manager")
-	}
 	r.Lock()
 	defer r.Unlock()
+	if atomic.LoadInt32(&r.disposed) == 1 {
+		return fmt.Errorf("RemoveLink failed: %w", ErrDisposed)
+	}
 
 	delete(r.linkedActors, from.ID())
 	return nil
 }
 
-func (r *Relations) AddMonitored(who p.InternalPID) error {
+func (r *Relations) AddMonitored(ref string, who p.InternalPID) error {
 	if who == nil {
 		return fmt.Errorf("AddMonitored failed: nil pid")
 	}
-	if atomic.LoadInt32(&r.disposed) == 1 {
-		return fmt.Errorf("AddMonitored failed: disposed relation manager")
+	if ref == "" {
+		return fmt.Errorf("AddMonitored failed: empty monitor reference")
 	}
 	r.Lock()
 	defer r.Unlock()
+	if atomic.LoadInt32(&r.disposed) == 1 {
+		return fmt.Errorf("AddMonitored failed: %w", ErrDisposed)
+	}
 
-	r.monitoredActors[who.ID()] = who
+	r.monitoredActors[ref] = who
 	return nil
 }
 
-func (r *Relations) RemoveMonitored(who p.InternalPID) error {
-	if who == nil {
-		return fmt.Errorf("RemoveMonitored failed: nil pid")
-	}
-	if atomic.LoadInt32(&r.disposed) == 1 {
-		return fmt.Errorf("RemoveMonitored failed: disposed relation manager")
+func (r *Relations) RemoveMonitored(ref string) error {
+	if ref == "" {
+		return fmt.Errorf("RemoveMonitored failed: empty monitor reference")
 	}
 	r.Lock()
 	defer r.Unlock()
+	if atomic.LoadInt32(&r.disposed) == 1 {
+		return fmt.Errorf("RemoveMonitored failed: %w", ErrDisposed)
+	}
 
-	delete(r.monitoredActors, who.ID())
+	delete(r.monitoredActors, ref)
 	return nil
 }
 
-func (r *Relations) AddMonitor(by p.InternalPID) error {
+// Monitored returns the actor monitored by the given monitor reference.
+func (r *Relations) Monitored(ref string) (p.InternalPID, bool) {
+	r.RLock()
+	defer r.RUnlock()
+
+	pid, ok := r.monitoredActors[ref]
+	return pid, ok
+}
+
+func (r *Relations) AddMonitor(ref string, by p.InternalPID) error {
 	if by == nil {
 		return fmt.Errorf("AddMonitor failed: nil pid")
 	}
-	if atomic.LoadInt32(&r.disposed) == 1 {
-		return fmt.Errorf("AddMonitor failed: disposed relation manager")
+	if ref == "" {
+		return fmt.Errorf("AddMonitor failed: empty monitor reference")
 	}
 	r.Lock()
 	defer r.Unlock()
+	if atomic.LoadInt32(&r.disposed) == 1 {
+		return fmt.Errorf("AddMonitor failed: %w", ErrDisposed)
+	}
 
-	r.monitorActors[by.ID()] = by
+	r.monitorActors[ref] = by
 	return nil
 }
 
-func (r *Relations) RemoveMonitor(by p.InternalPID) error {
-	if by == nil {
-		return fmt.Errorf("RemoveMonitor failed: nil pid")
-	}
-	if atomic.LoadInt32(&r.disposed) == 1 {
-		return fmt.Errorf("RemoveMonitor failed: disposed relation manager")
+func (r *Relations) RemoveMonitor(ref string) error {
+	if ref == "" {
+		return fmt.Errorf("RemoveMonitor failed: empty monitor reference")
 	}
 	r.Lock()
 	defer r.Unlock()
+	if atomic.LoadInt32(&r.disposed) == 1 {
+		return fmt.Errorf("RemoveMonitor failed: %w", ErrDisposed)
+	}
 
-	delete(r.monitorActors, by.ID())
+	delete(r.monitorActors, ref)
 	return nil
 }
 
@@ -150,14 +173,23 @@ func (r *Relations) LinkedActors() *RelationIterator {
 	return NewRelationIterator(linkedActors)
 }
 
-func (r *Relations) MonitorActors() *RelationIterator {
+func (r *Relations) MonitorActors() *MonitorIterator {
 	r.RLock()
 	defer r.RUnlock()
 
-	monitorActors := make([]p.InternalPID, 0, len(r.monitorActors))
-	for _, pid := range r.monitorActors {
-		monitorActors = append(monitorActors, pid)
+	monitors := make([]Monitor, 0, len(r.monitorActors))
+	for ref, pid := range r.monitorActors {
+		monitors = append(monitors, Monitor{Ref: ref, PID: pid})
 	}
 
-	return NewRelationIterator(monitorActors)
+	return NewMonitorIterator(monitors)
+}
+
+func containsPID(relations RelationMap, pid p.InternalPID) bool {
+	for _, related := range relations {
+		if related.ID() == pid.ID() {
+			return true
+		}
+	}
+	return false
 }
@@ -1,6 +1,8 @@
 package relations
 
 import (
+	"errors"
+	"fmt"
 	"github.com/hedisam/goactor/internal/intlpid"
 	"github.com/stretchr/testify/assert"
 	"testing"
@@ -19,7 +21,7 @@ func TestRelations_RelationType(t *testing.T) {
 
 	err := rm.AddLink(linkedPID)
 	assert.Nil(t, err)
-	err = rm.AddMonitored(monitoredPID)
+	err = rm.AddMonitored("ref", monitoredPID)
 	assert.Nil(t, err)
 	// no adding for noRelationPID
 
@@ -45,14 +47,22 @@ func TestRelations_AddRemove(t *testing.T) {
 		err = rm.RemoveLink(nil)
 		assert.NotNil(t, err)
 
-		err = rm.AddMonitor(nil)
+		err = rm.AddMonitor("ref", nil)
 		assert.NotNil(t, err)
-		err = rm.RemoveMonitor(nil)
+
+		err = rm.AddMonitored("ref", nil)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("empty monitor reference", func(t *testing.T) {
+		err := rm.AddMonitor("", getNewMockPID())
+		assert.NotNil(t, err)
+		err = rm.RemoveMonitor("")
 		assert.NotNil(t, err)
 
-		err = rm.AddMonitored(nil)
+		err = rm.AddMonitored("", getNewMockPID())
 		assert.NotNil(t, err)
-		err = rm.RemoveMonitored(nil)
+		err = rm.RemoveMonitored("")
 		assert.NotNil(t, err)
 	})
 
@@ -76,35 +86,41 @@ func TestRelations_AddRemove(t *testing.T) {
 
 	t.Run("monitored actors", func(t *testing.T) {
 		monitoredPID := getNewMockPID()
-		err := rm.AddMonitored(monitoredPID)
+		err := rm.AddMonitored("ref1", monitoredPID)
+		assert.Nil(t, err)
+		// monitoring the same actor twice results in two independent monitors
+		err = rm.AddMonitored("ref2", monitoredPID)
 		assert.Nil(t, err)
 
-		pid, ok := rm.monitoredActors[monitoredPID.ID()]
+		pid, ok := rm.Monitored("ref1")
 		if !assert.True(t, ok) {
 			return
 		}
 		assert.Equal(t, monitoredPID, pid)
 
-		err = rm.RemoveMonitored(monitoredPID)
+		err = rm.RemoveMonitored("ref1")
 		assert.Nil(t, err)
 
-		pid, ok = rm.monitoredActors[monitoredPID.ID()]
+		pid, ok = rm.Monitored("ref1")
 		assert.False(t, ok)
+		pid, ok = rm.Monitored("ref2")
+		assert.True(t, ok)
+		assert.Equal(t, monitoredPID, pid)
 	})
 
 	t.Run("monitor actors", func(t *testing.T) {
 		monitorPID := getNewMockPID()
-		err := rm.AddMonitor(monitorPID)
+		err := rm.AddMonitor("ref", monitorPID)
 		assert.Nil(t, err)
 
-		pid, ok := rm.monitorActors[monitorPID.ID()]
+		pid, ok := rm.monitorActors["ref"]
 		if !assert.True(t, ok) {return}
 		assert.Equal(t, monitorPID, pid)
 
-		err = rm.RemoveMonitor(monitorPID)
+		err = rm.RemoveMonitor("ref")
 		assert.Nil(t, err)
 
-		pid, ok = rm.monitorActors[monitorPID.ID()]
+		pid, ok = rm.monitorActors["ref"]
 		assert.False(t, ok)
 	})
 
@@ -117,14 +133,14 @@ func TestRelations_AddRemove(t *testing.T) {
 		err = rm.RemoveLink(pid)
 		assert.NotNil(t, err)
 
-		err = rm.AddMonitor(pid)
-		assert.NotNil(t, err)
-		err = rm.RemoveMonitor(pid)
+		err = rm.AddMonitor("ref", pid)
+		assert.True(t, errors.Is(err, ErrDisposed))
+		err = rm.RemoveMonitor("ref")
 		assert.NotNil(t, err)
 
-		err = rm.AddMonitored(pid)
-		assert.NotNil(t, err)
-		err = rm.RemoveMonitored(pid)
+		err = rm.AddMonitored("ref", pid)
+		assert.True(t, errors.Is(err, ErrDisposed))
+		err = rm.RemoveMonitored("ref")
 		assert.NotNil(t, err)
 	})
 }
@@ -158,7 +174,7 @@ func TestRelations_MonitorActors(t *testing.T) {
 	L := 10
 	for i := 0; i < L; i++ {
 		pid := getNewMockPID()
-		err := rm.AddMonitor(pid)
+		err := rm.AddMonitor(fmt.Sprintf("ref-%d", i), pid)
 		assert.Nil(t, err)
 	}
 
@@ -166,11 +182,14 @@ func TestRelations_MonitorActors(t *testing.T) {
 	i := 0
 	for iterator.HasNext() {
 		i++
-		pid := iterator.Value()
-		relType := rm.RelationType(pid)
+		monitor := iterator.Value()
+		relType := rm.RelationType(monitor.PID)
 		if !assert.Equal(t, MonitorRelation, relType) {
 			return
 		}
+		pid, ok := rm.monitorActors[monitor.Ref]
+		if !assert.True(t, ok) {return}
+		assert.Equal(t, pid, monitor.PID)
 	}
 	assert.Equal(t, L, i)
 }
\ No newline at end of file
@@ -0,0 +1,69 @@
+// Package wal frames the records of the append-only files used by the persistence journal and the durable mailbox.
+// Each record is written as its header, i.e. the length of its data, the data's crc32 checksum and the record's
+// sequence number, followed by the data.
+package wal
+
+import (
+	"bufio"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+)
+
+// HeaderSize is the size of a record's header.
+const HeaderSize = 16
+
+// MaxDataSize is the maximum size of a record's data. A header with a larger length is treated as corrupted.
+const MaxDataSize = 64 << 20
+
+// AppendRecord appends the record to buf and returns the extended buffer.
+func AppendRecord(buf []byte, seq uint64, data []byte) []byte {
+	header := make([]byte, HeaderSize)
+	binary.BigEndian.PutUint32(header[0:4], uint32(len(data)))
+	binary.BigEndian.PutUint32(header[4:8], crc32.ChecksumIEEE(data))
+	binary.BigEndian.PutUint64(header[8:16], seq)
+	buf = append(buf, header...)
+	return append(buf, data...)
+}
+
+// Scan calls fn for each record read from r, until it reaches the end of r or a record which is partially written
+// or corrupted. It returns the offset after the last valid record, so the rest can be truncated, and the first error
+// returned by fn. Failing to read from r returns the read error, in which case the rest isn't known to be invalid and
+// mustn't be truncated.
+func Scan(r io.Reader, fn func(seq uint64, data []byte) error) (int64, error) {
+	reader := bufio.NewReader(r)
+	header := make([]byte, HeaderSize)
+	var offset int64
+	for {
+		_, err := io.ReadFull(reader, header)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			// the end of r, or a partially written header
+			return offset, nil
+		} else if err != nil {
+			return offset, err
+		}
+		length := binary.BigEndian.Uint32(header[0:4])
+		checksum := binary.BigEndian.Uint32(header[4:8])
+		seq := binary.BigEndian.Uint64(header[8:16])
+
+		if length > MaxDataSize {
+			return offset, nil
+		}
+		data := make([]byte, length)
+		_, err = io.ReadFull(reader, data)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			// partially written data
+			return offset, nil
+		} else if err != nil {
+			return offset, err
+		}
+		if crc32.ChecksumIEEE(data) != checksum {
+			return offset, nil
+		}
+		err = fn(seq, data)
+		if err != nil {
+			return offset, err
+		}
+		offset += HeaderSize + int64(length)
+	}
+}
@@ -0,0 +1,51 @@
+package watch
+
+import (
+	"github.com/hedisam/goactor"
+	p "github.com/hedisam/goactor/pid"
+	"github.com/hedisam/goactor/sched"
+	"github.com/hedisam/goactor/sysmsg"
+)
+
+// Watcher monitors actors on behalf of packages that are not actors themselves, e.g. the process groups, and calls
+// back once a watched actor exits. The callbacks are invoked one at a time from the watcher's own goroutine.
+type Watcher struct {
+	actor   *goactor.Actor
+	dispose func()
+}
+
+// New starts a watcher which calls onDown for each of the watched actors that exits. The watcher's actor blocks while
+// it waits for the Down messages, and it only stops once it's closed, so the packages start it once it has something
+// to watch.
+func New(onDown func(down sysmsg.Down)) *Watcher {
+	actor, dispose := goactor.NewParentActor(goactor.DefaultChanMailbox)
+	sched.Go(func() {
+		_ = actor.Receive(func(message interface{}) (loop bool) {
+			if down, ok := message.(sysmsg.Down); ok {
+				onDown(down)
+			}
+			return true
+		})
+	})
+	return &Watcher{actor: actor, dispose: dispose}
+}
+
+// Watch starts monitoring the given actor. onDown gets called with the returned reference once the actor exits, even
+// if it has already exited.
+func (w *Watcher) Watch(pid *p.PID) (sysmsg.MonitorRef, error) {
+	return w.actor.Monitor(pid)
+}
+
+// Unwatch stops the monitor identified by the given reference. The watched actor may have exited already, so its Down
+// message could still reach onDown: the callers are expected to forget the reference along with unwatching it, and
+// to ignore the Down messages of the references they don't know. Then the returned error can be ignored as well,
+// since the watched actor is forgotten either way.
+func (w *Watcher) Unwatch(ref sysmsg.MonitorRef) error {
+	return w.actor.Demonitor(ref)
+}
+
+// Close stops the watcher's actor. onDown isn't called anymore, and watching fails from now on. The actors still
+// being watched should be unwatched first, so they don't try to notify the closed watcher once they exit.
+func (w *Watcher) Close() {
+	w.dispose()
+}
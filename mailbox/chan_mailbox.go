@@ -1,6 +1,8 @@
 package mailbox
 
 import (
+	"github.com/hedisam/goactor/clock"
+	"sync/atomic"
 	"time"
 )
 
@@ -9,6 +11,11 @@ type chanMailbox struct {
 	sysMsgChan  chan interface{}
 	sendTimeout time.Duration
 	done        chan struct{}
+	clock       clock.Clock
+	// front holds the messages prepended by PrependMessages, which are received before the ones in userMsgChan.
+	// it's only accessed by the receiving goroutine, frontLen lets other goroutines read its length.
+	front    []interface{}
+	frontLen int32
 }
 
 func NewChanMailbox(userMailboxCap, sysMailboxCap int, sendTimeout time.Duration) *chanMailbox {
@@ -17,9 +24,16 @@ func NewChanMailbox(userMailboxCap, sysMailboxCap int, sendTimeout time.Duration
 		sysMsgChan:  make(chan interface{}, sysMailboxCap),
 		done:        make(chan struct{}),
 		sendTimeout: sendTimeout,
+		clock:       clock.Real(),
 	}
 }
 
+// SetClock sets the clock the mailbox's timeouts are measured by, e.g. a fake clock in tests.
+func (m *chanMailbox) SetClock(c clock.Clock) *chanMailbox {
+	m.clock = clock.OrReal(c)
+	return m
+}
+
 func (m *chanMailbox) Receive(msgHandler, sysMsgHandler func(interface{}) bool) error {
 	// we could've delegate this to m.ReceiveWithTimeout(0, msgHandler, sysMsgHandler),
 	// but we won't do that for the sake of efficiency.
@@ -29,6 +43,13 @@ func (m *chanMailbox) Receive(msgHandler, sysMsgHandler func(interface{}) bool)
 			return ErrMailboxClosed
 		default:
 		}
+		if len(m.front) > 0 {
+			if !m.receiveFront(msgHandler, sysMsgHandler) {
+				// stop looping
+				return nil
+			}
+			continue
+		}
 		select {
 		case sysMsg := <-m.sysMsgChan:
 			if !sysMsgHandler(sysMsg) {
@@ -50,8 +71,8 @@ func (m *chanMailbox) ReceiveWithTimeout(timeout time.Duration, msgHandler, sysM
 	if timeout <= 0 {
 		return m.Receive(msgHandler, sysMsgHandler)
 	}
-	var ticker = time.NewTicker(timeout)
-	defer ticker.Stop()
+	var timer = m.clock.NewTimer(timeout)
+	defer timer.Stop()
 
 	for {
 		select {
@@ -59,6 +80,14 @@ func (m *chanMailbox) ReceiveWithTimeout(timeout time.Duration, msgHandler, sysM
 			return ErrMailboxClosed
 		default:
 		}
+		if len(m.front) > 0 {
+			if !m.receiveFront(msgHandler, sysMsgHandler) {
+				// stop looping
+				return nil
+			}
+			resetTimer(timer, timeout)
+			continue
+		}
 		select {
 		case sysMsg := <-m.sysMsgChan:
 			if !sysMsgHandler(sysMsg) {
@@ -72,14 +101,12 @@ func (m *chanMailbox) ReceiveWithTimeout(timeout time.Duration, msgHandler, sysM
 			}
 		case <-m.done:
 			return ErrMailboxClosed
-		case <-ticker.C:
+		case <-timer.C():
 			//msgHandler(TimedOut{})
 			return ErrMailboxReceiveTimeout
 		}
 
-		ticker.Reset(timeout)
-		// the next tick could've already been triggered. so drain the channel to prevent unwanted ticks.
-		drainChan(ticker.C)
+		resetTimer(timer, timeout)
 	}
 }
 
@@ -92,12 +119,12 @@ func (m *chanMailbox) PushSystemMessage(msg interface{}) error {
 }
 
 func (m *chanMailbox) push(msgChan chan<- interface{}, msg interface{}) error {
-	var timer *time.Timer
+	var timer clock.Timer
 	timeoutChan := make(<-chan time.Time, 1)
 
 	if m.sendTimeout > 0 {
-		timer = time.NewTimer(m.sendTimeout)
-		timeoutChan = timer.C
+		timer = m.clock.NewTimer(m.sendTimeout)
+		timeoutChan = timer.C()
 	}
 
 	if timer != nil {
@@ -119,6 +146,46 @@ func (m *chanMailbox) push(msgChan chan<- interface{}, msg interface{}) error {
 	}
 }
 
+// receiveFront handles the next prepended message, after the system messages which are already waiting.
+func (m *chanMailbox) receiveFront(msgHandler, sysMsgHandler func(interface{}) bool) bool {
+	select {
+	case sysMsg := <-m.sysMsgChan:
+		return sysMsgHandler(sysMsg)
+	default:
+	}
+	msg := m.front[0]
+	m.front[0] = nil
+	m.front = m.front[1:]
+	atomic.StoreInt32(&m.frontLen, int32(len(m.front)))
+	return msgHandler(msg)
+}
+
+// PrependMessages puts the messages, in the same order, in front of the user messages waiting in the mailbox.
+// It must only be called by the receiving goroutine, e.g. from within a message handler.
+func (m *chanMailbox) PrependMessages(messages []interface{}) error {
+	if m.Disposed() {
+		return ErrMailboxClosed
+	}
+	m.front = prepend(m.front, messages)
+	atomic.StoreInt32(&m.frontLen, int32(len(m.front)))
+	return nil
+}
+
+// Len returns the number of user messages waiting in the mailbox.
+func (m *chanMailbox) Len() int {
+	return len(m.userMsgChan) + int(atomic.LoadInt32(&m.frontLen))
+}
+
+// Disposed returns true once the mailbox has been disposed.
+func (m *chanMailbox) Disposed() bool {
+	select {
+	case <-m.done:
+		return true
+	default:
+		return false
+	}
+}
+
 func (m *chanMailbox) Dispose() {
 	select {
 	case <-m.done:
@@ -129,9 +196,23 @@ func (m *chanMailbox) Dispose() {
 	}
 }
 
-func drainChan(timeoutChan <-chan time.Time) {
-	select {
-	case <-timeoutChan:
-	default:
+// prepend returns the messages followed by the ones in front.
+func prepend(front []interface{}, messages []interface{}) []interface{} {
+	merged := make([]interface{}, 0, len(messages)+len(front))
+	merged = append(merged, messages...)
+	return append(merged, front...)
+}
+
+// resetTimer resets the timer, dropping its time if it has already fired.
+func resetTimer(timer clock.Timer, timeout time.Duration) {
+	if timer == nil {
+		return
+	}
+	if !timer.Stop() {
+		select {
+		case <-timer.C():
+		default:
+		}
 	}
+	timer.Reset(timeout)
 }
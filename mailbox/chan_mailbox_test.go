@@ -1,6 +1,7 @@
 package mailbox
 
 import (
+	"github.com/hedisam/goactor/clock"
 	"github.com/stretchr/testify/assert"
 	"sync"
 	"testing"
@@ -62,6 +63,28 @@ func TestChanMailbox_Receive(t *testing.T) {
 		assert.Equal(t, ErrMailboxReceiveTimeout, err)
 	})
 
+	t.Run("receive with timeout on a fake clock", func(t *testing.T) {
+		c := clock.NewFake(time.Now())
+		m := NewChanMailbox(2, 2, 10 * time.Millisecond).SetClock(c)
+		done := make(chan error, 1)
+		go func() {
+			done <- m.ReceiveWithTimeout(time.Hour, func(msg interface{}) bool {
+				return false
+			}, nil)
+		}()
+
+		// the timeout only elapses once the clock is advanced past it
+		c.BlockUntil(1)
+		c.Advance(time.Hour - time.Nanosecond)
+		select {
+		case err := <-done:
+			t.Fatalf("the receive returned before the timeout: %v", err)
+		default:
+		}
+		c.Advance(time.Nanosecond)
+		assert.Equal(t, ErrMailboxReceiveTimeout, <-done)
+	})
+
 	t.Run("timeout reset after reading user's new message", func(t *testing.T) {
 		m := NewChanMailbox(2, 2, 10 * time.Millisecond)
 		wg := sync.WaitGroup{}
@@ -137,6 +160,28 @@ func TestChanMailbox_Receive(t *testing.T) {
 	})
 }
 
+func TestChanMailbox_PrependMessages(t *testing.T) {
+	m := NewChanMailbox(5, 5, 10 * time.Millisecond)
+
+	assert.Nil(t, m.PushMessage(3))
+	assert.Nil(t, m.PushMessage(4))
+	var received []interface{}
+	err := m.Receive(func(msg interface{}) bool {
+		received = append(received, msg)
+		if len(received) == 1 {
+			// put the received message back along with one more, in front of the waiting message
+			assert.Nil(t, m.PrependMessages([]interface{}{2, 3}))
+			assert.Equal(t, 3, m.Len())
+		}
+		return len(received) < 4
+	}, nil)
+	if !assert.Nil(t, err) {return}
+	assert.Equal(t, []interface{}{3, 2, 3, 4}, received)
+
+	m.Dispose()
+	assert.Equal(t, ErrMailboxClosed, m.PrependMessages([]interface{}{1}))
+}
+
 func TestChanMailbox_Push(t *testing.T) {
 	testFunc := func(t *testing.T, n int, m *chanMailbox) {
 		var err error
@@ -146,6 +191,8 @@ func TestChanMailbox_Push(t *testing.T) {
 			err = m.PushSystemMessage(i)
 			assert.Nil(t, err)
 		}
+		// system messages are not counted
+		assert.Equal(t, n, m.Len())
 
 		err = m.PushMessage(n)
 		if !assert.NotNil(t, err) {return}
@@ -2,6 +2,7 @@ package mailbox
 
 import (
 	"github.com/Workiva/go-datastructures/queue"
+	"github.com/hedisam/goactor/clock"
 	"runtime"
 	"sync/atomic"
 	"time"
@@ -13,6 +14,11 @@ type queueMailbox struct {
 	sendTimeout         time.Duration
 	goSchedulerInterval uint16
 	disposed 			uint32
+	clock               clock.Clock
+	// front holds the messages prepended by PrependMessages, which are received before the ones in userMsgQueue.
+	// it's only accessed by the receiving goroutine, frontLen lets other goroutines read its length.
+	front    []interface{}
+	frontLen int32
 }
 
 func NewQueueMailbox(userMailboxCap, sysMailboxCap int, sendTimeout time.Duration, schedulerInterval uint16) *queueMailbox {
@@ -28,9 +34,16 @@ func NewQueueMailbox(userMailboxCap, sysMailboxCap int, sendTimeout time.Duratio
 		sysMsgQueue:         queue.NewRingBuffer(uint64(sysMailboxCap)),
 		sendTimeout:         sendTimeout,
 		goSchedulerInterval: schedulerInterval,
+		clock:               clock.Real(),
 	}
 }
 
+// SetClock sets the clock the mailbox's timeouts are measured by, e.g. a fake clock in tests.
+func (m *queueMailbox) SetClock(c clock.Clock) *queueMailbox {
+	m.clock = clock.OrReal(c)
+	return m
+}
+
 func (m *queueMailbox) Receive(msgHandler, sysMsgHandler func(interface{}) bool) error {
 	return m.ReceiveWithTimeout(0, msgHandler, sysMsgHandler)
 }
@@ -39,7 +52,7 @@ func (m *queueMailbox) ReceiveWithTimeout(timeout time.Duration, msgHandler, sys
 	var i uint16
 	var start time.Time
 	if timeout > 0 {
-		start = time.Now()
+		start = m.clock.Now()
 	}
 	for {
 		if atomic.LoadUint32(&m.disposed) == 1 {
@@ -58,12 +71,23 @@ func (m *queueMailbox) ReceiveWithTimeout(timeout time.Duration, msgHandler, sys
 			}
 			if timeout > 0 {
 				// we just processed a message so reset the start time
-				start = time.Now()
+				start = m.clock.Now()
 			}
 		}
 
-		// checking user mailbox
-		if m.userMsgQueue.Len() > 0 {
+		// checking user mailbox, starting with the prepended messages
+		if len(m.front) > 0 {
+			msg := m.popFront()
+			if !msgHandler(msg) {
+				// stop looping through the mailbox
+				return nil
+			}
+
+			if timeout > 0 {
+				// we just processed a message so reset the start time
+				start = m.clock.Now()
+			}
+		} else if m.userMsgQueue.Len() > 0 {
 			msg, err := m.userMsgQueue.Get()
 			if err != nil {
 				return ErrMailboxClosed
@@ -75,11 +99,11 @@ func (m *queueMailbox) ReceiveWithTimeout(timeout time.Duration, msgHandler, sys
 
 			if timeout > 0 {
 				// we just processed a message so reset the start time
-				start = time.Now()
+				start = m.clock.Now()
 			}
 		}
 
-		if timeout > 0 && time.Since(start) >= timeout {
+		if timeout > 0 && m.clock.Since(start) >= timeout {
 			return ErrMailboxReceiveTimeout
 		}
 
@@ -107,7 +131,7 @@ func (m *queueMailbox) PushSystemMessage(msg interface{}) error {
 func (m *queueMailbox) push(queue *queue.RingBuffer, msg interface{}) error {
 	var start time.Time
 	if m.sendTimeout > 0 {
-		start = time.Now()
+		start = m.clock.Now()
 	}
 	for {
 		ok, err := queue.Offer(msg)
@@ -117,13 +141,42 @@ func (m *queueMailbox) push(queue *queue.RingBuffer, msg interface{}) error {
 		if ok {
 			return nil
 		}
-		if m.sendTimeout > 0 && time.Since(start) >= m.sendTimeout {
+		if m.sendTimeout > 0 && m.clock.Since(start) >= m.sendTimeout {
 			return ErrMailboxEnqueueTimeout
 		}
 		runtime.Gosched()
 	}
 }
 
+// PrependMessages puts the messages, in the same order, in front of the user messages waiting in the mailbox.
+// It must only be called by the receiving goroutine, e.g. from within a message handler.
+func (m *queueMailbox) PrependMessages(messages []interface{}) error {
+	if atomic.LoadUint32(&m.disposed) == 1 {
+		return ErrMailboxClosed
+	}
+	m.front = prepend(m.front, messages)
+	atomic.StoreInt32(&m.frontLen, int32(len(m.front)))
+	return nil
+}
+
+func (m *queueMailbox) popFront() interface{} {
+	msg := m.front[0]
+	m.front[0] = nil
+	m.front = m.front[1:]
+	atomic.StoreInt32(&m.frontLen, int32(len(m.front)))
+	return msg
+}
+
+// Len returns the number of user messages waiting in the mailbox.
+func (m *queueMailbox) Len() int {
+	return int(m.userMsgQueue.Len()) + int(atomic.LoadInt32(&m.frontLen))
+}
+
+// Disposed returns true once the mailbox has been disposed.
+func (m *queueMailbox) Disposed() bool {
+	return atomic.LoadUint32(&m.disposed) == 1
+}
+
 func (m *queueMailbox) Dispose() {
 	atomic.StoreUint32(&m.disposed, 1)
 	m.sysMsgQueue.Dispose()
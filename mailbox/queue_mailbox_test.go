@@ -159,6 +159,8 @@ func TestQueueMailboxPush(t *testing.T) {
 		err = m.PushSystemMessage(fmt.Sprintf("sys msg #%d", i))
 		assert.Nil(t, err)
 	}
+	// system messages are not counted
+	assert.Equal(t, 2, m.Len())
 
 	var expectedError = ErrMailboxEnqueueTimeout
 	var disposed bool
@@ -226,6 +228,28 @@ func TestQueueMailbox_Dispose(t *testing.T) {
 	})
 }
 
+func TestQueueMailbox_PrependMessages(t *testing.T) {
+	m := NewQueueMailbox(5, 5, 10 * time.Millisecond, DefaultGoSchedulerInterval)
+
+	assert.Nil(t, m.PushMessage(3))
+	assert.Nil(t, m.PushMessage(4))
+	var received []interface{}
+	err := m.Receive(func(msg interface{}) bool {
+		received = append(received, msg)
+		if len(received) == 1 {
+			// put the received message back along with one more, in front of the waiting message
+			assert.Nil(t, m.PrependMessages([]interface{}{2, 3}))
+			assert.Equal(t, 3, m.Len())
+		}
+		return len(received) < 4
+	}, nil)
+	if !assert.Nil(t, err) {return}
+	assert.Equal(t, []interface{}{3, 2, 3, 4}, received)
+
+	m.Dispose()
+	assert.Equal(t, ErrMailboxClosed, m.PrependMessages([]interface{}{1}))
+}
+
 func TestNewQueueMailbox(t *testing.T) {
 	m := NewQueueMailbox(1, 1, 0, DefaultGoSchedulerInterval)
 
@@ -0,0 +1,512 @@
+package mailbox
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/hedisam/goactor/clock"
+	"github.com/hedisam/goactor/codec"
+	"github.com/hedisam/goactor/internal/wal"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const DefaultWALSegmentSize = 4 << 20
+
+const (
+	walMessageRecord byte = iota + 1
+	walAckRecord
+)
+
+const walSegmentExt = ".wal"
+
+// ErrWALRollFailed is returned by a wal mailbox when the messages have been pushed, but the log couldn't be rolled to
+// a new segment. The active segment keeps growing until the roll is retried by the next write.
+var ErrWALRollFailed = fmt.Errorf("mailbox: the message was pushed but the wal segment couldn't be rolled")
+
+// walMailbox is a durable mailbox. User messages are appended to a write-ahead log before they're queued, and
+// acknowledged once their handler returns, so the messages which weren't handled, e.g. because the process crashed
+// or the handler panicked, are queued again by the next mailbox opened on the same dir. Delivery is at least once:
+// a message whose handler returned right before a crash may be handled again.
+// User messages are kept in memory until they're handled, so the mailbox has no capacity. System messages are not
+// logged, since links and monitors don't outlive the actor.
+type walMailbox struct {
+	dir         string
+	codec       codec.Codec
+	segmentSize int64
+
+	fileMu sync.Mutex
+	// segments are the log's segment files in order, the last one is the active segment new records are appended to.
+	segments []*walSegment
+	active   *os.File
+	// unacked maps the sequence number of each unacknowledged message to its segment.
+	unacked map[uint64]*walSegment
+	nextSeq uint64
+
+	queueMu sync.Mutex
+	queue   []walMessage
+	// notify is signaled when a message is queued.
+	notify chan struct{}
+
+	// stashed keeps the sequence number of the messages which have been stashed by the receiving actor, they're only
+	// acknowledged once they're unstashed. stashing tells whether the message being handled has been stashed. both are
+	// only accessed by the receiving goroutine.
+	stashed  []uint64
+	stashing bool
+
+	sysMsgChan  chan interface{}
+	sendTimeout time.Duration
+	clock       clock.Clock
+	done        chan struct{}
+	disposeOnce sync.Once
+}
+
+type walSegment struct {
+	path    string
+	size    int64
+	unacked int
+}
+
+type walMessage struct {
+	seq uint64
+	msg interface{}
+}
+
+// NewWALMailbox opens a durable mailbox which keeps its log in dir, encoding the messages by the given codec.
+// The messages which weren't acknowledged by the previous mailbox opened on dir are queued before any new one.
+// Only one mailbox must be open on a dir at any time. The log is split into segment files of about segmentSize
+// bytes, and a segment is removed once all of its messages are acknowledged.
+func NewWALMailbox(dir string, c codec.Codec, sysMailboxCap int, sendTimeout time.Duration, segmentSize int64) (*walMailbox, error) {
+	if segmentSize <= 0 {
+		segmentSize = DefaultWALSegmentSize
+	}
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return nil, fmt.Errorf("mailbox: couldn't create wal dir: %w", err)
+	}
+
+	m := &walMailbox{
+		dir:         dir,
+		codec:       c,
+		segmentSize: segmentSize,
+		unacked:     make(map[uint64]*walSegment),
+		nextSeq:     1,
+		notify:      make(chan struct{}, 1),
+		sysMsgChan:  make(chan interface{}, sysMailboxCap),
+		sendTimeout: sendTimeout,
+		done:        make(chan struct{}),
+		clock:       clock.Real(),
+	}
+	err = m.recover()
+	if err != nil {
+		m.closeActive()
+		return nil, err
+	}
+	return m, nil
+}
+
+// recover reads the existing segments and queues the unacknowledged messages.
+func (m *walMailbox) recover() error {
+	paths, err := filepath.Glob(filepath.Join(m.dir, "*"+walSegmentExt))
+	if err != nil {
+		return fmt.Errorf("mailbox: couldn't list wal segments: %w", err)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		return segmentIndex(paths[i]) < segmentIndex(paths[j])
+	})
+
+	pending := make(map[uint64][]byte)
+	for _, path := range paths {
+		segment := &walSegment{path: path}
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("mailbox: couldn't read wal segment: %w", err)
+		}
+		offset, _ := wal.Scan(bytes.NewReader(content), func(seq uint64, data []byte) error {
+			if len(data) == 0 {
+				return nil
+			}
+			switch data[0] {
+			case walMessageRecord:
+				pending[seq] = data[1:]
+				m.unacked[seq] = segment
+				segment.unacked++
+			case walAckRecord:
+				if s, ok := m.unacked[seq]; ok {
+					s.unacked--
+					delete(m.unacked, seq)
+					delete(pending, seq)
+				}
+			}
+			if seq >= m.nextSeq {
+				m.nextSeq = seq + 1
+			}
+			return nil
+		})
+		if offset < int64(len(content)) {
+			// drop the partially written records left by a crash
+			err = os.Truncate(path, offset)
+			if err != nil {
+				return fmt.Errorf("mailbox: couldn't truncate wal segment: %w", err)
+			}
+		}
+		segment.size = offset
+		m.segments = append(m.segments, segment)
+	}
+
+	seqs := make([]uint64, 0, len(pending))
+	for seq := range pending {
+		seqs = append(seqs, seq)
+	}
+	sort.Slice(seqs, func(i, j int) bool {
+		return seqs[i] < seqs[j]
+	})
+	for _, seq := range seqs {
+		msg, err := m.codec.Decode(pending[seq])
+		if err != nil {
+			return fmt.Errorf("mailbox: couldn't decode message %d: %w", seq, err)
+		}
+		m.queue = append(m.queue, walMessage{seq: seq, msg: msg})
+	}
+	if len(m.queue) > 0 {
+		m.notify <- struct{}{}
+	}
+
+	if len(m.segments) == 0 {
+		return m.roll()
+	}
+	m.removeAcked()
+	active := m.segments[len(m.segments)-1]
+	m.active, err = os.OpenFile(active.path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("mailbox: couldn't open wal segment: %w", err)
+	}
+	return nil
+}
+
+func segmentIndex(path string) uint64 {
+	index, _ := strconv.ParseUint(strings.TrimSuffix(filepath.Base(path), walSegmentExt), 10, 64)
+	return index
+}
+
+// roll closes the active segment and creates a new one.
+func (m *walMailbox) roll() error {
+	var index uint64
+	if len(m.segments) > 0 {
+		index = segmentIndex(m.segments[len(m.segments)-1].path) + 1
+	}
+	path := filepath.Join(m.dir, fmt.Sprintf("%020d%s", index, walSegmentExt))
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("mailbox: couldn't create wal segment: %w", err)
+	}
+	m.closeActive()
+	m.active = file
+	m.segments = append(m.segments, &walSegment{path: path})
+	m.removeAcked()
+	return nil
+}
+
+// removeAcked removes the oldest segments as long as all of their messages have been acknowledged. The active
+// segment is never removed.
+func (m *walMailbox) removeAcked() {
+	for len(m.segments) > 1 && m.segments[0].unacked == 0 {
+		_ = os.Remove(m.segments[0].path)
+		m.segments = m.segments[1:]
+	}
+}
+
+func (m *walMailbox) closeActive() {
+	if m.active != nil {
+		_ = m.active.Close()
+		m.active = nil
+	}
+}
+
+// write appends a record to the active segment. Message records are synced before they're counted as unacknowledged,
+// while ack records are not synced at all: an ack is lost only if the os crashes, in which case the message is
+// handled again. The record is committed once write returns nil, the segment is rolled afterwards by rollIfFull.
+// The caller must hold fileMu.
+func (m *walMailbox) write(kind byte, seq uint64, data []byte) error {
+	if m.active == nil {
+		return ErrMailboxClosed
+	}
+	record := wal.AppendRecord(nil, seq, append([]byte{kind}, data...))
+	_, err := m.active.Write(record)
+	if err == nil && kind == walMessageRecord {
+		err = m.active.Sync()
+	}
+	if err != nil {
+		return fmt.Errorf("mailbox: couldn't write wal segment: %w", err)
+	}
+
+	segment := m.segments[len(m.segments)-1]
+	segment.size += int64(len(record))
+	if kind == walMessageRecord {
+		segment.unacked++
+		m.unacked[seq] = segment
+	}
+	return nil
+}
+
+// rollIfFull rolls the log to a new segment once the active one is full. The caller must hold fileMu.
+func (m *walMailbox) rollIfFull() error {
+	if m.active == nil || m.segments[len(m.segments)-1].size < m.segmentSize {
+		return nil
+	}
+	err := m.roll()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrWALRollFailed, err)
+	}
+	return nil
+}
+
+// SetClock sets the clock the mailbox's timeouts are measured by, e.g. a fake clock in tests.
+func (m *walMailbox) SetClock(c clock.Clock) *walMailbox {
+	m.clock = clock.OrReal(c)
+	return m
+}
+
+func (m *walMailbox) PushMessage(msg interface{}) error {
+	data, err := m.codec.Encode(msg)
+	if err != nil {
+		return fmt.Errorf("mailbox: couldn't encode message: %w", err)
+	}
+
+	m.fileMu.Lock()
+	if m.Disposed() {
+		m.fileMu.Unlock()
+		return ErrMailboxClosed
+	}
+	seq := m.nextSeq
+	err = m.write(walMessageRecord, seq, data)
+	if err != nil {
+		m.fileMu.Unlock()
+		return err
+	}
+	m.nextSeq++
+	// the message is queued while holding fileMu so the queue stays in the order of the log
+	m.queueMu.Lock()
+	m.queue = append(m.queue, walMessage{seq: seq, msg: msg})
+	m.queueMu.Unlock()
+	err = m.rollIfFull()
+	m.fileMu.Unlock()
+
+	select {
+	case m.notify <- struct{}{}:
+	default:
+	}
+	return err
+}
+
+// StashCurrent defers the acknowledgement of the message being handled until the stashed messages are prepended, so
+// a stashed message is replayed after a crash, rather than lost.
+func (m *walMailbox) StashCurrent() {
+	m.stashing = true
+}
+
+// PrependMessages puts the messages, in the same order, in front of the user messages waiting in the mailbox.
+// The messages are logged again, and the stashed ones are acknowledged once they're all logged; after a crash they're
+// replayed in the order they were prepended, not in their original order. A crash in between replays them twice.
+func (m *walMailbox) PrependMessages(messages []interface{}) error {
+	encoded := make([][]byte, len(messages))
+	for i, msg := range messages {
+		data, err := m.codec.Encode(msg)
+		if err != nil {
+			return fmt.Errorf("mailbox: couldn't encode message: %w", err)
+		}
+		encoded[i] = data
+	}
+
+	m.fileMu.Lock()
+	defer m.fileMu.Unlock()
+	if m.Disposed() {
+		return ErrMailboxClosed
+	}
+	prepended := make([]walMessage, 0, len(messages)+len(m.queue))
+	var err, rollErr error
+	for i, data := range encoded {
+		seq := m.nextSeq
+		err = m.write(walMessageRecord, seq, data)
+		if err != nil {
+			break
+		}
+		m.nextSeq++
+		prepended = append(prepended, walMessage{seq: seq, msg: messages[i]})
+		if e := m.rollIfFull(); e != nil {
+			rollErr = e
+		}
+	}
+	// the messages which have been logged are queued even if the rest failed, they'd be replayed after a crash anyway.
+	m.queueMu.Lock()
+	m.queue = append(prepended, m.queue...)
+	m.queueMu.Unlock()
+	if err != nil {
+		// the stashed messages are kept unacknowledged, since some of them haven't been logged again.
+		return err
+	}
+	for _, seq := range m.stashed {
+		err = m.ackLocked(seq)
+		if err != nil {
+			return err
+		}
+	}
+	m.stashed = nil
+	return rollErr
+}
+
+func (m *walMailbox) PushSystemMessage(msg interface{}) error {
+	var timeoutChan <-chan time.Time
+	if m.sendTimeout > 0 {
+		timer := m.clock.NewTimer(m.sendTimeout)
+		defer timer.Stop()
+		timeoutChan = timer.C()
+	}
+
+	select {
+	case <-m.done:
+		return ErrMailboxClosed
+	default:
+	}
+	select {
+	case <-m.done:
+		return ErrMailboxClosed
+	case <-timeoutChan:
+		return ErrMailboxEnqueueTimeout
+	case m.sysMsgChan <- msg:
+		return nil
+	}
+}
+
+// ack writes the acknowledgement of a handled message.
+func (m *walMailbox) ack(seq uint64) error {
+	m.fileMu.Lock()
+	defer m.fileMu.Unlock()
+	return m.ackLocked(seq)
+}
+
+// ackLocked must be called with fileMu held.
+func (m *walMailbox) ackLocked(seq uint64) error {
+	segment, ok := m.unacked[seq]
+	if !ok {
+		return nil
+	}
+	err := m.write(walAckRecord, seq, nil)
+	if err != nil {
+		return err
+	}
+	delete(m.unacked, seq)
+	segment.unacked--
+	m.removeAcked()
+	// the message has been handled anyway, a failed roll is retried by the next write.
+	_ = m.rollIfFull()
+	return nil
+}
+
+func (m *walMailbox) pop() (walMessage, bool) {
+	m.queueMu.Lock()
+	defer m.queueMu.Unlock()
+
+	if len(m.queue) == 0 {
+		return walMessage{}, false
+	}
+	message := m.queue[0]
+	m.queue[0] = walMessage{}
+	m.queue = m.queue[1:]
+	return message, true
+}
+
+func (m *walMailbox) Receive(msgHandler, sysMsgHandler func(interface{}) bool) error {
+	return m.ReceiveWithTimeout(0, msgHandler, sysMsgHandler)
+}
+
+func (m *walMailbox) ReceiveWithTimeout(timeout time.Duration, msgHandler, sysMsgHandler func(interface{}) bool) error {
+	var timeoutChan <-chan time.Time
+	var timer clock.Timer
+	if timeout > 0 {
+		timer = m.clock.NewTimer(timeout)
+		defer timer.Stop()
+		timeoutChan = timer.C()
+	}
+
+	for {
+		select {
+		case <-m.done:
+			return ErrMailboxClosed
+		default:
+		}
+
+		// our first priority is system messages
+		select {
+		case sysMsg := <-m.sysMsgChan:
+			if !sysMsgHandler(sysMsg) {
+				return nil
+			}
+			resetTimer(timer, timeout)
+			continue
+		default:
+		}
+
+		if message, ok := m.pop(); ok {
+			m.stashing = false
+			loop := msgHandler(message.msg)
+			// we only get here if the handler didn't panic
+			if m.stashing {
+				m.stashed = append(m.stashed, message.seq)
+			} else if err := m.ack(message.seq); err != nil && err != ErrMailboxClosed {
+				return err
+			}
+			if !loop {
+				return nil
+			}
+			resetTimer(timer, timeout)
+			continue
+		}
+
+		select {
+		case sysMsg := <-m.sysMsgChan:
+			if !sysMsgHandler(sysMsg) {
+				return nil
+			}
+			resetTimer(timer, timeout)
+		case <-m.notify:
+		case <-m.done:
+			return ErrMailboxClosed
+		case <-timeoutChan:
+			return ErrMailboxReceiveTimeout
+		}
+	}
+}
+
+// Len returns the number of user messages waiting in the mailbox.
+func (m *walMailbox) Len() int {
+	m.queueMu.Lock()
+	defer m.queueMu.Unlock()
+	return len(m.queue)
+}
+
+// Disposed returns true once the mailbox has been disposed.
+func (m *walMailbox) Disposed() bool {
+	select {
+	case <-m.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Dispose closes the mailbox and its log. The unacknowledged messages are kept in the log for the next mailbox
+// opened on the same dir.
+func (m *walMailbox) Dispose() {
+	m.disposeOnce.Do(func() {
+		m.fileMu.Lock()
+		defer m.fileMu.Unlock()
+		close(m.done)
+		m.closeActive()
+	})
+}
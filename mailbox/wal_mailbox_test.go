@@ -0,0 +1,220 @@
+package mailbox
+
+import (
+	"errors"
+	"fmt"
+	"github.com/hedisam/goactor/codec"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestWALMailbox(t *testing.T, dir string, segmentSize int64) *walMailbox {
+	m, err := NewWALMailbox(dir, codec.Gob(), DefaultSysMailboxCap, DefaultMailboxTimeout, segmentSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func receiveN(t *testing.T, m *walMailbox, n int) []interface{} {
+	var received []interface{}
+	if n == 0 {
+		return received
+	}
+	err := m.ReceiveWithTimeout(time.Second, func(msg interface{}) bool {
+		received = append(received, msg)
+		return len(received) < n
+	}, nil)
+	assert.Nil(t, err)
+	return received
+}
+
+func TestWALMailbox_Replay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wal")
+	if !assert.Nil(t, err) {return}
+	defer os.RemoveAll(dir)
+
+	m := newTestWALMailbox(t, dir, 0)
+	for i := 1; i <= 5; i++ {
+		if !assert.Nil(t, m.PushMessage(i)) {return}
+	}
+	assert.Equal(t, 5, m.Len())
+	assert.Equal(t, []interface{}{1, 2}, receiveN(t, m, 2))
+
+	// the third message's handler panics so it's not acknowledged
+	assert.Panics(t, func() {
+		_ = m.Receive(func(msg interface{}) bool {
+			panic("handler failed")
+		}, nil)
+	})
+	m.Dispose()
+	assert.True(t, m.Disposed())
+	assert.Equal(t, ErrMailboxClosed, m.PushMessage(6))
+
+	// the unacknowledged messages are queued again, before the new ones
+	m = newTestWALMailbox(t, dir, 0)
+	assert.Nil(t, m.PushMessage(6))
+	assert.Equal(t, []interface{}{3, 4, 5, 6}, receiveN(t, m, 4))
+	m.Dispose()
+
+	m = newTestWALMailbox(t, dir, 0)
+	defer m.Dispose()
+	assert.Equal(t, 0, m.Len())
+	err = m.ReceiveWithTimeout(10*time.Millisecond, func(msg interface{}) bool {
+		return false
+	}, nil)
+	assert.Equal(t, ErrMailboxReceiveTimeout, err)
+}
+
+func TestWALMailbox_Segments(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wal")
+	if !assert.Nil(t, err) {return}
+	defer os.RemoveAll(dir)
+
+	m := newTestWALMailbox(t, dir, 128)
+	for i := 0; i < 50; i++ {
+		if !assert.Nil(t, m.PushMessage(i)) {return}
+	}
+	segments, _ := filepath.Glob(filepath.Join(dir, "*.wal"))
+	assert.Greater(t, len(segments), 1)
+
+	receiveN(t, m, 49)
+	m.Dispose()
+
+	// the segments before the one holding the unacknowledged message are removed
+	remaining, _ := filepath.Glob(filepath.Join(dir, "*.wal"))
+	assert.NotContains(t, remaining, segments[0])
+	assert.Contains(t, remaining, segments[len(segments)-1])
+	m = newTestWALMailbox(t, dir, 128)
+	defer m.Dispose()
+	assert.Equal(t, []interface{}{49}, receiveN(t, m, 1))
+}
+
+func TestWALMailbox_TornWrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wal")
+	if !assert.Nil(t, err) {return}
+	defer os.RemoveAll(dir)
+
+	m := newTestWALMailbox(t, dir, 0)
+	assert.Nil(t, m.PushMessage("first"))
+	m.Dispose()
+
+	// simulate a crash in the middle of writing a message
+	segments, _ := filepath.Glob(filepath.Join(dir, "*.wal"))
+	if !assert.Len(t, segments, 1) {return}
+	file, err := os.OpenFile(segments[0], os.O_WRONLY|os.O_APPEND, 0644)
+	if !assert.Nil(t, err) {return}
+	_, err = file.Write([]byte{0, 0, 0, 42, 1, 2})
+	assert.Nil(t, err)
+	assert.Nil(t, file.Close())
+
+	m = newTestWALMailbox(t, dir, 0)
+	defer m.Dispose()
+	assert.Nil(t, m.PushMessage("second"))
+	assert.Equal(t, []interface{}{"first", "second"}, receiveN(t, m, 2))
+}
+
+func TestWALMailbox_SystemMessages(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wal")
+	if !assert.Nil(t, err) {return}
+	defer os.RemoveAll(dir)
+
+	m := newTestWALMailbox(t, dir, 0)
+	assert.Nil(t, m.PushMessage("user"))
+	assert.Nil(t, m.PushSystemMessage("system"))
+
+	// system messages are received first
+	var received []interface{}
+	handler := func(msg interface{}) bool {
+		received = append(received, msg)
+		return len(received) < 2
+	}
+	assert.Nil(t, m.Receive(handler, handler))
+	assert.Equal(t, []interface{}{"system", "user"}, received)
+
+	m.Dispose()
+	assert.Equal(t, ErrMailboxClosed, m.PushSystemMessage("system"))
+	assert.Equal(t, ErrMailboxClosed, m.Receive(handler, handler))
+}
+
+func TestWALMailbox_PrependMessages(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wal")
+	if !assert.Nil(t, err) {return}
+	defer os.RemoveAll(dir)
+
+	m := newTestWALMailbox(t, dir, 0)
+	assert.Nil(t, m.PushMessage("second"))
+	assert.Nil(t, m.PushMessage("third"))
+	assert.Equal(t, []interface{}{"second"}, receiveN(t, m, 1))
+	assert.Nil(t, m.PrependMessages([]interface{}{"first", "second"}))
+	m.Dispose()
+
+	// the prepended messages are logged again, since the received one has been acknowledged
+	m = newTestWALMailbox(t, dir, 0)
+	defer m.Dispose()
+	assert.Equal(t, []interface{}{"third", "first", "second"}, receiveN(t, m, 3))
+}
+
+func TestWALMailbox_StashCurrent(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wal")
+	if !assert.Nil(t, err) {return}
+	defer os.RemoveAll(dir)
+
+	m := newTestWALMailbox(t, dir, 0)
+	assert.Nil(t, m.PushMessage("first"))
+	assert.Nil(t, m.PushMessage("second"))
+	err = m.ReceiveWithTimeout(time.Second, func(msg interface{}) bool {
+		m.StashCurrent()
+		return false
+	}, nil)
+	assert.Nil(t, err)
+	m.Dispose()
+
+	// the stashed message isn't acknowledged until it's prepended
+	m = newTestWALMailbox(t, dir, 0)
+	err = m.ReceiveWithTimeout(time.Second, func(msg interface{}) bool {
+		m.StashCurrent()
+		return false
+	}, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, m.PrependMessages([]interface{}{"first"}))
+	m.Dispose()
+
+	// it's replayed once, from where it was prepended
+	m = newTestWALMailbox(t, dir, 0)
+	defer m.Dispose()
+	assert.Equal(t, 2, m.Len())
+	assert.Equal(t, []interface{}{"second", "first"}, receiveN(t, m, 2))
+}
+
+func TestWALMailbox_RollFailure(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wal")
+	if !assert.Nil(t, err) {return}
+	defer os.RemoveAll(dir)
+
+	m := newTestWALMailbox(t, dir, 1)
+	// a dir in place of the next segment makes the roll fail
+	next := filepath.Join(dir, fmt.Sprintf("%020d%s", 1, walSegmentExt))
+	if !assert.Nil(t, os.Mkdir(next, 0755)) {return}
+
+	// the messages are pushed anyway, each with its own seq
+	assert.True(t, errors.Is(m.PushMessage("a"), ErrWALRollFailed))
+	assert.True(t, errors.Is(m.PushMessage("b"), ErrWALRollFailed))
+	assert.True(t, errors.Is(m.PrependMessages([]interface{}{"prepended"}), ErrWALRollFailed))
+	assert.Equal(t, 3, m.Len())
+
+	// the roll is retried by the next write
+	if !assert.Nil(t, os.Remove(next)) {return}
+	assert.Nil(t, m.PushMessage("c"))
+	segments, _ := filepath.Glob(filepath.Join(dir, "*.wal"))
+	assert.Len(t, segments, 2)
+	m.Dispose()
+
+	m = newTestWALMailbox(t, dir, 1)
+	defer m.Dispose()
+	assert.Equal(t, []interface{}{"a", "b", "prepended", "c"}, receiveN(t, m, 4))
+}
@@ -3,6 +3,8 @@ package goactor
 import (
 	"github.com/hedisam/goactor/internal/intlpid"
 	"github.com/hedisam/goactor/internal/relations"
+	p "github.com/hedisam/goactor/pid"
+	"github.com/hedisam/goactor/sysmsg"
 	"time"
 )
 
@@ -14,20 +16,43 @@ type Mailbox interface {
 	Dispose()
 }
 
+// prependableMailbox is implemented by the mailboxes which can put messages back in front of the waiting ones, so
+// the actor's stashed messages can be unstashed.
+type prependableMailbox interface {
+	PrependMessages(messages []interface{}) error
+}
+
+// stashingMailbox is implemented by the mailboxes which acknowledge the messages once they're handled, e.g. the WAL
+// mailbox. It's told when the message being handled gets stashed, so it's only acknowledged once it's unstashed.
+type stashingMailbox interface {
+	StashCurrent()
+}
+
 type relationManager interface {
 	AddLink(pid intlpid.InternalPID) error
 	RemoveLink(pid intlpid.InternalPID) error
 
-	AddMonitored(pid intlpid.InternalPID) error
-	RemoveMonitored(pid intlpid.InternalPID) error
+	AddMonitored(ref string, pid intlpid.InternalPID) error
+	RemoveMonitored(ref string) error
+	Monitored(ref string) (intlpid.InternalPID, bool)
 
 	LinkedActors() *relations.RelationIterator
-	MonitorActors() *relations.RelationIterator
+	MonitorActors() *relations.MonitorIterator
 
 	RelationType(pid intlpid.InternalPID) relations.RelationType
 	Dispose()
 }
 
+// Linker is implemented by processes that can be linked to other actors, i.e. actors and supervisors.
+type Linker interface {
+	Link(pid *p.PID) error
+}
+
+// Monitorer is implemented by processes that can monitor other actors, e.g. actors and pidtest's pids.
+type Monitorer interface {
+	Monitor(pid *p.PID) (sysmsg.MonitorRef, error)
+}
+
 type ActorFunc func(actor *Actor)
 type MailboxBuilderFunc func() Mailbox
 type MessageHandler func(message interface{}) (loop bool)
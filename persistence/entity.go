@@ -0,0 +1,151 @@
+package persistence
+
+import (
+	"fmt"
+	"github.com/hedisam/goactor"
+	p "github.com/hedisam/goactor/pid"
+	"github.com/hedisam/goactor/supervisor/spec"
+	"log"
+)
+
+// Entity declares an event sourced actor.
+type Entity struct {
+	// ID is the persistence id of the entity. Its events and snapshots are stored by it, so only one actor must be
+	// running for an id at any time.
+	ID        string
+	Journal   Journal
+	Snapshots SnapshotStore
+	// SnapshotEvery is the number of events persisted between snapshots. Snapshots are disabled if it's zero or
+	// Snapshots is nil.
+	SnapshotEvery uint64
+	// Init returns the state of the entity before any event is applied.
+	Init func() interface{}
+	// Apply returns the state after applying the event. It's called for persisted events only, both while recovering
+	// and after Context.Persist, so it must not have any side effect. The state must be treated as immutable since it
+	// may be kept by a snapshot store.
+	Apply func(state interface{}, event interface{}) interface{}
+	// Handle handles the messages the entity receives once it's recovered. Commands are turned into events by
+	// Context.Persist.
+	Handle func(ctx *Context, message interface{}) (loop bool)
+}
+
+// Context gives the entity's Handle access to its state.
+type Context struct {
+	entity      Entity
+	actor       *goactor.Actor
+	state       interface{}
+	seq         uint64
+	snapshotSeq uint64
+}
+
+// Spawn spawns the entity. The actor recovers the entity's state before handling any message, and exits if that
+// fails.
+func Spawn(entity Entity, mailboxBuilder goactor.MailboxBuilderFunc) (*p.PID, error) {
+	err := entity.validate()
+	if err != nil {
+		return nil, err
+	}
+	return goactor.Spawn(actorFunc(entity), mailboxBuilder), nil
+}
+
+// ChildSpec returns a worker spec which starts the entity under a supervisor. Each restart recovers the state
+// persisted by the previous run.
+func ChildSpec(name string, restartWhen int, entity Entity) spec.WorkerSpec {
+	return spec.NewWorkerSpec(name, restartWhen, actorFunc(entity))
+}
+
+func (e Entity) validate() error {
+	switch {
+	case e.ID == "":
+		return fmt.Errorf("%w: empty persistence id", ErrInvalidEntity)
+	case e.Journal == nil:
+		return fmt.Errorf("%w: nil journal", ErrInvalidEntity)
+	case e.Init == nil || e.Apply == nil || e.Handle == nil:
+		return fmt.Errorf("%w: Init, Apply and Handle must be set", ErrInvalidEntity)
+	}
+	return nil
+}
+
+func actorFunc(entity Entity) goactor.ActorFunc {
+	return func(actor *goactor.Actor) {
+		err := entity.validate()
+		if err != nil {
+			panic(err)
+		}
+		ctx := &Context{entity: entity, actor: actor}
+		err = ctx.recover()
+		if err != nil {
+			panic(err)
+		}
+
+		_ = actor.Receive(func(message interface{}) (loop bool) {
+			return entity.Handle(ctx, message)
+		})
+	}
+}
+
+func (ctx *Context) recover() error {
+	ctx.state = ctx.entity.Init()
+	if ctx.entity.Snapshots != nil {
+		snapshot, ok, err := ctx.entity.Snapshots.LoadSnapshot(ctx.entity.ID)
+		if err != nil {
+			return fmt.Errorf("persistence: couldn't load snapshot: %w", err)
+		} else if ok {
+			ctx.state = snapshot.State
+			ctx.seq = snapshot.Seq
+			ctx.snapshotSeq = snapshot.Seq
+		}
+	}
+
+	err := ctx.entity.Journal.Replay(ctx.entity.ID, ctx.seq+1, func(event Event) {
+		ctx.state = ctx.entity.Apply(ctx.state, event.Payload)
+		ctx.seq = event.Seq
+	})
+	if err != nil {
+		return fmt.Errorf("persistence: couldn't replay events: %w", err)
+	}
+	return nil
+}
+
+// Persist appends the events to the journal, then applies them to the state. Nothing is applied if the events
+// couldn't be appended, in which case the entity should exit so its state is recovered from the journal again.
+func (ctx *Context) Persist(events ...interface{}) error {
+	if len(events) == 0 {
+		return nil
+	}
+	err := ctx.entity.Journal.Append(ctx.entity.ID, ctx.seq+1, events)
+	if err != nil {
+		return fmt.Errorf("persistence: couldn't persist events: %w", err)
+	}
+	for _, event := range events {
+		ctx.state = ctx.entity.Apply(ctx.state, event)
+		ctx.seq++
+	}
+
+	if ctx.entity.Snapshots != nil && ctx.entity.SnapshotEvery > 0 &&
+		ctx.seq-ctx.snapshotSeq >= ctx.entity.SnapshotEvery {
+		// the events are already in the journal, so a failed snapshot only makes the next recovery longer
+		err = ctx.entity.Snapshots.SaveSnapshot(ctx.entity.ID, Snapshot{Seq: ctx.seq, State: ctx.state})
+		if err != nil {
+			log.Printf("[!] persistence entity %s couldn't save snapshot: %v\n", ctx.entity.ID, err)
+		} else {
+			ctx.snapshotSeq = ctx.seq
+		}
+	}
+	return nil
+}
+
+// State returns the entity's current state.
+func (ctx *Context) State() interface{} {
+	return ctx.state
+}
+
+// Seq returns the sequence number of the last event applied to the state.
+func (ctx *Context) Seq() uint64 {
+	return ctx.seq
+}
+
+// Self returns the pid of the entity's actor.
+func (ctx *Context) Self() *p.PID {
+	return ctx.actor.Self()
+}
@@ -0,0 +1,218 @@
+package persistence
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/hedisam/goactor/codec"
+	"github.com/hedisam/goactor/internal/wal"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+var ErrCorruptedSnapshot = fmt.Errorf("persistence: snapshot file is corrupted")
+
+// openJournal opens a journal file for reading. Tests replace it to fail the reads.
+var openJournal = func(path string) (io.ReadCloser, error) {
+	return os.Open(path)
+}
+
+// FileJournal is an append-only journal which keeps the events of each persistence id in a file of its own.
+// A record which was partially written, e.g. because the process crashed, is dropped once the file is appended again.
+type FileJournal struct {
+	dir   string
+	codec codec.Codec
+	// highest keeps the sequence number of the last event of the persistence ids which have been appended.
+	highest map[string]uint64
+	sync.Mutex
+}
+
+// NewFileJournal returns a journal which keeps its files in dir, encoding the events by the given codec.
+func NewFileJournal(dir string, c codec.Codec) (*FileJournal, error) {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return nil, fmt.Errorf("persistence: couldn't create journal dir: %w", err)
+	}
+	return &FileJournal{dir: dir, codec: c, highest: make(map[string]uint64)}, nil
+}
+
+func (j *FileJournal) path(persistenceID string) string {
+	return filepath.Join(j.dir, hex.EncodeToString([]byte(persistenceID))+".journal")
+}
+
+func (j *FileJournal) Append(persistenceID string, seq uint64, events []interface{}) error {
+	j.Lock()
+	defer j.Unlock()
+
+	highest, ok := j.highest[persistenceID]
+	if !ok {
+		var err error
+		highest, err = j.recover(persistenceID)
+		if err != nil {
+			return err
+		}
+		j.highest[persistenceID] = highest
+	}
+	if seq != highest+1 {
+		return fmt.Errorf("%w: expected %d, got %d", ErrSequenceConflict, highest+1, seq)
+	}
+
+	var buf []byte
+	for i, payload := range events {
+		data, err := j.codec.Encode(payload)
+		if err != nil {
+			return fmt.Errorf("persistence: couldn't encode event: %w", err)
+		}
+		buf = wal.AppendRecord(buf, seq+uint64(i), data)
+	}
+
+	file, err := os.OpenFile(j.path(persistenceID), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("persistence: couldn't open journal file: %w", err)
+	}
+	defer file.Close()
+	_, err = file.Write(buf)
+	if err == nil {
+		err = file.Sync()
+	}
+	if err != nil {
+		// the next append recovers the file from the partially written records
+		delete(j.highest, persistenceID)
+		return fmt.Errorf("persistence: couldn't write journal file: %w", err)
+	}
+	j.highest[persistenceID] = seq + uint64(len(events)) - 1
+	return nil
+}
+
+// recover returns the sequence number of the last valid record of the persistence id's file, and truncates the
+// file after it. The file is left as it is if it couldn't be read.
+func (j *FileJournal) recover(persistenceID string) (uint64, error) {
+	var highest uint64
+	offset, err := j.scan(persistenceID, func(seq uint64, _ []byte) error {
+		highest = seq
+		return nil
+	})
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	} else if err != nil {
+		return 0, fmt.Errorf("persistence: couldn't read journal file: %w", err)
+	}
+
+	err = os.Truncate(j.path(persistenceID), offset)
+	if err != nil {
+		return 0, fmt.Errorf("persistence: couldn't truncate journal file: %w", err)
+	}
+	return highest, nil
+}
+
+func (j *FileJournal) Replay(persistenceID string, fromSeq uint64, fn func(event Event)) error {
+	_, err := j.scan(persistenceID, func(seq uint64, data []byte) error {
+		if seq < fromSeq {
+			return nil
+		}
+		payload, err := j.codec.Decode(data)
+		if err != nil {
+			return fmt.Errorf("persistence: couldn't decode event %d: %w", seq, err)
+		}
+		fn(Event{Seq: seq, Payload: payload})
+		return nil
+	})
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// scan calls fn for each valid record of the persistence id's file, and returns the offset after the last one.
+func (j *FileJournal) scan(persistenceID string, fn func(seq uint64, data []byte) error) (int64, error) {
+	file, err := openJournal(j.path(persistenceID))
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	return wal.Scan(file, fn)
+}
+
+// FileSnapshotStore is a snapshot store which keeps the latest snapshot of each persistence id in a file of its own.
+type FileSnapshotStore struct {
+	dir   string
+	codec codec.Codec
+	sync.Mutex
+}
+
+// NewFileSnapshotStore returns a snapshot store which keeps its files in dir, encoding the states by the given codec.
+func NewFileSnapshotStore(dir string, c codec.Codec) (*FileSnapshotStore, error) {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return nil, fmt.Errorf("persistence: couldn't create snapshot dir: %w", err)
+	}
+	return &FileSnapshotStore{dir: dir, codec: c}, nil
+}
+
+func (s *FileSnapshotStore) path(persistenceID string) string {
+	return filepath.Join(s.dir, hex.EncodeToString([]byte(persistenceID))+".snapshot")
+}
+
+func (s *FileSnapshotStore) SaveSnapshot(persistenceID string, snapshot Snapshot) error {
+	data, err := s.codec.Encode(snapshot.State)
+	if err != nil {
+		return fmt.Errorf("persistence: couldn't encode snapshot: %w", err)
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	// the snapshot is written to a temp file first, so a crash never leaves a partially written snapshot behind
+	tmp, err := ioutil.TempFile(s.dir, "snapshot-*")
+	if err != nil {
+		return fmt.Errorf("persistence: couldn't create snapshot file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(wal.AppendRecord(nil, snapshot.Seq, data))
+	if err == nil {
+		err = tmp.Sync()
+	}
+	closeErr := tmp.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("persistence: couldn't write snapshot file: %w", err)
+	}
+
+	err = os.Rename(tmp.Name(), s.path(persistenceID))
+	if err != nil {
+		return fmt.Errorf("persistence: couldn't write snapshot file: %w", err)
+	}
+	return nil
+}
+
+func (s *FileSnapshotStore) LoadSnapshot(persistenceID string) (Snapshot, bool, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	content, err := ioutil.ReadFile(s.path(persistenceID))
+	if errors.Is(err, os.ErrNotExist) {
+		return Snapshot{}, false, nil
+	} else if err != nil {
+		return Snapshot{}, false, fmt.Errorf("persistence: couldn't read snapshot file: %w", err)
+	}
+	var seq uint64
+	var data []byte
+	offset, _ := wal.Scan(bytes.NewReader(content), func(recordSeq uint64, recordData []byte) error {
+		seq, data = recordSeq, recordData
+		return nil
+	})
+	if offset == 0 || offset != int64(len(content)) {
+		return Snapshot{}, false, ErrCorruptedSnapshot
+	}
+	state, err := s.codec.Decode(data)
+	if err != nil {
+		return Snapshot{}, false, fmt.Errorf("persistence: couldn't decode snapshot: %w", err)
+	}
+	return Snapshot{Seq: seq, State: state}, true, nil
+}
@@ -0,0 +1,111 @@
+// Package persistence provides event sourced actors. An entity persists the events its commands produce to a journal
+// before applying them to its state, and recovers the state by replaying them once it's started again, e.g. when it's
+// restarted by its supervisor. Snapshots of the state can be saved periodically so the replay doesn't begin from the
+// first event.
+package persistence
+
+import (
+	"fmt"
+	"sync"
+)
+
+var ErrSequenceConflict = fmt.Errorf("persistence: sequence number doesn't follow the last appended event")
+var ErrInvalidEntity = fmt.Errorf("persistence: invalid entity")
+
+// Event is a persisted event. Sequence numbers of a persistence id start from 1.
+type Event struct {
+	Seq     uint64
+	Payload interface{}
+}
+
+// Journal stores the events of persistence ids.
+type Journal interface {
+	// Append appends the events of the persistence id. seq is the sequence number of the first event, and it must
+	// follow the last appended event of the id, otherwise ErrSequenceConflict is returned.
+	Append(persistenceID string, seq uint64, events []interface{}) error
+	// Replay calls fn for each event of the persistence id whose sequence number is at least fromSeq, in order.
+	Replay(persistenceID string, fromSeq uint64, fn func(event Event)) error
+}
+
+// Snapshot is the state of an entity after the event with the sequence number Seq has been applied.
+type Snapshot struct {
+	Seq   uint64
+	State interface{}
+}
+
+// SnapshotStore keeps the latest snapshot of persistence ids.
+type SnapshotStore interface {
+	SaveSnapshot(persistenceID string, snapshot Snapshot) error
+	// LoadSnapshot returns the latest snapshot of the persistence id, and false if there's none.
+	LoadSnapshot(persistenceID string) (Snapshot, bool, error)
+}
+
+// MemoryJournal is a journal which keeps the events in memory. It outlives the entities which use it, not the process.
+type MemoryJournal struct {
+	events map[string][]Event
+	sync.RWMutex
+}
+
+// NewMemoryJournal returns an empty memory journal.
+func NewMemoryJournal() *MemoryJournal {
+	return &MemoryJournal{events: make(map[string][]Event)}
+}
+
+func (j *MemoryJournal) Append(persistenceID string, seq uint64, events []interface{}) error {
+	j.Lock()
+	defer j.Unlock()
+
+	stored := j.events[persistenceID]
+	if seq != uint64(len(stored))+1 {
+		return fmt.Errorf("%w: expected %d, got %d", ErrSequenceConflict, len(stored)+1, seq)
+	}
+	for i, payload := range events {
+		stored = append(stored, Event{Seq: seq + uint64(i), Payload: payload})
+	}
+	j.events[persistenceID] = stored
+	return nil
+}
+
+func (j *MemoryJournal) Replay(persistenceID string, fromSeq uint64, fn func(event Event)) error {
+	j.RLock()
+	stored := j.events[persistenceID]
+	j.RUnlock()
+
+	// events are only appended, so the slice we've got is never modified
+	for _, event := range stored {
+		if event.Seq >= fromSeq {
+			fn(event)
+		}
+	}
+	return nil
+}
+
+// MemorySnapshotStore is a snapshot store which keeps the snapshots in memory.
+type MemorySnapshotStore struct {
+	snapshots map[string]Snapshot
+	sync.RWMutex
+}
+
+// NewMemorySnapshotStore returns an empty memory snapshot store.
+func NewMemorySnapshotStore() *MemorySnapshotStore {
+	return &MemorySnapshotStore{snapshots: make(map[string]Snapshot)}
+}
+
+func (s *MemorySnapshotStore) SaveSnapshot(persistenceID string, snapshot Snapshot) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if latest, ok := s.snapshots[persistenceID]; ok && latest.Seq > snapshot.Seq {
+		return nil
+	}
+	s.snapshots[persistenceID] = snapshot
+	return nil
+}
+
+func (s *MemorySnapshotStore) LoadSnapshot(persistenceID string) (Snapshot, bool, error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	snapshot, ok := s.snapshots[persistenceID]
+	return snapshot, ok, nil
+}
@@ -0,0 +1,260 @@
+package persistence
+
+import (
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/hedisam/goactor"
+	"github.com/hedisam/goactor/codec"
+	"github.com/hedisam/goactor/internal/wal"
+	p "github.com/hedisam/goactor/pid"
+	"github.com/hedisam/goactor/process"
+	"github.com/hedisam/goactor/supervisor"
+	"github.com/hedisam/goactor/supervisor/option"
+	"github.com/hedisam/goactor/supervisor/spec"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type added struct {
+	N int
+}
+
+type add struct {
+	n         int
+	requester *p.PID
+}
+
+type get struct {
+	requester *p.PID
+}
+
+type crash struct{}
+
+func init() {
+	gob.Register(added{})
+}
+
+func counter(id string, journal Journal, snapshots SnapshotStore) Entity {
+	return Entity{
+		ID:            id,
+		Journal:       journal,
+		Snapshots:     snapshots,
+		SnapshotEvery: 3,
+		Init: func() interface{} {
+			return 0
+		},
+		Apply: func(state interface{}, event interface{}) interface{} {
+			return state.(int) + event.(added).N
+		},
+		Handle: func(ctx *Context, message interface{}) (loop bool) {
+			switch msg := message.(type) {
+			case add:
+				err := ctx.Persist(added{N: msg.n})
+				if err != nil {
+					panic(err)
+				}
+				_ = goactor.Send(msg.requester, ctx.State())
+			case get:
+				_ = goactor.Send(msg.requester, ctx.State())
+			case crash:
+				panic("crash")
+			}
+			return true
+		},
+	}
+}
+
+func request(t *testing.T, pid *p.PID, build func(requester *p.PID) interface{}) interface{} {
+	future := goactor.NewFutureActor()
+	if !assert.Nil(t, goactor.Send(pid, build(future.Self()))) {return nil}
+	var resp interface{}
+	err := future.ReceiveWithTimeout(time.Second, func(message interface{}) (loop bool) {
+		resp = message
+		return false
+	})
+	assert.Nil(t, err)
+	return resp
+}
+
+func testEntity(t *testing.T, journal Journal, snapshots SnapshotStore) {
+	id := "counter " + uuid.New().String()
+	pid, err := Spawn(counter(id, journal, snapshots), nil)
+	if !assert.Nil(t, err) {return}
+	for i := 1; i <= 5; i++ {
+		assert.Equal(t, i*(i+1)/2, request(t, pid, func(requester *p.PID) interface{} {
+			return add{n: i, requester: requester}
+		}))
+	}
+	if snapshots != nil {
+		snapshot, ok, err := snapshots.LoadSnapshot(id)
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, Snapshot{Seq: 3, State: 6}, snapshot)
+	}
+	assert.Nil(t, goactor.Send(pid, crash{}))
+
+	// a new incarnation recovers from the snapshot and the events after it
+	pid, err = Spawn(counter(id, journal, snapshots), nil)
+	if !assert.Nil(t, err) {return}
+	assert.Equal(t, 15, request(t, pid, func(requester *p.PID) interface{} {
+		return get{requester: requester}
+	}))
+	assert.Equal(t, 21, request(t, pid, func(requester *p.PID) interface{} {
+		return add{n: 6, requester: requester}
+	}))
+}
+
+func TestEntity_Memory(t *testing.T) {
+	testEntity(t, NewMemoryJournal(), nil)
+	testEntity(t, NewMemoryJournal(), NewMemorySnapshotStore())
+}
+
+func TestEntity_File(t *testing.T) {
+	dir, err := ioutil.TempDir("", "persistence")
+	if !assert.Nil(t, err) {return}
+	defer os.RemoveAll(dir)
+
+	journal, err := NewFileJournal(filepath.Join(dir, "journal"), codec.Gob())
+	if !assert.Nil(t, err) {return}
+	snapshots, err := NewFileSnapshotStore(filepath.Join(dir, "snapshots"), codec.Gob())
+	if !assert.Nil(t, err) {return}
+	testEntity(t, journal, snapshots)
+}
+
+func TestEntity_Invalid(t *testing.T) {
+	_, err := Spawn(Entity{Journal: NewMemoryJournal()}, nil)
+	assert.True(t, errors.Is(err, ErrInvalidEntity))
+	_, err = Spawn(Entity{ID: "no journal"}, nil)
+	assert.True(t, errors.Is(err, ErrInvalidEntity))
+}
+
+func TestEntity_SupervisorRestart(t *testing.T) {
+	name := "persistent counter " + uuid.New().String()
+	journal := NewMemoryJournal()
+	_, err := supervisor.Start(option.OneForOneStrategyOption(),
+		ChildSpec(name, spec.RestartAlways, counter(name, journal, NewMemorySnapshotStore())))
+	if !assert.Nil(t, err) {return}
+
+	pid, _ := process.WhereIs(name)
+	for i := 0; i < 4; i++ {
+		request(t, pid, func(requester *p.PID) interface{} {
+			return add{n: 10, requester: requester}
+		})
+	}
+	assert.Nil(t, goactor.Send(pid, crash{}))
+
+	var restarted *p.PID
+	assert.Eventually(t, func() bool {
+		var ok bool
+		restarted, ok = process.WhereIs(name)
+		return ok && !p.Equal(restarted, pid)
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, 40, request(t, restarted, func(requester *p.PID) interface{} {
+		return get{requester: requester}
+	}))
+}
+
+func TestMemoryJournal_SequenceConflict(t *testing.T) {
+	journal := NewMemoryJournal()
+	assert.Nil(t, journal.Append("id", 1, []interface{}{"a", "b"}))
+	assert.True(t, errors.Is(journal.Append("id", 2, []interface{}{"c"}), ErrSequenceConflict))
+	assert.Nil(t, journal.Append("id", 3, []interface{}{"c"}))
+
+	var replayed []Event
+	assert.Nil(t, journal.Replay("id", 2, func(event Event) {
+		replayed = append(replayed, event)
+	}))
+	assert.Equal(t, []Event{{Seq: 2, Payload: "b"}, {Seq: 3, Payload: "c"}}, replayed)
+}
+
+func TestFileJournal_TornWrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "persistence")
+	if !assert.Nil(t, err) {return}
+	defer os.RemoveAll(dir)
+
+	journal, err := NewFileJournal(dir, codec.Gob())
+	if !assert.Nil(t, err) {return}
+	if !assert.Nil(t, journal.Append("id", 1, []interface{}{added{N: 1}, added{N: 2}})) {return}
+	assert.True(t, errors.Is(journal.Append("id", 1, []interface{}{added{N: 3}}), ErrSequenceConflict))
+
+	// simulate a crash in the middle of writing a record
+	file, err := os.OpenFile(journal.path("id"), os.O_WRONLY|os.O_APPEND, 0644)
+	if !assert.Nil(t, err) {return}
+	_, err = file.Write(wal.AppendRecord(nil, 3, []byte("partial"))[:10])
+	assert.Nil(t, err)
+	assert.Nil(t, file.Close())
+
+	// a new journal, e.g. after the process is restarted, drops the partial record
+	journal, err = NewFileJournal(dir, codec.Gob())
+	if !assert.Nil(t, err) {return}
+	if !assert.Nil(t, journal.Append("id", 3, []interface{}{added{N: 3}})) {return}
+
+	var replayed []Event
+	assert.Nil(t, journal.Replay("id", 1, func(event Event) {
+		replayed = append(replayed, event)
+	}))
+	assert.Equal(t, []Event{{Seq: 1, Payload: added{N: 1}}, {Seq: 2, Payload: added{N: 2}}, {Seq: 3, Payload: added{N: 3}}}, replayed)
+}
+
+var errReadFailed = errors.New("read failed")
+
+// failingFile is a journal file whose reads fail once its reader does.
+type failingFile struct {
+	io.Reader
+	io.Closer
+}
+
+func TestFileJournal_ReadError(t *testing.T) {
+	dir, err := ioutil.TempDir("", "persistence")
+	if !assert.Nil(t, err) {return}
+	defer os.RemoveAll(dir)
+
+	journal, err := NewFileJournal(dir, codec.Gob())
+	if !assert.Nil(t, err) {return}
+	if !assert.Nil(t, journal.Append("id", 1, []interface{}{added{N: 1}, added{N: 2}})) {return}
+	info, err := os.Stat(journal.path("id"))
+	if !assert.Nil(t, err) {return}
+
+	// the file can't be read past its first record
+	defer func(open func(path string) (io.ReadCloser, error)) {
+		openJournal = open
+	}(openJournal)
+	openJournal = func(path string) (io.ReadCloser, error) {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		header := make([]byte, wal.HeaderSize)
+		_, err = io.ReadFull(file, header)
+		if err != nil {
+			return nil, err
+		}
+		_, _ = file.Seek(0, io.SeekStart)
+		first := int64(wal.HeaderSize) + int64(binary.BigEndian.Uint32(header[0:4]))
+		return failingFile{Reader: io.MultiReader(io.LimitReader(file, first), &failingReader{}), Closer: file}, nil
+	}
+
+	// a new journal fails to recover the file, rather than truncating the records it couldn't read
+	journal, err = NewFileJournal(dir, codec.Gob())
+	if !assert.Nil(t, err) {return}
+	err = journal.Append("id", 3, []interface{}{added{N: 3}})
+	assert.True(t, errors.Is(err, errReadFailed), err)
+	assert.True(t, errors.Is(journal.Replay("id", 1, func(event Event) {}), errReadFailed))
+
+	recovered, err := os.Stat(journal.path("id"))
+	if !assert.Nil(t, err) {return}
+	assert.Equal(t, info.Size(), recovered.Size())
+}
+
+type failingReader struct{}
+
+func (r *failingReader) Read(_ []byte) (int, error) {
+	return 0, errReadFailed
+}
@@ -0,0 +1,260 @@
+// Package pg provides process groups: named groups of actors which can be broadcast to, and whose membership changes
+// can be subscribed to. The members and the subscribers are monitored, and removed from their groups once they exit.
+package pg
+
+import (
+	"fmt"
+	"github.com/hedisam/goactor"
+	"github.com/hedisam/goactor/internal/watch"
+	p "github.com/hedisam/goactor/pid"
+	"github.com/hedisam/goactor/reason"
+	"github.com/hedisam/goactor/sysmsg"
+	"sync"
+)
+
+var ErrNilPID = fmt.Errorf("pg: pid is nil")
+var ErrNotMember = fmt.Errorf("pg: pid is not a member of the group")
+var ErrNotSubscribed = fmt.Errorf("pg: pid is not subscribed to the group")
+
+// Joined is sent to the subscribers of a group when a new member joins the group.
+type Joined struct {
+	Group string
+	PID   *p.PID
+}
+
+// Left is sent to the subscribers of a group when a member leaves the group. Reason is nil if the member left by
+// calling Leave, otherwise it's the reason the member exited with.
+type Left struct {
+	Group  string
+	PID    *p.PID
+	Reason reason.Reason
+}
+
+// watched is what a monitor reference stands for, a member or a subscriber of a group.
+type watched struct {
+	group      string
+	pid        *p.PID
+	subscriber bool
+}
+
+type groups struct {
+	// members and subscribers map a group's name to its pids, indexed by their ids.
+	members     map[string]map[string]sysmsg.MonitorRef
+	subscribers map[string]map[string]sysmsg.MonitorRef
+	refs        map[sysmsg.MonitorRef]watched
+	// watcher is started by the first Join or Subscribe, so importing the package doesn't start an actor.
+	watcher *watch.Watcher
+	sync.RWMutex
+}
+
+var pg *groups
+
+func newGroups() *groups {
+	g := &groups{
+		members:     make(map[string]map[string]sysmsg.MonitorRef),
+		subscribers: make(map[string]map[string]sysmsg.MonitorRef),
+		refs:        make(map[sysmsg.MonitorRef]watched),
+	}
+	return g
+}
+
+func init() {
+	pg = newGroups()
+}
+
+// Join adds the given actor to the group. The actor gets removed from the group automatically once it exits.
+// Joining a group more than once is a no-op.
+func Join(group string, pid *p.PID) error {
+	if pid == nil {
+		return ErrNilPID
+	}
+	pg.Lock()
+	if _, ok := pg.members[group][pid.ID()]; ok {
+		pg.Unlock()
+		return nil
+	}
+	err := pg.watch(pg.members, watched{group: group, pid: pid})
+	if err != nil {
+		pg.Unlock()
+		return fmt.Errorf("pg: join failed: %w", err)
+	}
+	subscribers := pg.subscribersOf(group)
+	pg.Unlock()
+
+	notify(subscribers, Joined{Group: group, PID: pid})
+	return nil
+}
+
+// Leave removes the given actor from the group.
+func Leave(group string, pid *p.PID) error {
+	if pid == nil {
+		return ErrNilPID
+	}
+	pg.Lock()
+	ref, ok := pg.members[group][pid.ID()]
+	if !ok {
+		pg.Unlock()
+		return ErrNotMember
+	}
+	pg.unwatch(pg.members, ref)
+	subscribers := pg.subscribersOf(group)
+	pg.Unlock()
+
+	notify(subscribers, Left{Group: group, PID: pid})
+	return nil
+}
+
+// Members returns the members of the group in no particular order.
+func Members(group string) []*p.PID {
+	pg.RLock()
+	defer pg.RUnlock()
+
+	return pg.pidsOf(pg.members, group)
+}
+
+// LocalMembers returns the members of the group which are running on this node. Since actors are not distributed,
+// all members are local ones and LocalMembers is the same as Members.
+func LocalMembers(group string) []*p.PID {
+	return Members(group)
+}
+
+// Groups returns the name of the groups with at least one member.
+func Groups() []string {
+	pg.RLock()
+	defer pg.RUnlock()
+
+	names := make([]string, 0, len(pg.members))
+	for name := range pg.members {
+		names = append(names, name)
+	}
+	return names
+}
+
+// Broadcast sends the message to every member of the group. It tries all of the members even if sending to some of
+// them fails.
+func Broadcast(group string, msg interface{}) error {
+	var failed int
+	var firstErr error
+	for _, pid := range Members(group) {
+		err := goactor.Send(pid, msg)
+		if err != nil {
+			failed++
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	if firstErr != nil {
+		return fmt.Errorf("pg: broadcast failed for %d member(s): %w", failed, firstErr)
+	}
+	return nil
+}
+
+// Subscribe makes the given actor receive a Joined or a Left message whenever the group's membership changes.
+// The subscription gets removed automatically once the subscriber exits. Subscribing more than once is a no-op.
+func Subscribe(group string, pid *p.PID) error {
+	if pid == nil {
+		return ErrNilPID
+	}
+	pg.Lock()
+	defer pg.Unlock()
+
+	if _, ok := pg.subscribers[group][pid.ID()]; ok {
+		return nil
+	}
+	err := pg.watch(pg.subscribers, watched{group: group, pid: pid, subscriber: true})
+	if err != nil {
+		return fmt.Errorf("pg: subscribe failed: %w", err)
+	}
+	return nil
+}
+
+// Unsubscribe stops the given actor from receiving the group's membership changes.
+func Unsubscribe(group string, pid *p.PID) error {
+	if pid == nil {
+		return ErrNilPID
+	}
+	pg.Lock()
+	defer pg.Unlock()
+
+	ref, ok := pg.subscribers[group][pid.ID()]
+	if !ok {
+		return ErrNotSubscribed
+	}
+	pg.unwatch(pg.subscribers, ref)
+	return nil
+}
+
+// onDown is called by the watcher when a member or a subscriber exits.
+func (g *groups) onDown(down sysmsg.Down) {
+	g.Lock()
+	w, ok := g.refs[down.Ref]
+	if !ok {
+		g.Unlock()
+		return
+	}
+	if w.subscriber {
+		g.remove(g.subscribers, down.Ref)
+		g.Unlock()
+		return
+	}
+	g.remove(g.members, down.Ref)
+	subscribers := g.subscribersOf(w.group)
+	g.Unlock()
+
+	notify(subscribers, Left{Group: w.group, PID: w.pid, Reason: down.Reason})
+}
+
+// watch monitors the pid and adds it to the index. must be called with the write lock held.
+func (g *groups) watch(index map[string]map[string]sysmsg.MonitorRef, w watched) error {
+	if g.watcher == nil {
+		g.watcher = watch.New(g.onDown)
+	}
+	ref, err := g.watcher.Watch(w.pid)
+	if err != nil {
+		return err
+	}
+	pids, ok := index[w.group]
+	if !ok {
+		pids = make(map[string]sysmsg.MonitorRef)
+		index[w.group] = pids
+	}
+	pids[w.pid.ID()] = ref
+	g.refs[ref] = w
+	return nil
+}
+
+// unwatch stops monitoring the pid identified by ref and removes it from the index. must be called with the
+// write lock held.
+func (g *groups) unwatch(index map[string]map[string]sysmsg.MonitorRef, ref sysmsg.MonitorRef) {
+	_ = g.watcher.Unwatch(ref)
+	g.remove(index, ref)
+}
+
+func (g *groups) remove(index map[string]map[string]sysmsg.MonitorRef, ref sysmsg.MonitorRef) {
+	w := g.refs[ref]
+	delete(g.refs, ref)
+	delete(index[w.group], w.pid.ID())
+	if len(index[w.group]) == 0 {
+		delete(index, w.group)
+	}
+}
+
+func (g *groups) subscribersOf(group string) []*p.PID {
+	return g.pidsOf(g.subscribers, group)
+}
+
+func (g *groups) pidsOf(index map[string]map[string]sysmsg.MonitorRef, group string) []*p.PID {
+	pids := make([]*p.PID, 0, len(index[group]))
+	for _, ref := range index[group] {
+		pids = append(pids, g.refs[ref].pid)
+	}
+	return pids
+}
+
+func notify(subscribers []*p.PID, msg interface{}) {
+	for _, pid := range subscribers {
+		// a subscriber which has exited gets removed by its Down message.
+		_ = goactor.Send(pid, msg)
+	}
+}
@@ -0,0 +1,153 @@
+package pg
+
+import (
+	"github.com/hedisam/goactor"
+	p "github.com/hedisam/goactor/pid"
+	"github.com/hedisam/goactor/reason"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func contains(pids []*p.PID, pid *p.PID) bool {
+	for _, member := range pids {
+		if p.Equal(member, pid) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestJoinLeave(t *testing.T) {
+	group := "join-leave"
+	actor1, dispose1 := goactor.NewParentActor(nil)
+	defer dispose1()
+	actor2, dispose2 := goactor.NewParentActor(nil)
+	defer dispose2()
+
+	assert.Equal(t, ErrNilPID, Join(group, nil))
+
+	if !assert.Nil(t, Join(group, actor1.Self())) {return}
+	if !assert.Nil(t, Join(group, actor2.Self())) {return}
+	// joining twice is a no-op
+	if !assert.Nil(t, Join(group, actor1.Self())) {return}
+
+	members := Members(group)
+	assert.Len(t, members, 2)
+	assert.True(t, contains(members, actor1.Self()))
+	assert.True(t, contains(members, actor2.Self()))
+	assert.ElementsMatch(t, members, LocalMembers(group))
+	assert.Contains(t, Groups(), group)
+
+	if !assert.Nil(t, Leave(group, actor1.Self())) {return}
+	assert.Equal(t, ErrNotMember, Leave(group, actor1.Self()))
+	members = Members(group)
+	assert.Len(t, members, 1)
+	assert.True(t, contains(members, actor2.Self()))
+
+	if !assert.Nil(t, Leave(group, actor2.Self())) {return}
+	assert.Empty(t, Members(group))
+	assert.NotContains(t, Groups(), group)
+}
+
+func TestBroadcast(t *testing.T) {
+	group := "broadcast"
+	actor1, dispose1 := goactor.NewParentActor(nil)
+	defer dispose1()
+	actor2, dispose2 := goactor.NewParentActor(nil)
+	defer dispose2()
+
+	if !assert.Nil(t, Join(group, actor1.Self())) {return}
+	if !assert.Nil(t, Join(group, actor2.Self())) {return}
+	if !assert.Nil(t, Broadcast(group, "hello")) {return}
+
+	for _, actor := range []*goactor.Actor{actor1, actor2} {
+		err := actor.ReceiveWithTimeout(100*time.Millisecond, func(message interface{}) (loop bool) {
+			assert.Equal(t, "hello", message)
+			return false
+		})
+		assert.Nil(t, err)
+	}
+
+	// broadcasting to an empty group is a no-op
+	assert.Nil(t, Broadcast("no members", "hello"))
+}
+
+func TestAutoRemoval(t *testing.T) {
+	group := "auto-removal"
+	subscriber, disposeSubscriber := goactor.NewParentActor(nil)
+	defer disposeSubscriber()
+	if !assert.Nil(t, Subscribe(group, subscriber.Self())) {return}
+
+	member, disposeMember := goactor.NewParentActor(nil)
+	if !assert.Nil(t, Join(group, member.Self())) {return}
+
+	err := subscriber.ReceiveWithTimeout(100*time.Millisecond, func(message interface{}) (loop bool) {
+		assert.Equal(t, Joined{Group: group, PID: member.Self()}, message)
+		return false
+	})
+	if !assert.Nil(t, err) {return}
+
+	disposeMember()
+
+	err = subscriber.ReceiveWithTimeout(100*time.Millisecond, func(message interface{}) (loop bool) {
+		left, ok := message.(Left)
+		if !assert.True(t, ok) {return false}
+		assert.Equal(t, group, left.Group)
+		assert.True(t, p.Equal(member.Self(), left.PID))
+		assert.Equal(t, reason.Normal, left.Reason)
+		return false
+	})
+	if !assert.Nil(t, err) {return}
+	assert.Empty(t, Members(group))
+}
+
+func TestSubscribe(t *testing.T) {
+	group := "subscribe"
+	subscriber, dispose := goactor.NewParentActor(nil)
+	defer dispose()
+	member, disposeMember := goactor.NewParentActor(nil)
+	defer disposeMember()
+
+	assert.Equal(t, ErrNilPID, Subscribe(group, nil))
+	if !assert.Nil(t, Subscribe(group, subscriber.Self())) {return}
+
+	if !assert.Nil(t, Join(group, member.Self())) {return}
+	if !assert.Nil(t, Leave(group, member.Self())) {return}
+
+	var received []interface{}
+	err := subscriber.ReceiveWithTimeout(100*time.Millisecond, func(message interface{}) (loop bool) {
+		received = append(received, message)
+		return len(received) < 2
+	})
+	if !assert.Nil(t, err) {return}
+	assert.Equal(t, []interface{}{
+		Joined{Group: group, PID: member.Self()},
+		Left{Group: group, PID: member.Self()},
+	}, received)
+
+	if !assert.Nil(t, Unsubscribe(group, subscriber.Self())) {return}
+	assert.Equal(t, ErrNotSubscribed, Unsubscribe(group, subscriber.Self()))
+
+	if !assert.Nil(t, Join(group, member.Self())) {return}
+	err = subscriber.ReceiveWithTimeout(20*time.Millisecond, func(message interface{}) (loop bool) {
+		t.Errorf("unexpected message after unsubscribing: %v", message)
+		return false
+	})
+	assert.NotNil(t, err)
+	_ = Leave(group, member.Self())
+}
+
+func TestGroups_LazyWatcher(t *testing.T) {
+	g := newGroups()
+	// nothing is watched yet, so no actor is started
+	assert.Nil(t, g.watcher)
+
+	member, dispose := goactor.NewParentActor(nil)
+	defer dispose()
+	g.Lock()
+	err := g.watch(g.members, watched{group: "lazy", pid: member.Self()})
+	g.Unlock()
+	if !assert.Nil(t, err) {return}
+	assert.NotNil(t, g.watcher)
+}
@@ -2,25 +2,30 @@ package pid
 
 import "github.com/hedisam/goactor/internal/intlpid"
 
-type PID struct {
-	iPID intlpid.InternalPID
-}
-
-func ToPID(iPID intlpid.InternalPID) *PID {
-	if iPID == nil {
-		return nil
-	}
-	return &PID{iPID: iPID}
-}
+// PID is a process identifier which is used to send messages to an actor, or to link to and monitor it.
+type PID intlpid.PID
 
+// ID returns the unique id of the process.
 func (pid *PID) ID() string {
-	return pid.iPID.ID()
+	return intlpid.Internal((*intlpid.PID)(pid)).ID()
 }
 
+// IsSupervisor returns true if the process is a supervisor.
 func (pid *PID) IsSupervisor() bool {
-	return pid.iPID.IsSupervisor()
+	return intlpid.Internal((*intlpid.PID)(pid)).IsSupervisor()
 }
 
-func (pid *PID) InternalPID() intlpid.InternalPID {
-	return pid.iPID
+func (pid *PID) String() string {
+	if pid == nil {
+		return "<nil>"
+	}
+	return pid.ID()
+}
+
+// Equal returns true if both pids identify the same process. Two nil pids are considered equal.
+func Equal(a, b *PID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.ID() == b.ID()
 }
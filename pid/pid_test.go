@@ -6,23 +6,49 @@ import (
 	"testing"
 )
 
+// toPID and internal convert the same way the pidconv package does, which can't be imported here.
+func toPID(iPID intlpid.InternalPID) *PID {
+	return (*PID)(intlpid.ToPID(iPID))
+}
+
+func internal(pid *PID) intlpid.InternalPID {
+	return intlpid.Internal((*intlpid.PID)(pid))
+}
+
 func TestToPID(t *testing.T) {
 	internalPID := intlpid.NewMockInternalPID()
 
-	pid := ToPID(internalPID)
+	pid := toPID(internalPID)
 	if !assert.NotNil(t, pid) {return}
-	if !assert.Equal(t, internalPID, pid.InternalPID()) {return}
+	if !assert.Equal(t, internalPID, internal(pid)) {return}
 	assert.Equal(t, internalPID.ID(), pid.ID())
+	assert.Equal(t, internalPID.ID(), pid.String())
 	assert.Equal(t, internalPID.IsSupervisor(), pid.IsSupervisor())
 
-	pid2 := ToPID(internalPID)
+	pid2 := toPID(internalPID)
 	if !assert.NotNil(t, pid2) {return}
 	assert.Equal(t, pid, pid2)
 
 	internalPID2 := intlpid.NewMockInternalPID()
-	pid3 := ToPID(internalPID2)
+	pid3 := toPID(internalPID2)
 	assert.NotEqual(t, pid3, pid)
 
-	nilPID := ToPID(nil)
+	nilPID := toPID(nil)
 	assert.Nil(t, nilPID)
-}
\ No newline at end of file
+	assert.Nil(t, internal(nilPID))
+	assert.Equal(t, "<nil>", nilPID.String())
+}
+
+func TestEqual(t *testing.T) {
+	internalPID := intlpid.NewMockInternalPID()
+	pid := toPID(internalPID)
+	samePID := toPID(internalPID)
+	otherPID := toPID(intlpid.NewMockInternalPID())
+
+	assert.True(t, Equal(pid, samePID))
+	assert.True(t, Equal(pid, pid))
+	assert.False(t, Equal(pid, otherPID))
+	assert.False(t, Equal(pid, nil))
+	assert.False(t, Equal(nil, pid))
+	assert.True(t, Equal(nil, nil))
+}
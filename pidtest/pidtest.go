@@ -0,0 +1,232 @@
+// Package pidtest provides a fake pid for unit testing actors. It isn't backed by an actor: it captures the messages
+// sent to it, it can be linked to and monitored by actors, and it can exit with any reason on demand, which notifies
+// them just like a real actor would. It can be registered in the process registry, so goactor.SendNamed reaches it.
+package pidtest
+
+import (
+	"errors"
+	"fmt"
+	"github.com/hedisam/goactor/internal/intlpid"
+	"github.com/hedisam/goactor/internal/pidconv"
+	"github.com/hedisam/goactor/internal/relations"
+	"github.com/hedisam/goactor/mailbox"
+	p "github.com/hedisam/goactor/pid"
+	"github.com/hedisam/goactor/reason"
+	"github.com/hedisam/goactor/sysmsg"
+	"sync"
+	"time"
+)
+
+var ErrExited = fmt.Errorf("pidtest: the pid has already exited")
+
+// PID is a fake pid which captures the messages sent to it.
+type PID struct {
+	self      *p.PID
+	mailbox   *captureMailbox
+	relations *relations.Relations
+}
+
+// New returns a new fake pid.
+func New() *PID {
+	m := &captureMailbox{changed: make(chan struct{})}
+	relationManager := relations.NewRelation()
+	// like an actor, the pid stops without notifying anyone when it's shut down, e.g. by a supervisor.
+	shutdown := func() {
+		m.dispose()
+		relationManager.Dispose()
+	}
+	localPID := intlpid.NewLocalPID(m, relationManager, false, shutdown)
+	return &PID{
+		self:      pidconv.ToPID(localPID),
+		mailbox:   m,
+		relations: relationManager,
+	}
+}
+
+// PID returns the pid to be given to the actors under test.
+func (pid *PID) PID() *p.PID {
+	return pid.self
+}
+
+// Messages returns the user messages sent to the pid so far, in the order they were sent.
+func (pid *PID) Messages() []interface{} {
+	return pid.mailbox.snapshot(false)
+}
+
+// SystemMessages returns the system messages sent to the pid so far, e.g. the exit messages of the linked actors and
+// the down messages of the monitored ones.
+func (pid *PID) SystemMessages() []interface{} {
+	return pid.mailbox.snapshot(true)
+}
+
+// WaitMessages waits until at least n user messages have been sent to the pid, and returns them. It returns false if
+// they're not sent in time.
+func (pid *PID) WaitMessages(n int, timeout time.Duration) ([]interface{}, bool) {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	for {
+		messages, changed := pid.mailbox.wait()
+		if len(messages) >= n {
+			return messages, true
+		}
+		select {
+		case <-changed:
+		case <-deadline.C:
+			return messages, false
+		}
+	}
+}
+
+// Alive returns false once the pid has exited.
+func (pid *PID) Alive() bool {
+	return !pid.mailbox.Disposed()
+}
+
+// Link links the pid to the actor, so the actor gets its exit and the pid captures the actor's exit message.
+// It implements goactor.Linker, so the pid can be the parent given to goactor.SpawnLink.
+func (pid *PID) Link(other *p.PID) error {
+	err := intlpid.Link(pidconv.Internal(other), pidconv.Internal(pid.self))
+	if err != nil {
+		return fmt.Errorf("pidtest: failed to link: %w", err)
+	}
+	err = pid.relations.AddLink(pidconv.Internal(other))
+	if err != nil {
+		return fmt.Errorf("pidtest: failed to link: %w", err)
+	}
+	return nil
+}
+
+// Monitor makes the pid monitor the actor, so the pid captures the actor's Down message. If the actor has already
+// exited, the Down message is captured right away with the reason.NoProc reason.
+func (pid *PID) Monitor(other *p.PID) (sysmsg.MonitorRef, error) {
+	ref := sysmsg.NewMonitorRef()
+	err := intlpid.AddMonitor(pidconv.Internal(other), pidconv.Internal(pid.self), string(ref))
+	if errors.Is(err, relations.ErrDisposed) {
+		err = pid.mailbox.PushSystemMessage(sysmsg.Down{Ref: ref, PID: other, Reason: reason.NoProc})
+	}
+	if err != nil {
+		return "", fmt.Errorf("pidtest: failed to monitor: %w", err)
+	}
+	return ref, nil
+}
+
+// Linked returns the actors linked to the pid.
+func (pid *PID) Linked() []*p.PID {
+	var linked []*p.PID
+	iterator := pid.relations.LinkedActors()
+	for iterator.HasNext() {
+		linked = append(linked, pidconv.ToPID(iterator.Value()))
+	}
+	return linked
+}
+
+// Monitors returns the actors monitoring the pid.
+func (pid *PID) Monitors() []*p.PID {
+	var monitors []*p.PID
+	iterator := pid.relations.MonitorActors()
+	for iterator.HasNext() {
+		monitors = append(monitors, pidconv.ToPID(iterator.Value().PID))
+	}
+	return monitors
+}
+
+// Exit simulates the exit of an actor with the given reason: the linked actors get a NormalExit message if the reason
+// is reason.Normal and an AbnormalExit message otherwise, and the monitors get a Down message. No more messages can be
+// sent to the pid afterwards.
+func (pid *PID) Exit(exitReason reason.Reason) error {
+	if !pid.mailbox.dispose() {
+		return ErrExited
+	}
+	pid.relations.Dispose()
+
+	var msg sysmsg.SystemMessage
+	if exitReason == reason.Normal {
+		msg = sysmsg.NewNormalExitMsg(pid.self, nil)
+	} else {
+		msg = sysmsg.NewAbnormalExitMsg(pid.self, exitReason, nil)
+	}
+	linked := pid.relations.LinkedActors()
+	for linked.HasNext() {
+		_ = intlpid.SendSystemMessage(linked.Value(), msg)
+	}
+	monitors := pid.relations.MonitorActors()
+	for monitors.HasNext() {
+		monitor := monitors.Value()
+		down := sysmsg.Down{Ref: sysmsg.MonitorRef(monitor.Ref), PID: pid.self, Reason: exitReason}
+		_ = intlpid.SendSystemMessage(monitor.PID, down)
+	}
+	return nil
+}
+
+// captureMailbox keeps all the messages pushed into it.
+type captureMailbox struct {
+	mu          sync.Mutex
+	messages    []interface{}
+	sysMessages []interface{}
+	disposed    bool
+	// changed is closed, and replaced, each time a user message is pushed.
+	changed chan struct{}
+}
+
+func (m *captureMailbox) PushMessage(msg interface{}) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.disposed {
+		return mailbox.ErrMailboxClosed
+	}
+	m.messages = append(m.messages, msg)
+	close(m.changed)
+	m.changed = make(chan struct{})
+	return nil
+}
+
+func (m *captureMailbox) PushSystemMessage(msg interface{}) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.disposed {
+		return mailbox.ErrMailboxClosed
+	}
+	m.sysMessages = append(m.sysMessages, msg)
+	return nil
+}
+
+// Len returns the number of user messages captured so far.
+func (m *captureMailbox) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.messages)
+}
+
+// Disposed returns true once the pid has exited.
+func (m *captureMailbox) Disposed() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.disposed
+}
+
+// dispose returns false if the mailbox has already been disposed.
+func (m *captureMailbox) dispose() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.disposed {
+		return false
+	}
+	m.disposed = true
+	return true
+}
+
+func (m *captureMailbox) snapshot(system bool) []interface{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if system {
+		return append([]interface{}(nil), m.sysMessages...)
+	}
+	return append([]interface{}(nil), m.messages...)
+}
+
+// wait returns the user messages captured so far, and a channel which is closed once another one is pushed.
+func (m *captureMailbox) wait() ([]interface{}, <-chan struct{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]interface{}(nil), m.messages...), m.changed
+}
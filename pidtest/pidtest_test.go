@@ -0,0 +1,101 @@
+package pidtest
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"github.com/hedisam/goactor"
+	"github.com/hedisam/goactor/mailbox"
+	p "github.com/hedisam/goactor/pid"
+	"github.com/hedisam/goactor/process"
+	"github.com/hedisam/goactor/reason"
+	"github.com/hedisam/goactor/sysmsg"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestPID_Messages(t *testing.T) {
+	pid := New()
+	goactor.Spawn(func(actor *goactor.Actor) {
+		_ = goactor.Send(pid.PID(), "hello")
+		_ = goactor.Send(pid.PID(), "world")
+	}, nil)
+
+	messages, ok := pid.WaitMessages(2, time.Second)
+	assert.True(t, ok)
+	assert.Equal(t, []interface{}{"hello", "world"}, messages)
+	assert.Equal(t, messages, pid.Messages())
+
+	_, ok = pid.WaitMessages(3, 10*time.Millisecond)
+	assert.False(t, ok)
+}
+
+func TestPID_SendNamed(t *testing.T) {
+	pid := New()
+	name := "pidtest " + uuid.New().String()
+	if !assert.Nil(t, process.Register(name, pid.PID())) {return}
+	defer process.Unregister(name)
+
+	assert.Nil(t, goactor.SendNamed(name, "named"))
+	assert.Equal(t, []interface{}{"named"}, pid.Messages())
+}
+
+func TestPID_Exit(t *testing.T) {
+	pid := New()
+	parent, dispose := goactor.NewParentActor(nil)
+	defer dispose()
+	parent.SetTrapExit(true)
+	if !assert.Nil(t, parent.Link(pid.PID())) {return}
+	ref, err := parent.Monitor(pid.PID())
+	if !assert.Nil(t, err) {return}
+	assert.Equal(t, []*p.PID{parent.Self()}, pid.Linked())
+	assert.Equal(t, []*p.PID{parent.Self()}, pid.Monitors())
+
+	assert.Nil(t, pid.Exit(reason.Killed))
+	assert.False(t, pid.Alive())
+	assert.Equal(t, ErrExited, pid.Exit(reason.Normal))
+
+	var received []interface{}
+	err = parent.ReceiveWithTimeout(time.Second, func(message interface{}) (loop bool) {
+		received = append(received, message)
+		return len(received) < 2
+	})
+	if !assert.Nil(t, err) {return}
+	for _, msg := range received {
+		switch msg := msg.(type) {
+		case sysmsg.AbnormalExit:
+			assert.True(t, p.Equal(pid.PID(), msg.Sender()))
+			assert.Equal(t, reason.Killed, msg.Reason())
+		case sysmsg.Down:
+			assert.Equal(t, sysmsg.Down{Ref: ref, PID: pid.PID(), Reason: reason.Killed}, msg)
+		default:
+			t.Errorf("unexpected message: %#v", msg)
+		}
+	}
+
+	// an exited pid can't receive messages anymore
+	err = goactor.Send(pid.PID(), "too late")
+	assert.True(t, errors.Is(err, mailbox.ErrMailboxClosed))
+}
+
+func TestPID_LinkAndMonitor(t *testing.T) {
+	pid := New()
+	child, err := goactor.SpawnLink(pid, func(actor *goactor.Actor) {
+		panic("crashed")
+	}, nil)
+	if !assert.Nil(t, err) {return}
+	_, err = pid.Monitor(child)
+	if !assert.Nil(t, err) {return}
+
+	assert.Eventually(t, func() bool {
+		return len(pid.SystemMessages()) > 0
+	}, time.Second, time.Millisecond)
+	exit, ok := pid.SystemMessages()[0].(sysmsg.AbnormalExit)
+	if !assert.True(t, ok) {return}
+	assert.True(t, p.Equal(child, exit.Sender()))
+
+	// monitoring an actor which has already exited captures a noproc down message
+	ref, err := pid.Monitor(child)
+	if !assert.Nil(t, err) {return}
+	assert.Contains(t, pid.SystemMessages(), sysmsg.Down{Ref: ref, PID: child, Reason: reason.NoProc})
+}
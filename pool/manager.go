@@ -0,0 +1,327 @@
+package pool
+
+import (
+	"fmt"
+	"github.com/hedisam/goactor"
+	"github.com/hedisam/goactor/internal/intlpid"
+	"github.com/hedisam/goactor/internal/pidconv"
+	p "github.com/hedisam/goactor/pid"
+	"github.com/hedisam/goactor/process"
+	"github.com/hedisam/goactor/reason"
+	"github.com/hedisam/goactor/supervisor/spec"
+	"github.com/hedisam/goactor/supervisor/supref"
+	"github.com/hedisam/goactor/sysmsg"
+	"log"
+	"time"
+)
+
+// supervisorTimeout is how long the manager waits for the workers' supervisor to answer a request.
+const supervisorTimeout = 5 * time.Second
+
+// checkout is a worker which is checked out by an owner. Both the owner and the worker are monitored, so the worker
+// is returned to the pool if the owner exits, and the checkout is dropped if the worker exits.
+type checkout struct {
+	name      string
+	worker    *p.PID
+	owner     *p.PID
+	ownerRef  sysmsg.MonitorRef
+	workerRef sysmsg.MonitorRef
+	// requestID is the id of the checkout request the worker was handed to.
+	requestID string
+}
+
+// manager is the pool's actor which keeps track of the workers. Each worker is a child of the workers' supervisor
+// which is registered in the process registry by its name, so a worker is found by its name even after a restart.
+type manager struct {
+	actor    *goactor.Actor
+	template spec.WorkerSpec
+	prefix   string
+	overflow int
+
+	sup    *supref.SupRef
+	supRef sysmsg.MonitorRef
+
+	available  []string
+	checkedOut map[string]*checkout
+	// refs maps the monitor references of the owners and the checked out workers to the workers' name
+	refs map[string]string
+	// overflowed keeps the name of the running overflow workers
+	overflowed map[string]bool
+	waiting    []checkoutRequest
+	nameIndex  int
+}
+
+func (m *manager) run(actor *goactor.Actor) {
+	m.actor = actor
+	m.overflowed = make(map[string]bool)
+	defer m.stopWorkers()
+
+	ref, err := actor.Monitor(m.sup.PID())
+	if err != nil {
+		log.Printf("[!] pool %s failed to monitor its workers' supervisor: %v\n", actor.Self().ID(), err)
+		return
+	}
+	m.supRef = ref
+
+	_ = actor.Receive(m.handle)
+}
+
+func (m *manager) handle(message interface{}) (loop bool) {
+	switch msg := message.(type) {
+	case checkoutRequest:
+		m.checkout(msg)
+	case cancelCheckout:
+		m.cancel(msg.id)
+	case checkinRequest:
+		for name, c := range m.checkedOut {
+			if p.Equal(c.worker, msg.worker) {
+				m.checkin(name)
+				break
+			}
+		}
+	case statusRequest:
+		status := Status{
+			Available:  m.idleCount(),
+			Overflow:   len(m.overflowed),
+			CheckedOut: len(m.checkedOut),
+			Waiting:    len(m.waiting),
+		}
+		m.reply(msg.requester, status)
+	case stopRequest:
+		return false
+	case sysmsg.Down:
+		if msg.Ref == m.supRef {
+			log.Printf("[!] pool %s: workers' supervisor has exited, reason: %v\n", m.actor.Self().ID(), msg.Reason)
+			m.sup = nil
+			return false
+		}
+		m.down(msg)
+	default:
+		log.Printf("[!] pool %s received an unknown message: %v\n", m.actor.Self().ID(), message)
+	}
+	return true
+}
+
+func (m *manager) checkout(req checkoutRequest) {
+	// backpressure: the caller waits behind the ones already waiting, until a worker gets checked in, or its checkout
+	// times out.
+	m.waiting = append(m.waiting, req)
+	m.serveWaiting()
+}
+
+// serveWaiting checks out workers for the waiting callers, in the order they've been waiting, until there's no
+// worker left to check out.
+func (m *manager) serveWaiting() {
+	for len(m.waiting) > 0 {
+		if !m.tryCheckout(m.waiting[0]) {
+			// the caller keeps its place at the head of the queue.
+			return
+		}
+		m.waiting = m.waiting[1:]
+	}
+}
+
+// tryCheckout checks out an idle worker, or a new overflow worker, for the request's owner. It returns false if
+// there's no worker to check out, in which case the request isn't answered.
+func (m *manager) tryCheckout(req checkoutRequest) bool {
+	name, ok := m.idleWorker()
+	if !ok {
+		if len(m.overflowed) >= m.overflow {
+			return false
+		}
+		name = m.nextName()
+		err := m.sup.StartNewChild(m.spec(name, spec.RestartNever), supervisorTimeout)
+		if err != nil {
+			m.reply(req.requester, checkoutResponse{err: fmt.Errorf("pool: failed to start an overflow worker: %w", err)})
+			return true
+		}
+		m.overflowed[name] = true
+	}
+	return m.handTo(name, req)
+}
+
+// handTo checks out the named worker for the request's owner. It returns false if the worker isn't running, in which
+// case the request isn't answered.
+func (m *manager) handTo(name string, req checkoutRequest) bool {
+	worker, ok := process.WhereIs(name)
+	if !ok {
+		// the worker is being restarted.
+		m.available = append(m.available, name)
+		return false
+	}
+	ownerRef, err := m.actor.Monitor(req.owner)
+	if err != nil {
+		m.available = append(m.available, name)
+		m.reply(req.requester, checkoutResponse{err: fmt.Errorf("pool: failed to monitor the owner: %w", err)})
+		return true
+	}
+	workerRef, err := m.actor.Monitor(worker)
+	if err != nil {
+		_ = m.actor.Demonitor(ownerRef)
+		m.available = append(m.available, name)
+		m.reply(req.requester, checkoutResponse{err: fmt.Errorf("pool: failed to monitor the worker: %w", err)})
+		return true
+	}
+	m.checkedOut[name] = &checkout{
+		name:      name,
+		worker:    worker,
+		owner:     req.owner,
+		ownerRef:  ownerRef,
+		workerRef: workerRef,
+		requestID: req.id,
+	}
+	m.refs[string(ownerRef)] = name
+	m.refs[string(workerRef)] = name
+	// if the requester has stopped waiting, it'll ask for a cancellation which checks the worker back in.
+	m.reply(req.requester, checkoutResponse{worker: worker})
+	return true
+}
+
+// idleWorker takes an available worker which is running.
+func (m *manager) idleWorker() (string, bool) {
+	for i, name := range m.available {
+		if _, ok := process.WhereIs(name); ok {
+			m.available = append(m.available[:i], m.available[i+1:]...)
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// idleCount returns the number of available workers which are running, the ones being restarted aren't counted.
+func (m *manager) idleCount() int {
+	var count int
+	for _, name := range m.available {
+		if _, ok := process.WhereIs(name); ok {
+			count++
+		}
+	}
+	return count
+}
+
+func (m *manager) cancel(id string) {
+	for i, req := range m.waiting {
+		if req.id == id {
+			m.waiting = append(m.waiting[:i], m.waiting[i+1:]...)
+			return
+		}
+	}
+	for name, c := range m.checkedOut {
+		if c.requestID == id {
+			m.checkin(name)
+			return
+		}
+	}
+}
+
+// checkin returns the named worker to the pool, or hands it to the next waiting caller.
+func (m *manager) checkin(name string) {
+	m.release(name)
+
+	if len(m.waiting) > 0 {
+		if m.handTo(name, m.waiting[0]) {
+			m.waiting = m.waiting[1:]
+		}
+		return
+	}
+	if m.overflowed[name] {
+		m.dismiss(name)
+		return
+	}
+	m.available = append(m.available, name)
+}
+
+// release drops the named worker's checkout along with its monitors.
+func (m *manager) release(name string) {
+	c, ok := m.checkedOut[name]
+	if !ok {
+		return
+	}
+	delete(m.checkedOut, name)
+	delete(m.refs, string(c.ownerRef))
+	delete(m.refs, string(c.workerRef))
+	_ = m.actor.Demonitor(c.ownerRef)
+	_ = m.actor.Demonitor(c.workerRef)
+}
+
+func (m *manager) down(msg sysmsg.Down) {
+	name, ok := m.refs[string(msg.Ref)]
+	if !ok {
+		return
+	}
+	c := m.checkedOut[name]
+	if msg.Ref == c.ownerRef {
+		// the owner has exited without checking the worker in.
+		m.checkin(name)
+		return
+	}
+
+	// the worker has exited while checked out.
+	m.release(name)
+	if m.overflowed[name] {
+		// overflow workers are not restarted, so we just need to forget about it.
+		delete(m.overflowed, name)
+		err := m.sup.DeleteChild(name, supervisorTimeout)
+		if err != nil {
+			log.Printf("[!] pool %s failed to delete overflow worker %s: %v\n", m.actor.Self().ID(), name, err)
+		}
+		// its place can be taken by a new overflow worker.
+		m.serveWaiting()
+		return
+	}
+	// the worker is being restarted by the supervisor, so it's available again.
+	m.available = append(m.available, name)
+	if len(m.waiting) > 0 {
+		// the supervisor handles the worker's exit before any request, so the worker has been restarted once the
+		// supervisor answers one.
+		_, err := m.sup.ChildrenCount(supervisorTimeout)
+		if err != nil {
+			log.Printf("[!] pool %s failed to wait for worker %s's restart: %v\n", m.actor.Self().ID(), name, err)
+		}
+		m.serveWaiting()
+	}
+}
+
+// dismiss stops an overflow worker.
+func (m *manager) dismiss(name string) {
+	delete(m.overflowed, name)
+	err := m.sup.TerminateChild(name, supervisorTimeout)
+	if err == nil {
+		err = m.sup.DeleteChild(name, supervisorTimeout)
+	}
+	if err != nil {
+		log.Printf("[!] pool %s failed to stop overflow worker %s: %v\n", m.actor.Self().ID(), name, err)
+	}
+}
+
+func (m *manager) spec(name string, restartWhen int) spec.WorkerSpec {
+	s := m.template
+	s.Id = name
+	s.WhenToRestart = restartWhen
+	return s
+}
+
+func (m *manager) nextName() string {
+	name := fmt.Sprintf("%s%d", m.prefix, m.nameIndex)
+	m.nameIndex++
+	return name
+}
+
+// stopWorkers shuts down the workers' supervisor.
+func (m *manager) stopWorkers() {
+	if m.sup == nil {
+		return
+	}
+	cmd := sysmsg.NewShutdownCMD(m.actor.Self(), reason.Shutdown, nil)
+	err := intlpid.SendSystemMessage(pidconv.Internal(m.sup.PID()), cmd)
+	if err != nil {
+		log.Printf("[!] pool %s failed to stop its workers: %v\n", m.actor.Self().ID(), err)
+	}
+}
+
+func (m *manager) reply(requester *p.PID, msg interface{}) {
+	err := goactor.Send(requester, msg)
+	if err != nil {
+		log.Printf("[!] pool %s couldn't send back a response: %v\n", m.actor.Self().ID(), err)
+	}
+}
@@ -0,0 +1,29 @@
+package pool
+
+import p "github.com/hedisam/goactor/pid"
+
+type checkoutRequest struct {
+	id        string
+	owner     *p.PID
+	requester *p.PID
+}
+
+type checkoutResponse struct {
+	worker *p.PID
+	err    error
+}
+
+// cancelCheckout is sent by a caller whose checkout has timed out, so the worker, if any, gets returned to the pool.
+type cancelCheckout struct {
+	id string
+}
+
+type checkinRequest struct {
+	worker *p.PID
+}
+
+type statusRequest struct {
+	requester *p.PID
+}
+
+type stopRequest struct{}
@@ -0,0 +1,160 @@
+package pool
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/hedisam/goactor"
+	"github.com/hedisam/goactor/mailbox"
+	p "github.com/hedisam/goactor/pid"
+	"github.com/hedisam/goactor/supervisor"
+	"github.com/hedisam/goactor/supervisor/option"
+	"github.com/hedisam/goactor/supervisor/spec"
+	"time"
+)
+
+var ErrInvalidSize = fmt.Errorf("pool: size must be greater than zero")
+var ErrInvalidOverflow = fmt.Errorf("pool: overflow can not be negative")
+var ErrNilOwner = fmt.Errorf("pool: owner pid is nil")
+var ErrCheckoutTimeout = fmt.Errorf("pool: checkout timeout")
+var ErrUnknownResponse = fmt.Errorf("pool: unknown response")
+
+// Pool is a reference to a pool of workers. A worker is checked out by an owner actor, and it's not handed to anyone
+// else until it's checked in, or the owner exits.
+type Pool struct {
+	pid *p.PID
+}
+
+// Status is a snapshot of a pool's workers.
+type Status struct {
+	// Available is the number of idle workers
+	Available int
+	// Overflow is the number of running workers that have been started beyond the pool's size
+	Overflow int
+	// CheckedOut is the number of workers that are checked out
+	CheckedOut int
+	// Waiting is the number of callers waiting for a worker
+	Waiting int
+}
+
+// Start starts a pool of size workers which are supervised and restarted whenever they exit. When all of them are
+// checked out, up to overflow extra workers get started on demand, which are stopped once they are checked in.
+// The given spec is used as a template for the workers, its name and restart values are ignored.
+func Start(template spec.WorkerSpec, size, overflow int) (*Pool, error) {
+	if size < 1 {
+		return nil, ErrInvalidSize
+	}
+	if overflow < 0 {
+		return nil, ErrInvalidOverflow
+	}
+
+	m := &manager{
+		template:   template,
+		prefix:     "pool-" + uuid.New().String() + "-worker-",
+		overflow:   overflow,
+		checkedOut: make(map[string]*checkout),
+		refs:       make(map[string]string),
+	}
+	specs := make([]spec.Spec, 0, size)
+	for i := 0; i < size; i++ {
+		name := m.nextName()
+		m.available = append(m.available, name)
+		specs = append(specs, m.spec(name, spec.RestartAlways))
+	}
+	sup, err := supervisor.Start(option.OneForOneStrategyOption(), specs...)
+	if err != nil {
+		return nil, fmt.Errorf("pool: failed to start the workers: %w", err)
+	}
+	m.sup = sup
+
+	return &Pool{pid: goactor.Spawn(m.run, nil)}, nil
+}
+
+// PID returns the pid of the pool's manager actor.
+func (pool *Pool) PID() *p.PID {
+	return pool.pid
+}
+
+// Checkout hands a worker to the owner actor, waiting up to timeout for one to become available. A zero timeout waits
+// forever. The worker gets checked in automatically if the owner exits before checking it in.
+func (pool *Pool) Checkout(owner *p.PID, timeout time.Duration) (*p.PID, error) {
+	if owner == nil {
+		return nil, ErrNilOwner
+	}
+	id := uuid.New().String()
+	future := goactor.NewFutureActor()
+	err := goactor.Send(pool.pid, checkoutRequest{id: id, owner: owner, requester: future.Self()})
+	if err != nil {
+		return nil, fmt.Errorf("pool: couldn't deliver checkout request: %w", err)
+	}
+
+	var resp *checkoutResponse
+	var respErr error
+	err = future.ReceiveWithTimeout(timeout, func(message interface{}) (loop bool) {
+		if r, ok := message.(checkoutResponse); ok {
+			resp = &r
+			return false
+		}
+		respErr = fmt.Errorf("%w: %v", ErrUnknownResponse, message)
+		return false
+	})
+	if errors.Is(err, mailbox.ErrMailboxReceiveTimeout) {
+		// the worker could've been handed to us right after we stopped waiting, so ask for it to be checked in.
+		_ = goactor.Send(pool.pid, cancelCheckout{id: id})
+		return nil, ErrCheckoutTimeout
+	} else if err != nil {
+		return nil, fmt.Errorf("pool: checkout failed: %w", err)
+	} else if respErr != nil {
+		return nil, respErr
+	}
+	if resp.err != nil {
+		return nil, resp.err
+	}
+	return resp.worker, nil
+}
+
+// Checkin returns a checked out worker to the pool.
+func (pool *Pool) Checkin(worker *p.PID) error {
+	return goactor.Send(pool.pid, checkinRequest{worker: worker})
+}
+
+// Transaction checks out a worker for the owner, passes it to fn and checks it back in once fn returns.
+func (pool *Pool) Transaction(owner *p.PID, timeout time.Duration, fn func(worker *p.PID)) error {
+	worker, err := pool.Checkout(owner, timeout)
+	if err != nil {
+		return err
+	}
+	defer pool.Checkin(worker)
+
+	fn(worker)
+	return nil
+}
+
+// Status returns a snapshot of the pool's workers.
+func (pool *Pool) Status(timeout time.Duration) (*Status, error) {
+	future := goactor.NewFutureActor()
+	err := goactor.Send(pool.pid, statusRequest{requester: future.Self()})
+	if err != nil {
+		return nil, fmt.Errorf("pool: couldn't deliver status request: %w", err)
+	}
+
+	var status *Status
+	var respErr error
+	err = future.ReceiveWithTimeout(timeout, func(message interface{}) (loop bool) {
+		if s, ok := message.(Status); ok {
+			status = &s
+			return false
+		}
+		respErr = fmt.Errorf("%w: %v", ErrUnknownResponse, message)
+		return false
+	})
+	if err != nil {
+		return nil, fmt.Errorf("pool: status request failed: %w", err)
+	}
+	return status, respErr
+}
+
+// Stop stops the pool along with its workers.
+func (pool *Pool) Stop() error {
+	return goactor.Send(pool.pid, stopRequest{})
+}
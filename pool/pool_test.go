@@ -0,0 +1,224 @@
+package pool
+
+import (
+	"github.com/hedisam/goactor"
+	p "github.com/hedisam/goactor/pid"
+	"github.com/hedisam/goactor/supervisor/spec"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func idleWorker(actor *goactor.Actor) {
+	_ = actor.Receive(func(message interface{}) (loop bool) {
+		if message == "crash" {
+			panic("crashing on purpose")
+		}
+		return true
+	})
+}
+
+func startPool(t *testing.T, size, overflow int) *Pool {
+	pool, err := Start(spec.NewWorkerSpec("", spec.RestartAlways, idleWorker), size, overflow)
+	if !assert.Nil(t, err) {
+		t.FailNow()
+	}
+	return pool
+}
+
+func assertStatus(t *testing.T, pool *Pool, expected Status) bool {
+	return assert.Eventually(t, func() bool {
+		status, err := pool.Status(time.Second)
+		return err == nil && *status == expected
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestStart(t *testing.T) {
+	template := spec.NewWorkerSpec("", spec.RestartAlways, idleWorker)
+	_, err := Start(template, 0, 0)
+	assert.Equal(t, ErrInvalidSize, err)
+	_, err = Start(template, 1, -1)
+	assert.Equal(t, ErrInvalidOverflow, err)
+
+	pool := startPool(t, 2, 0)
+	defer pool.Stop()
+	assertStatus(t, pool, Status{Available: 2})
+}
+
+func TestPool_CheckoutCheckin(t *testing.T) {
+	pool := startPool(t, 2, 0)
+	defer pool.Stop()
+	owner, dispose := goactor.NewParentActor(nil)
+	defer dispose()
+
+	_, err := pool.Checkout(nil, time.Second)
+	assert.Equal(t, ErrNilOwner, err)
+
+	worker1, err := pool.Checkout(owner.Self(), time.Second)
+	if !assert.Nil(t, err) {return}
+	worker2, err := pool.Checkout(owner.Self(), time.Second)
+	if !assert.Nil(t, err) {return}
+	assert.False(t, p.Equal(worker1, worker2))
+	assertStatus(t, pool, Status{CheckedOut: 2})
+
+	// no worker is left, so the caller has to wait
+	_, err = pool.Checkout(owner.Self(), 20*time.Millisecond)
+	assert.Equal(t, ErrCheckoutTimeout, err)
+	assertStatus(t, pool, Status{CheckedOut: 2})
+
+	if !assert.Nil(t, pool.Checkin(worker1)) {return}
+	assertStatus(t, pool, Status{Available: 1, CheckedOut: 1})
+
+	worker, err := pool.Checkout(owner.Self(), time.Second)
+	if !assert.Nil(t, err) {return}
+	assert.True(t, p.Equal(worker1, worker))
+}
+
+func TestPool_WaitingCaller(t *testing.T) {
+	pool := startPool(t, 1, 0)
+	defer pool.Stop()
+	owner, dispose := goactor.NewParentActor(nil)
+	defer dispose()
+
+	worker, err := pool.Checkout(owner.Self(), time.Second)
+	if !assert.Nil(t, err) {return}
+
+	checkedOut := make(chan *p.PID, 1)
+	go func() {
+		w, err := pool.Checkout(owner.Self(), time.Second)
+		assert.Nil(t, err)
+		checkedOut <- w
+	}()
+	assertStatus(t, pool, Status{CheckedOut: 1, Waiting: 1})
+
+	if !assert.Nil(t, pool.Checkin(worker)) {return}
+	select {
+	case w := <-checkedOut:
+		assert.True(t, p.Equal(worker, w))
+	case <-time.After(time.Second):
+		t.Error("the waiting caller didn't get the checked in worker")
+	}
+}
+
+func TestPool_Overflow(t *testing.T) {
+	pool := startPool(t, 1, 1)
+	defer pool.Stop()
+	owner, dispose := goactor.NewParentActor(nil)
+	defer dispose()
+
+	_, err := pool.Checkout(owner.Self(), time.Second)
+	if !assert.Nil(t, err) {return}
+	overflow, err := pool.Checkout(owner.Self(), time.Second)
+	if !assert.Nil(t, err) {return}
+	assertStatus(t, pool, Status{Overflow: 1, CheckedOut: 2})
+
+	_, err = pool.Checkout(owner.Self(), 20*time.Millisecond)
+	assert.Equal(t, ErrCheckoutTimeout, err)
+
+	// overflow workers are stopped once checked in
+	if !assert.Nil(t, pool.Checkin(overflow)) {return}
+	assertStatus(t, pool, Status{CheckedOut: 1})
+	assert.Eventually(t, func() bool {
+		return goactor.Send(overflow, "hello") != nil
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestPool_OwnerExits(t *testing.T) {
+	pool := startPool(t, 1, 0)
+	defer pool.Stop()
+	owner, dispose := goactor.NewParentActor(nil)
+
+	_, err := pool.Checkout(owner.Self(), time.Second)
+	if !assert.Nil(t, err) {return}
+	assertStatus(t, pool, Status{CheckedOut: 1})
+
+	// the worker is returned to the pool once its owner exits
+	dispose()
+	assertStatus(t, pool, Status{Available: 1})
+}
+
+func TestPool_WorkerExits(t *testing.T) {
+	pool := startPool(t, 1, 0)
+	defer pool.Stop()
+	owner, dispose := goactor.NewParentActor(nil)
+	defer dispose()
+
+	worker, err := pool.Checkout(owner.Self(), time.Second)
+	if !assert.Nil(t, err) {return}
+	if !assert.Nil(t, goactor.Send(worker, "crash")) {return}
+	assertStatus(t, pool, Status{Available: 1})
+
+	// the restarted worker is handed out
+	var restarted *p.PID
+	assert.Eventually(t, func() bool {
+		restarted, err = pool.Checkout(owner.Self(), time.Second)
+		if err != nil {
+			return false
+		}
+		if p.Equal(worker, restarted) {
+			_ = pool.Checkin(restarted)
+			return false
+		}
+		return true
+	}, time.Second, 5*time.Millisecond)
+	assert.Nil(t, goactor.Send(restarted, "hello"))
+}
+
+func TestPool_WorkerExitsWhileWaiting(t *testing.T) {
+	pool := startPool(t, 1, 0)
+	defer pool.Stop()
+	owner, dispose := goactor.NewParentActor(nil)
+	defer dispose()
+
+	worker, err := pool.Checkout(owner.Self(), time.Second)
+	if !assert.Nil(t, err) {return}
+
+	checkedOut := make(chan *p.PID, 1)
+	go func() {
+		w, err := pool.Checkout(owner.Self(), time.Second)
+		assert.Nil(t, err)
+		checkedOut <- w
+	}()
+	if !assertStatus(t, pool, Status{CheckedOut: 1, Waiting: 1}) {return}
+
+	// the waiting caller gets the restarted worker, rather than waiting until its checkout times out
+	if !assert.Nil(t, goactor.Send(worker, "crash")) {return}
+	select {
+	case w := <-checkedOut:
+		if !assert.NotNil(t, w) {return}
+		assert.False(t, p.Equal(worker, w))
+	case <-time.After(2 * time.Second):
+		t.Error("the waiting caller didn't get the restarted worker")
+	}
+	assertStatus(t, pool, Status{CheckedOut: 1})
+}
+
+func TestPool_Transaction(t *testing.T) {
+	pool := startPool(t, 1, 0)
+	defer pool.Stop()
+	owner, dispose := goactor.NewParentActor(nil)
+	defer dispose()
+
+	var used *p.PID
+	err := pool.Transaction(owner.Self(), time.Second, func(worker *p.PID) {
+		used = worker
+		assert.Nil(t, goactor.Send(worker, "hello"))
+	})
+	if !assert.Nil(t, err) {return}
+	assert.NotNil(t, used)
+	assertStatus(t, pool, Status{Available: 1})
+}
+
+func TestPool_Stop(t *testing.T) {
+	pool := startPool(t, 1, 0)
+	owner, dispose := goactor.NewParentActor(nil)
+	defer dispose()
+
+	worker, err := pool.Checkout(owner.Self(), time.Second)
+	if !assert.Nil(t, err) {return}
+
+	if !assert.Nil(t, pool.Stop()) {return}
+	assert.Eventually(t, func() bool {
+		return goactor.Send(worker, "hello") != nil
+	}, time.Second, 5*time.Millisecond)
+}
@@ -1,13 +1,22 @@
 package process
 
 import (
+	"fmt"
 	"github.com/hedisam/goactor/internal/intlpid"
+	"github.com/hedisam/goactor/internal/pidconv"
 	p "github.com/hedisam/goactor/pid"
 	"sync"
 )
 
+var ErrNilPID = fmt.Errorf("process: pid is nil")
+var ErrNameTaken = fmt.Errorf("process: name is already registered by another actor")
+var ErrAlreadyRegistered = fmt.Errorf("process: actor is already registered by another name")
+var ErrDeadProcess = fmt.Errorf("process: actor is not alive")
+
 type registry struct {
 	actors map[string]intlpid.InternalPID
+	// names maps the id of the registered pids to their name.
+	names map[string]string
 	sync.RWMutex
 }
 
@@ -17,16 +26,68 @@ var reg *registry
 func newRegistry() *registry {
 	return &registry{
 		actors: make(map[string]intlpid.InternalPID),
+		names:  make(map[string]string),
 	}
 }
 func init() {
 	reg = newRegistry()
 }
 
-func Register(name string, pid *p.PID) {
+// Register registers the actor by the given name. An actor can have one name, and a name can belong to one actor,
+// though the name of an actor which has exited can be taken by another one. Registering an actor by its own name again
+// is a no-op.
+func Register(name string, pid *p.PID) error {
+	if pid == nil {
+		return ErrNilPID
+	}
+	reg.Lock()
+	defer reg.Unlock()
+
+	return reg.register(name, pidconv.Internal(pid))
+}
+
+// RegisterOrGet registers the actor by the given name, unless the name belongs to another actor which is alive, in
+// which case that actor's pid is returned. Otherwise, the given pid is returned.
+func RegisterOrGet(name string, pid *p.PID) (*p.PID, error) {
+	if pid == nil {
+		return nil, ErrNilPID
+	}
 	reg.Lock()
-	reg.actors[name] = pid.InternalPID()
-	reg.Unlock()
+	defer reg.Unlock()
+
+	if registered, ok := reg.actors[name]; ok && intlpid.IsAlive(registered) {
+		return pidconv.ToPID(registered), nil
+	}
+	err := reg.register(name, pidconv.Internal(pid))
+	if err != nil {
+		return nil, err
+	}
+	return pid, nil
+}
+
+// register must be called with the write lock held.
+func (r *registry) register(name string, iPID intlpid.InternalPID) error {
+	if !intlpid.IsAlive(iPID) {
+		return ErrDeadProcess
+	}
+	if registered, ok := r.actors[name]; ok {
+		if registered.ID() == iPID.ID() {
+			return nil
+		}
+		if intlpid.IsAlive(registered) {
+			return ErrNameTaken
+		}
+	}
+	if current, ok := r.names[iPID.ID()]; ok {
+		if registered, ok := r.actors[current]; ok && registered.ID() == iPID.ID() {
+			return ErrAlreadyRegistered
+		}
+	}
+
+	r.unregister(name)
+	r.actors[name] = iPID
+	r.names[iPID.ID()] = name
+	return nil
 }
 
 func Unregister(name string) {
@@ -39,7 +100,7 @@ func Unregister(name string) {
 		reg.RUnlock()
 
 		reg.Lock()
-		delete(reg.actors, name)
+		reg.unregister(name)
 		reg.Unlock()
 		return
 	}
@@ -47,10 +108,73 @@ func Unregister(name string) {
 	reg.RUnlock()
 }
 
+// unregister must be called with the write lock held.
+func (r *registry) unregister(name string) {
+	iPID, ok := r.actors[name]
+	if !ok {
+		return
+	}
+	delete(r.actors, name)
+	delete(r.names, iPID.ID())
+}
+
+// WhereIs returns the pid of the actor registered by the given name. The name of an actor which has exited is
+// dropped, so it's not found anymore, even before another actor takes it.
 func WhereIs(name string) (*p.PID, bool) {
 	reg.RLock()
-	defer reg.RUnlock()
+	iPID, ok := reg.actors[name]
+	reg.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	if !intlpid.IsAlive(iPID) {
+		reg.dropDead(name, iPID)
+		return nil, false
+	}
+	return pidconv.ToPID(iPID), true
+}
 
-	intlPID, ok := reg.actors[name]
-	return p.ToPID(intlPID), ok
+// NameOf returns the name the actor is registered by. An actor which has exited has no name.
+func NameOf(pid *p.PID) (string, bool) {
+	if pid == nil {
+		return "", false
+	}
+	reg.RLock()
+	name, ok := reg.names[pid.ID()]
+	reg.RUnlock()
+	if !ok {
+		return "", false
+	}
+	if !intlpid.IsAlive(pidconv.Internal(pid)) {
+		reg.dropDead(name, pidconv.Internal(pid))
+		return "", false
+	}
+	return name, true
+}
+
+// Registered returns the names of the registered actors which are alive, in no particular order. The names of the
+// ones which have exited are dropped.
+func Registered() []string {
+	reg.Lock()
+	defer reg.Unlock()
+
+	names := make([]string, 0, len(reg.actors))
+	for name, iPID := range reg.actors {
+		if !intlpid.IsAlive(iPID) {
+			reg.unregister(name)
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// dropDead unregisters the name, if it's still registered by the given pid, whose actor has exited.
+func (r *registry) dropDead(name string, iPID intlpid.InternalPID) {
+	r.Lock()
+	defer r.Unlock()
+
+	if registered, ok := r.actors[name]; ok && registered.ID() == iPID.ID() {
+		r.unregister(name)
+	}
 }
@@ -2,33 +2,45 @@ package process
 
 import (
 	"github.com/hedisam/goactor/internal/intlpid"
-	p "github.com/hedisam/goactor/pid"
+	"github.com/hedisam/goactor/internal/pidconv"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
 
 func TestRegistry(t *testing.T) {
 	internalPID := intlpid.NewMockInternalPID()
-	pid := p.ToPID(internalPID)
+	pid := pidconv.ToPID(internalPID)
 	name := "my pid"
 
-	Register(name, pid)
+	if !assert.Nil(t, Register(name, pid)) {return}
 
 	registeredPID, ok := WhereIs(name)
 	if !assert.True(t, ok) {return}
 	if !assert.NotNil(t, registeredPID) {return}
 	if !assert.Equal(t, pid, registeredPID) {return}
 
+	// registering by the same name again is a no-op
+	if !assert.Nil(t, Register(name, pid)) {return}
+
 	newInternalPID := intlpid.NewMockInternalPID()
-	newPID := p.ToPID(newInternalPID)
+	newPID := pidconv.ToPID(newInternalPID)
 
-	Register(name, newPID)
+	// the name belongs to a live actor
+	assert.Equal(t, ErrNameTaken, Register(name, newPID))
+	registeredPID, ok = WhereIs(name)
+	if !assert.True(t, ok) {return}
+	if !assert.Equal(t, pid, registeredPID) {return}
 
+	// the name of an actor which has exited can be taken
+	internalPID.Kill()
+	if !assert.Nil(t, Register(name, newPID)) {return}
 	registeredPID, ok = WhereIs(name)
 	if !assert.True(t, ok) {return}
 	if !assert.NotNil(t, registeredPID) {return}
 	if !assert.Equal(t, newPID, registeredPID) {return}
 	if !assert.NotEqual(t, pid, registeredPID) {return}
+	_, ok = NameOf(pid)
+	assert.False(t, ok)
 
 	Unregister(name)
 
@@ -41,3 +53,80 @@ func TestRegistry(t *testing.T) {
 	// now again unregister the same name to make sure the locks are freed as expected
 	Unregister(name)
 }
+
+func TestRegister_Errors(t *testing.T) {
+	assert.Equal(t, ErrNilPID, Register("nil pid", nil))
+
+	dead := intlpid.NewMockInternalPID()
+	dead.Kill()
+	assert.Equal(t, ErrDeadProcess, Register("dead pid", pidconv.ToPID(dead)))
+	_, ok := WhereIs("dead pid")
+	assert.False(t, ok)
+
+	// an actor can have one name
+	pid := pidconv.ToPID(intlpid.NewMockInternalPID())
+	if !assert.Nil(t, Register("first name", pid)) {return}
+	defer Unregister("first name")
+	assert.Equal(t, ErrAlreadyRegistered, Register("second name", pid))
+}
+
+func TestRegisterOrGet(t *testing.T) {
+	name := "register or get"
+	defer Unregister(name)
+	pid := pidconv.ToPID(intlpid.NewMockInternalPID())
+	other := pidconv.ToPID(intlpid.NewMockInternalPID())
+
+	registered, err := RegisterOrGet(name, pid)
+	assert.Nil(t, err)
+	assert.Equal(t, pid, registered)
+
+	registered, err = RegisterOrGet(name, other)
+	assert.Nil(t, err)
+	assert.Equal(t, pid, registered)
+	_, ok := NameOf(other)
+	assert.False(t, ok)
+
+	_, err = RegisterOrGet(name, nil)
+	assert.Equal(t, ErrNilPID, err)
+}
+
+func TestNameOf_Registered(t *testing.T) {
+	name := "name of"
+	pid := pidconv.ToPID(intlpid.NewMockInternalPID())
+	if !assert.Nil(t, Register(name, pid)) {return}
+
+	registeredName, ok := NameOf(pid)
+	assert.True(t, ok)
+	assert.Equal(t, name, registeredName)
+	assert.Contains(t, Registered(), name)
+
+	Unregister(name)
+	_, ok = NameOf(pid)
+	assert.False(t, ok)
+	assert.NotContains(t, Registered(), name)
+
+	_, ok = NameOf(nil)
+	assert.False(t, ok)
+}
+
+func TestRegistry_DeadActor(t *testing.T) {
+	name := "dead actor"
+	iPID := intlpid.NewMockInternalPID()
+	pid := pidconv.ToPID(iPID)
+	if !assert.Nil(t, Register(name, pid)) {return}
+	defer Unregister(name)
+
+	// the name of an actor which has exited isn't found anymore, even though no other actor has taken it
+	iPID.Kill()
+	_, ok := WhereIs(name)
+	assert.False(t, ok)
+	_, ok = NameOf(pid)
+	assert.False(t, ok)
+	assert.NotContains(t, Registered(), name)
+
+	other := pidconv.ToPID(intlpid.NewMockInternalPID())
+	if !assert.Nil(t, Register(name, other)) {return}
+	registered, ok := WhereIs(name)
+	assert.True(t, ok)
+	assert.Equal(t, other, registered)
+}
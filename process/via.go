@@ -0,0 +1,25 @@
+package process
+
+import p "github.com/hedisam/goactor/pid"
+
+// Registry is a naming registry other than the default one. Actors registered to it can be addressed by a Via name.
+type Registry interface {
+	RegisterName(name string, pid *p.PID) error
+	// UnregisterName removes the registration of the given name, if it belongs to the given pid.
+	UnregisterName(name string, pid *p.PID)
+	WhereIsName(name string) (*p.PID, bool)
+}
+
+// Via is a name which is looked up in the given registry instead of the default one.
+type Via struct {
+	Registry Registry
+	Name     string
+}
+
+// WhereIs returns the pid registered by the via name.
+func (v Via) WhereIs() (*p.PID, bool) {
+	if v.Registry == nil {
+		return nil, false
+	}
+	return v.Registry.WhereIsName(v.Name)
+}
@@ -0,0 +1,169 @@
+// Package pubsub lets actors subscribe to topics and receive what's published to them. Topics are made of segments
+// separated by dots, e.g. "orders.eu.created". A subscription's topic can use '*' as a segment to match exactly one
+// segment, e.g. "orders.*.created", and '#' to match zero or more segments, e.g. "orders.#".
+package pubsub
+
+import (
+	"fmt"
+	"github.com/hedisam/goactor"
+	"github.com/hedisam/goactor/internal/watch"
+	p "github.com/hedisam/goactor/pid"
+	"github.com/hedisam/goactor/sysmsg"
+	"sync"
+)
+
+var ErrNilPID = fmt.Errorf("pubsub: pid is nil")
+var ErrInvalidTopic = fmt.Errorf("pubsub: invalid topic")
+var ErrNotSubscribed = fmt.Errorf("pubsub: pid is not subscribed to the topic")
+
+// Message is what subscribers receive for each publish. Topic is the topic it was published to, which the
+// subscription's topic matched.
+type Message struct {
+	Topic   string
+	Payload interface{}
+}
+
+// subscription is what a monitor reference stands for.
+type subscription struct {
+	pattern string
+	pid     *p.PID
+}
+
+type topics struct {
+	root *node
+	refs map[sysmsg.MonitorRef]subscription
+	// watcher is started by the first Subscribe, so importing the package doesn't start an actor.
+	watcher *watch.Watcher
+	sync.RWMutex
+}
+
+var pubsub *topics
+
+func newTopics() *topics {
+	return &topics{root: newNode(), refs: make(map[sysmsg.MonitorRef]subscription)}
+}
+
+func init() {
+	pubsub = newTopics()
+}
+
+// Subscribe makes the given actor receive a Message for each publish to the topics that match the given one, which
+// can contain wildcards. The subscription gets removed automatically once the subscriber exits. Subscribing more than
+// once is a no-op.
+func Subscribe(topic string, pid *p.PID) error {
+	if pid == nil {
+		return ErrNilPID
+	} else if !validPattern(topic) {
+		return fmt.Errorf("%w: %q", ErrInvalidTopic, topic)
+	}
+	pubsub.Lock()
+	defer pubsub.Unlock()
+
+	segments := split(topic)
+	if _, ok := pubsub.root.get(segments, pid.ID()); ok {
+		return nil
+	}
+	ref, err := pubsub.watch(pid)
+	if err != nil {
+		return fmt.Errorf("pubsub: subscribe failed: %w", err)
+	}
+	pubsub.root.add(segments, pid.ID(), ref)
+	pubsub.refs[ref] = subscription{pattern: topic, pid: pid}
+	return nil
+}
+
+// Unsubscribe removes the given actor's subscription to the topic. The topic must be the same one it subscribed to.
+func Unsubscribe(topic string, pid *p.PID) error {
+	if pid == nil {
+		return ErrNilPID
+	}
+	pubsub.Lock()
+	defer pubsub.Unlock()
+
+	ref, ok := pubsub.root.get(split(topic), pid.ID())
+	if !ok {
+		return ErrNotSubscribed
+	}
+	_ = pubsub.watcher.Unwatch(ref)
+	pubsub.remove(ref)
+	return nil
+}
+
+// Subscribers returns the actors which would receive a publish to the topic, in no particular order.
+func Subscribers(topic string) []*p.PID {
+	if !validTopic(topic) {
+		return nil
+	}
+	pubsub.RLock()
+	defer pubsub.RUnlock()
+
+	return pubsub.match(topic)
+}
+
+// Publish sends a Message to each of the topic's subscribers. An actor which has more than one matching subscription
+// receives the message once. It tries all of the subscribers even if sending to some of them fails.
+func Publish(topic string, payload interface{}) error {
+	if !validTopic(topic) {
+		return fmt.Errorf("%w: %q", ErrInvalidTopic, topic)
+	}
+	pubsub.RLock()
+	subscribers := pubsub.match(topic)
+	pubsub.RUnlock()
+
+	msg := Message{Topic: topic, Payload: payload}
+	var failed int
+	var firstErr error
+	for _, pid := range subscribers {
+		err := goactor.Send(pid, msg)
+		if err != nil {
+			failed++
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	if firstErr != nil {
+		return fmt.Errorf("pubsub: publish failed for %d subscriber(s): %w", failed, firstErr)
+	}
+	return nil
+}
+
+// watch monitors the subscriber, starting the watcher if it's the first one. must be called with the write lock held.
+func (t *topics) watch(pid *p.PID) (sysmsg.MonitorRef, error) {
+	if t.watcher == nil {
+		t.watcher = watch.New(t.onDown)
+	}
+	return t.watcher.Watch(pid)
+}
+
+// onDown is called by the watcher when a subscriber exits.
+func (t *topics) onDown(down sysmsg.Down) {
+	t.Lock()
+	defer t.Unlock()
+
+	t.remove(down.Ref)
+}
+
+// remove removes the subscription identified by ref. must be called with the write lock held.
+func (t *topics) remove(ref sysmsg.MonitorRef) {
+	sub, ok := t.refs[ref]
+	if !ok {
+		return
+	}
+	delete(t.refs, ref)
+	t.root.remove(split(sub.pattern), sub.pid.ID())
+}
+
+// match returns the distinct subscribers of the topic. must be called with the read lock held.
+func (t *topics) match(topic string) []*p.PID {
+	seen := make(map[string]bool)
+	var pids []*p.PID
+	t.root.match(split(topic), func(ref sysmsg.MonitorRef) {
+		pid := t.refs[ref].pid
+		if !seen[pid.ID()] {
+			seen[pid.ID()] = true
+			pids = append(pids, pid)
+		}
+	})
+	return pids
+}
@@ -0,0 +1,141 @@
+package pubsub
+
+import (
+	"errors"
+	"github.com/hedisam/goactor"
+	p "github.com/hedisam/goactor/pid"
+	"github.com/hedisam/goactor/sysmsg"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func contains(pids []*p.PID, pid *p.PID) bool {
+	for _, subscriber := range pids {
+		if p.Equal(subscriber, pid) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		topic   string
+		match   bool
+	}{
+		{"orders.created", "orders.created", true},
+		{"orders.created", "orders.deleted", false},
+		{"orders.*", "orders.created", true},
+		{"orders.*", "orders", false},
+		{"orders.*", "orders.eu.created", false},
+		{"orders.*.created", "orders.eu.created", true},
+		{"orders.#", "orders", true},
+		{"orders.#", "orders.created", true},
+		{"orders.#", "orders.eu.created", true},
+		{"orders.#", "users.created", false},
+		{"#", "users.created", true},
+		{"#.created", "orders.eu.created", true},
+		{"#.created", "orders.eu.deleted", false},
+		{"orders.#.created", "orders.created", true},
+		{"orders.#.created", "orders.eu.west.created", true},
+		{"*.*", "orders", false},
+	}
+	for _, test := range tests {
+		root := newNode()
+		root.add(split(test.pattern), "id", "ref")
+		var matched bool
+		root.match(split(test.topic), func(ref sysmsg.MonitorRef) {
+			matched = true
+		})
+		assert.Equal(t, test.match, matched, "pattern %q, topic %q", test.pattern, test.topic)
+
+		assert.True(t, root.remove(split(test.pattern), "id"))
+		assert.Empty(t, root.children)
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	actor, dispose := goactor.NewParentActor(nil)
+	defer dispose()
+
+	assert.Equal(t, ErrNilPID, Subscribe("orders", nil))
+	for _, topic := range []string{"", "orders.", "orders.cr*ated", "orders..created"} {
+		assert.True(t, errors.Is(Subscribe(topic, actor.Self()), ErrInvalidTopic), topic)
+	}
+	assert.True(t, errors.Is(Publish("orders.*", nil), ErrInvalidTopic))
+
+	if !assert.Nil(t, Subscribe("subscribe.*", actor.Self())) {return}
+	// subscribing twice is a no-op
+	if !assert.Nil(t, Subscribe("subscribe.*", actor.Self())) {return}
+	assert.True(t, contains(Subscribers("subscribe.orders"), actor.Self()))
+	assert.Empty(t, Subscribers("subscribe"))
+
+	assert.Nil(t, Unsubscribe("subscribe.*", actor.Self()))
+	assert.Equal(t, ErrNotSubscribed, Unsubscribe("subscribe.*", actor.Self()))
+	assert.Empty(t, Subscribers("subscribe.orders"))
+}
+
+func TestPublish(t *testing.T) {
+	actor1, dispose1 := goactor.NewParentActor(nil)
+	defer dispose1()
+	actor2, dispose2 := goactor.NewParentActor(nil)
+	defer dispose2()
+
+	if !assert.Nil(t, Subscribe("publish.orders.*", actor1.Self())) {return}
+	// the matching subscriptions of the same actor get a single message
+	if !assert.Nil(t, Subscribe("publish.orders.#", actor1.Self())) {return}
+	if !assert.Nil(t, Subscribe("publish.#", actor2.Self())) {return}
+
+	if !assert.Nil(t, Publish("publish.orders.created", 42)) {return}
+	for _, actor := range []*goactor.Actor{actor1, actor2} {
+		err := actor.ReceiveWithTimeout(time.Second, func(message interface{}) (loop bool) {
+			assert.Equal(t, Message{Topic: "publish.orders.created", Payload: 42}, message)
+			return false
+		})
+		assert.Nil(t, err)
+	}
+	err := actor1.ReceiveWithTimeout(20*time.Millisecond, func(message interface{}) (loop bool) {
+		t.Errorf("unexpected message: %v", message)
+		return false
+	})
+	assert.NotNil(t, err)
+
+	// only actor2 matches
+	if !assert.Nil(t, Publish("publish.users", "user")) {return}
+	err = actor2.ReceiveWithTimeout(time.Second, func(message interface{}) (loop bool) {
+		assert.Equal(t, Message{Topic: "publish.users", Payload: "user"}, message)
+		return false
+	})
+	assert.Nil(t, err)
+}
+
+func TestSubscriberExit(t *testing.T) {
+	actor, dispose := goactor.NewParentActor(nil)
+
+	if !assert.Nil(t, Subscribe("exit.*", actor.Self())) {return}
+	if !assert.Nil(t, Subscribe("exit.#", actor.Self())) {return}
+	assert.True(t, contains(Subscribers("exit.now"), actor.Self()))
+
+	// the subscriptions get removed once the subscriber exits
+	dispose()
+	assert.Eventually(t, func() bool {
+		return len(Subscribers("exit.now")) == 0
+	}, time.Second, 5*time.Millisecond)
+	assert.Nil(t, Publish("exit.now", nil))
+}
+
+func TestTopics_LazyWatcher(t *testing.T) {
+	topics := newTopics()
+	// nobody has subscribed yet, so no actor is started
+	assert.Nil(t, topics.watcher)
+
+	subscriber, dispose := goactor.NewParentActor(nil)
+	defer dispose()
+	topics.Lock()
+	_, err := topics.watch(subscriber.Self())
+	topics.Unlock()
+	if !assert.Nil(t, err) {return}
+	assert.NotNil(t, topics.watcher)
+}
@@ -0,0 +1,103 @@
+package pubsub
+
+import (
+	"github.com/hedisam/goactor/sysmsg"
+	"strings"
+)
+
+const (
+	separator = "."
+	// singleWildcard matches exactly one segment of a topic and multiWildcard matches zero or more segments.
+	singleWildcard = "*"
+	multiWildcard  = "#"
+)
+
+// node is a node of the subscriptions' trie. Each node stands for a segment of the topic patterns.
+type node struct {
+	children map[string]*node
+	// subscribers maps the ids of the subscribers of the pattern ending at this node to their monitor reference.
+	subscribers map[string]sysmsg.MonitorRef
+}
+
+func newNode() *node {
+	return &node{children: make(map[string]*node), subscribers: make(map[string]sysmsg.MonitorRef)}
+}
+
+func split(topic string) []string {
+	return strings.Split(topic, separator)
+}
+
+// validPattern reports whether each segment of the pattern is either a wildcard or a name without wildcards.
+func validPattern(pattern string) bool {
+	for _, segment := range split(pattern) {
+		if segment == singleWildcard || segment == multiWildcard {
+			continue
+		}
+		if segment == "" || strings.ContainsAny(segment, singleWildcard+multiWildcard) {
+			return false
+		}
+	}
+	return true
+}
+
+// validTopic reports whether the topic can be published to, which is a pattern without wildcards.
+func validTopic(topic string) bool {
+	return validPattern(topic) && !strings.ContainsAny(topic, singleWildcard+multiWildcard)
+}
+
+func (n *node) add(segments []string, id string, ref sysmsg.MonitorRef) {
+	for _, segment := range segments {
+		child, ok := n.children[segment]
+		if !ok {
+			child = newNode()
+			n.children[segment] = child
+		}
+		n = child
+	}
+	n.subscribers[id] = ref
+}
+
+func (n *node) get(segments []string, id string) (sysmsg.MonitorRef, bool) {
+	for _, segment := range segments {
+		child, ok := n.children[segment]
+		if !ok {
+			return "", false
+		}
+		n = child
+	}
+	ref, ok := n.subscribers[id]
+	return ref, ok
+}
+
+// remove removes the subscriber and prunes the nodes which are left empty. It returns true if n itself is empty.
+func (n *node) remove(segments []string, id string) bool {
+	if len(segments) == 0 {
+		delete(n.subscribers, id)
+	} else if child, ok := n.children[segments[0]]; ok && child.remove(segments[1:], id) {
+		delete(n.children, segments[0])
+	}
+	return len(n.subscribers) == 0 && len(n.children) == 0
+}
+
+// match calls fn with the monitor reference of each subscriber whose pattern matches the topic's segments. A
+// subscriber can be matched by more than one of its patterns.
+func (n *node) match(segments []string, fn func(ref sysmsg.MonitorRef)) {
+	if multi, ok := n.children[multiWildcard]; ok {
+		// '#' can match any number of the remaining segments, including none.
+		for i := 0; i <= len(segments); i++ {
+			multi.match(segments[i:], fn)
+		}
+	}
+	if len(segments) == 0 {
+		for _, ref := range n.subscribers {
+			fn(ref)
+		}
+		return
+	}
+	if child, ok := n.children[segments[0]]; ok {
+		child.match(segments[1:], fn)
+	}
+	if single, ok := n.children[singleWildcard]; ok {
+		single.match(segments[1:], fn)
+	}
+}
@@ -0,0 +1,98 @@
+// Package reason provides the reasons an actor can exit with. All reasons implement the error interface, so they can
+// be inspected using errors.Is and errors.As.
+package reason
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// Reason is the reason an actor has exited with.
+type Reason interface {
+	error
+	reason()
+}
+
+// atom is a reason with no extra information attached to it.
+type atom string
+
+func (a atom) Error() string {
+	return string(a)
+}
+
+func (atom) reason() {}
+
+var (
+	// Normal is the reason of an actor that has finished its work, e.g. its ActorFunc has returned.
+	Normal Reason = atom("normal")
+	// Shutdown is the reason of an actor that has been asked to shut down, e.g. by its supervisor.
+	Shutdown Reason = atom("shutdown")
+	// Killed is the reason of an actor that has been killed unconditionally.
+	Killed Reason = atom("killed")
+	// NoProc is the reason of a Down message delivered for an actor that had already exited when it was monitored.
+	NoProc Reason = atom("noproc")
+	// NoConnection is the reason of a Down message delivered when the connection to the monitored actor's node
+	// is lost.
+	NoConnection Reason = atom("noconnection")
+)
+
+// Panic is the reason of an actor that has panicked. The stack trace of the panicking goroutine is kept in Stack.
+type Panic struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (p Panic) Error() string {
+	return fmt.Sprintf("panic: %v", p.Value)
+}
+
+// Unwrap returns the panic's value if it's an error, so errors.Is and errors.As can see through the panic.
+func (p Panic) Unwrap() error {
+	err, _ := p.Value.(error)
+	return err
+}
+
+func (Panic) reason() {}
+
+// Error is the reason of an actor that has exited with an error.
+type Error struct {
+	Err error
+}
+
+func (e Error) Error() string {
+	if e.Err == nil {
+		return "error: <nil>"
+	}
+	return e.Err.Error()
+}
+
+func (e Error) Unwrap() error {
+	return e.Err
+}
+
+func (Error) reason() {}
+
+// FromPanic converts a recovered panic value to a Reason. A Reason value is returned as it is, while any other value
+// gets wrapped in a Panic along with the current goroutine's stack, so it must be called by the deferred function
+// which has recovered the panic.
+func FromPanic(r interface{}) Reason {
+	if rsn, ok := r.(Reason); ok {
+		return rsn
+	}
+	return Panic{Value: r, Stack: debug.Stack()}
+}
+
+// FromError converts an error to a Reason. A Reason value is returned as it is, while any other error gets wrapped
+// in an Error.
+func FromError(err error) Reason {
+	if rsn, ok := err.(Reason); ok {
+		return rsn
+	}
+	return Error{Err: err}
+}
+
+// IsNormal returns true if the given reason denotes an expected exit, which is either Normal or Shutdown.
+// Transient children are not restarted by their supervisor when they exit with such reasons.
+func IsNormal(r Reason) bool {
+	return r == Normal || r == Shutdown
+}
@@ -0,0 +1,71 @@
+package reason
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestReasons(t *testing.T) {
+	reasons := []Reason{Normal, Shutdown, Killed, NoProc, NoConnection}
+	for _, r := range reasons {
+		assert.True(t, errors.Is(r, r))
+		assert.True(t, errors.Is(fmt.Errorf("wrapped: %w", r), r))
+	}
+	assert.False(t, errors.Is(Normal, Shutdown))
+	assert.Equal(t, "normal", Normal.Error())
+}
+
+func TestFromPanic(t *testing.T) {
+	t.Run("reason as the panic's value", func(t *testing.T) {
+		r := FromPanic(Killed)
+		assert.Equal(t, Killed, r)
+	})
+
+	t.Run("arbitrary panic's value", func(t *testing.T) {
+		var r Reason
+		func() {
+			defer func() {
+				r = FromPanic(recover())
+			}()
+			panic("something went wrong")
+		}()
+
+		var p Panic
+		if !assert.True(t, errors.As(r, &p)) {return}
+		assert.Equal(t, "something went wrong", p.Value)
+		assert.Contains(t, string(p.Stack), "TestFromPanic")
+		assert.Equal(t, "panic: something went wrong", r.Error())
+	})
+
+	t.Run("error as the panic's value", func(t *testing.T) {
+		errFailed := errors.New("failed")
+		r := FromPanic(errFailed)
+
+		assert.True(t, errors.Is(r, errFailed))
+		assert.IsType(t, Panic{}, r)
+	})
+}
+
+func TestFromError(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	r := FromError(errFailed)
+	assert.True(t, errors.Is(r, errFailed))
+	assert.Equal(t, "failed", r.Error())
+
+	var e Error
+	if !assert.True(t, errors.As(r, &e)) {return}
+	assert.Equal(t, errFailed, e.Err)
+
+	assert.Equal(t, Shutdown, FromError(Shutdown))
+}
+
+func TestIsNormal(t *testing.T) {
+	assert.True(t, IsNormal(Normal))
+	assert.True(t, IsNormal(Shutdown))
+	assert.False(t, IsNormal(Killed))
+	assert.False(t, IsNormal(FromError(errors.New("failed"))))
+	assert.False(t, IsNormal(nil))
+}
@@ -0,0 +1,312 @@
+// Package registry provides local key/pid registries. A registry's keys are either unique, where each key belongs to
+// one actor at most, or duplicate, where any number of actors can register by the same key. Each registration holds a
+// value, and is removed automatically once its actor exits.
+package registry
+
+import (
+	"fmt"
+	"github.com/hedisam/goactor"
+	"github.com/hedisam/goactor/internal/watch"
+	p "github.com/hedisam/goactor/pid"
+	"github.com/hedisam/goactor/process"
+	"github.com/hedisam/goactor/sysmsg"
+	"hash/fnv"
+	"sync"
+)
+
+var ErrNilPID = fmt.Errorf("registry: pid is nil")
+var ErrAlreadyRegistered = fmt.Errorf("registry: key is already registered")
+var ErrNotRegistered = fmt.Errorf("registry: key is not registered by the pid")
+var ErrClosed = fmt.Errorf("registry: registry is closed")
+
+type Keys int
+
+const (
+	// Unique keys belong to one actor at most.
+	Unique Keys = iota
+	// Duplicate keys can be registered by any number of actors, though an actor can register a key once.
+	Duplicate
+)
+
+// Entry is a registration of a key.
+type Entry struct {
+	PID   *p.PID
+	Value interface{}
+}
+
+type entry struct {
+	Entry
+	ref sysmsg.MonitorRef
+}
+
+// partition holds the keys whose hash falls into it, each with its entries in the order they were registered.
+type partition struct {
+	entries map[string][]*entry
+	sync.RWMutex
+}
+
+// registration is what a monitor reference stands for.
+type registration struct {
+	key string
+	pid *p.PID
+}
+
+// Registry is a key/pid registry. Its keys are spread over a number of partitions, each guarded by its own lock, so
+// registrations of different keys don't contend with each other.
+type Registry struct {
+	keys       Keys
+	partitions []*partition
+	watcher    *watch.Watcher
+
+	// refs maps the monitors of the registered actors to their registration.
+	refs   map[sysmsg.MonitorRef]registration
+	closed bool
+	refsMu sync.Mutex
+}
+
+// New returns a new registry with the given kind of keys and number of partitions. There's at least one partition.
+// The registry runs an actor which watches the registered actors, so it must be closed by Close once it's no longer
+// used.
+func New(keys Keys, partitions int) *Registry {
+	if partitions < 1 {
+		partitions = 1
+	}
+	r := &Registry{keys: keys, refs: make(map[sysmsg.MonitorRef]registration)}
+	for i := 0; i < partitions; i++ {
+		r.partitions = append(r.partitions, &partition{entries: make(map[string][]*entry)})
+	}
+	r.watcher = watch.New(r.onDown)
+	return r
+}
+
+// Register registers the actor by the key along with the given value.
+func (r *Registry) Register(key string, pid *p.PID, value interface{}) error {
+	if pid == nil {
+		return ErrNilPID
+	}
+	part := r.partition(key)
+	part.Lock()
+	defer part.Unlock()
+
+	entries := part.entries[key]
+	if (r.keys == Unique && len(entries) > 0) || find(entries, pid) >= 0 {
+		return ErrAlreadyRegistered
+	}
+
+	// the Down message of an actor which has already exited is only handled once its ref is known.
+	r.refsMu.Lock()
+	defer r.refsMu.Unlock()
+	if r.closed {
+		return ErrClosed
+	}
+	ref, err := r.watcher.Watch(pid)
+	if err != nil {
+		return fmt.Errorf("registry: register failed: %w", err)
+	}
+	r.refs[ref] = registration{key: key, pid: pid}
+	part.entries[key] = append(entries, &entry{Entry: Entry{PID: pid, Value: value}, ref: ref})
+	return nil
+}
+
+// Unregister removes the actor's registration of the key.
+func (r *Registry) Unregister(key string, pid *p.PID) error {
+	if pid == nil {
+		return ErrNilPID
+	}
+	part := r.partition(key)
+	part.Lock()
+	defer part.Unlock()
+
+	i := find(part.entries[key], pid)
+	if i < 0 {
+		return ErrNotRegistered
+	}
+	ref := part.entries[key][i].ref
+	_ = r.watcher.Unwatch(ref)
+	r.refsMu.Lock()
+	delete(r.refs, ref)
+	r.refsMu.Unlock()
+	part.remove(key, i)
+	return nil
+}
+
+// Lookup returns the entries of the key in the order they were registered.
+func (r *Registry) Lookup(key string) []Entry {
+	part := r.partition(key)
+	part.RLock()
+	defer part.RUnlock()
+
+	entries := make([]Entry, 0, len(part.entries[key]))
+	for _, e := range part.entries[key] {
+		entries = append(entries, e.Entry)
+	}
+	return entries
+}
+
+// UpdateValue replaces the value of the actor's registration of the key by the one returned by fn.
+func (r *Registry) UpdateValue(key string, pid *p.PID, fn func(value interface{}) interface{}) error {
+	if pid == nil {
+		return ErrNilPID
+	}
+	part := r.partition(key)
+	part.Lock()
+	defer part.Unlock()
+
+	i := find(part.entries[key], pid)
+	if i < 0 {
+		return ErrNotRegistered
+	}
+	e := part.entries[key][i]
+	e.Value = fn(e.Value)
+	return nil
+}
+
+// Keys returns the keys the actor is registered by, in no particular order.
+func (r *Registry) Keys(pid *p.PID) []string {
+	if pid == nil {
+		return nil
+	}
+	var keys []string
+	for _, part := range r.partitions {
+		part.RLock()
+		for key, entries := range part.entries {
+			if find(entries, pid) >= 0 {
+				keys = append(keys, key)
+			}
+		}
+		part.RUnlock()
+	}
+	return keys
+}
+
+// Count returns the number of registrations.
+func (r *Registry) Count() int {
+	var count int
+	for _, part := range r.partitions {
+		part.RLock()
+		for _, entries := range part.entries {
+			count += len(entries)
+		}
+		part.RUnlock()
+	}
+	return count
+}
+
+// Dispatch calls fn with the entries of the key, if there's any. fn is called without holding the registry's locks,
+// so the entries could be outdated by the time it's called.
+func (r *Registry) Dispatch(key string, fn func(entries []Entry)) {
+	entries := r.Lookup(key)
+	if len(entries) > 0 {
+		fn(entries)
+	}
+}
+
+// Send sends the message to each of the actors registered by the key. It tries all of them even if sending to some
+// of them fails.
+func (r *Registry) Send(key string, msg interface{}) error {
+	var failed int
+	var firstErr error
+	for _, e := range r.Lookup(key) {
+		err := goactor.Send(e.PID, msg)
+		if err != nil {
+			failed++
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	if firstErr != nil {
+		return fmt.Errorf("registry: send failed for %d actor(s): %w", failed, firstErr)
+	}
+	return nil
+}
+
+// RegisterName registers the actor by the name with no value. It makes the registry a process.Registry.
+func (r *Registry) RegisterName(name string, pid *p.PID) error {
+	return r.Register(name, pid, nil)
+}
+
+// UnregisterName removes the actor's registration of the name.
+func (r *Registry) UnregisterName(name string, pid *p.PID) {
+	_ = r.Unregister(name, pid)
+}
+
+// WhereIsName returns the actor registered by the name. For duplicate keys, it's the first one registered.
+func (r *Registry) WhereIsName(name string) (*p.PID, bool) {
+	entries := r.Lookup(name)
+	if len(entries) == 0 {
+		return nil, false
+	}
+	return entries[0].PID, true
+}
+
+// Via returns a via name by which the actor registered by the name can be addressed, e.g. by goactor.SendVia.
+func (r *Registry) Via(name string) process.Via {
+	return process.Via{Registry: r, Name: name}
+}
+
+// Close stops the registry's watcher. The registered actors are unwatched, though their registrations are kept, so
+// they're looked up as before while Register fails with ErrClosed. Closing more than once is a no-op.
+func (r *Registry) Close() {
+	r.refsMu.Lock()
+	defer r.refsMu.Unlock()
+	if r.closed {
+		return
+	}
+	r.closed = true
+	for ref := range r.refs {
+		_ = r.watcher.Unwatch(ref)
+	}
+	r.refs = make(map[sysmsg.MonitorRef]registration)
+	r.watcher.Close()
+}
+
+// onDown is called by the watcher when a registered actor exits.
+func (r *Registry) onDown(down sysmsg.Down) {
+	r.refsMu.Lock()
+	reg, ok := r.refs[down.Ref]
+	delete(r.refs, down.Ref)
+	r.refsMu.Unlock()
+	if !ok {
+		return
+	}
+
+	part := r.partition(reg.key)
+	part.Lock()
+	defer part.Unlock()
+	for i, e := range part.entries[reg.key] {
+		if e.ref == down.Ref {
+			part.remove(reg.key, i)
+			return
+		}
+	}
+}
+
+func (r *Registry) partition(key string) *partition {
+	if len(r.partitions) == 1 {
+		return r.partitions[0]
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return r.partitions[h.Sum32()%uint32(len(r.partitions))]
+}
+
+// remove removes the i-th entry of the key. must be called with the write lock held.
+func (part *partition) remove(key string, i int) {
+	entries := part.entries[key]
+	entries = append(entries[:i], entries[i+1:]...)
+	if len(entries) == 0 {
+		delete(part.entries, key)
+		return
+	}
+	part.entries[key] = entries
+}
+
+func find(entries []*entry, pid *p.PID) int {
+	for i, e := range entries {
+		if p.Equal(e.PID, pid) {
+			return i
+		}
+	}
+	return -1
+}
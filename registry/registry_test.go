@@ -0,0 +1,189 @@
+package registry
+
+import (
+	"fmt"
+	"github.com/hedisam/goactor"
+	p "github.com/hedisam/goactor/pid"
+	"github.com/hedisam/goactor/supervisor"
+	"github.com/hedisam/goactor/supervisor/option"
+	"github.com/hedisam/goactor/supervisor/spec"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRegistry_Unique(t *testing.T) {
+	r := New(Unique, 4)
+	defer r.Close()
+	actor1, dispose1 := goactor.NewParentActor(nil)
+	defer dispose1()
+	actor2, dispose2 := goactor.NewParentActor(nil)
+	defer dispose2()
+
+	assert.Equal(t, ErrNilPID, r.Register("key", nil, nil))
+	if !assert.Nil(t, r.Register("key", actor1.Self(), "value")) {return}
+	assert.Equal(t, ErrAlreadyRegistered, r.Register("key", actor2.Self(), nil))
+	assert.Equal(t, ErrAlreadyRegistered, r.Register("key", actor1.Self(), nil))
+
+	assert.Equal(t, []Entry{{PID: actor1.Self(), Value: "value"}}, r.Lookup("key"))
+	assert.Equal(t, []string{"key"}, r.Keys(actor1.Self()))
+	assert.Empty(t, r.Keys(actor2.Self()))
+
+	assert.Equal(t, ErrNotRegistered, r.Unregister("key", actor2.Self()))
+	assert.Nil(t, r.Unregister("key", actor1.Self()))
+	assert.Empty(t, r.Lookup("key"))
+	assert.Nil(t, r.Register("key", actor2.Self(), nil))
+}
+
+func TestRegistry_Duplicate(t *testing.T) {
+	r := New(Duplicate, 4)
+	defer r.Close()
+	actor1, dispose1 := goactor.NewParentActor(nil)
+	defer dispose1()
+	actor2, dispose2 := goactor.NewParentActor(nil)
+	defer dispose2()
+
+	if !assert.Nil(t, r.Register("topic", actor1.Self(), 1)) {return}
+	if !assert.Nil(t, r.Register("topic", actor2.Self(), 2)) {return}
+	assert.Equal(t, ErrAlreadyRegistered, r.Register("topic", actor1.Self(), 1))
+	assert.Equal(t, 2, r.Count())
+
+	// the entries are in the order they were registered
+	assert.Equal(t, []Entry{{PID: actor1.Self(), Value: 1}, {PID: actor2.Self(), Value: 2}}, r.Lookup("topic"))
+
+	err := r.UpdateValue("topic", actor2.Self(), func(value interface{}) interface{} {
+		return value.(int) * 10
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 20, r.Lookup("topic")[1].Value)
+	err = r.UpdateValue("other", actor2.Self(), func(value interface{}) interface{} {return value})
+	assert.Equal(t, ErrNotRegistered, err)
+
+	var dispatched []Entry
+	r.Dispatch("topic", func(entries []Entry) {
+		dispatched = entries
+	})
+	assert.Len(t, dispatched, 2)
+
+	if !assert.Nil(t, r.Send("topic", "hello")) {return}
+	for _, actor := range []*goactor.Actor{actor1, actor2} {
+		err := actor.ReceiveWithTimeout(time.Second, func(message interface{}) (loop bool) {
+			assert.Equal(t, "hello", message)
+			return false
+		})
+		assert.Nil(t, err)
+	}
+}
+
+func TestRegistry_AutoUnregister(t *testing.T) {
+	r := New(Duplicate, 4)
+	defer r.Close()
+	actor, dispose := goactor.NewParentActor(nil)
+	other, disposeOther := goactor.NewParentActor(nil)
+	defer disposeOther()
+
+	for i := 0; i < 10; i++ {
+		if !assert.Nil(t, r.Register(fmt.Sprintf("key-%d", i), actor.Self(), nil)) {return}
+	}
+	if !assert.Nil(t, r.Register("key-0", other.Self(), nil)) {return}
+	assert.Equal(t, 11, r.Count())
+
+	// the registrations of an actor are removed once it exits
+	dispose()
+	assert.Eventually(t, func() bool {
+		return r.Count() == 1
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, []Entry{{PID: other.Self()}}, r.Lookup("key-0"))
+	assert.Empty(t, r.Keys(actor.Self()))
+}
+
+func TestRegistry_Concurrent(t *testing.T) {
+	r := New(Unique, 8)
+	defer r.Close()
+	actor, dispose := goactor.NewParentActor(nil)
+	defer dispose()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := fmt.Sprintf("key-%d", i)
+			assert.Nil(t, r.Register(key, actor.Self(), i))
+			assert.Len(t, r.Lookup(key), 1)
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, 100, r.Count())
+	assert.Len(t, r.Keys(actor.Self()), 100)
+}
+
+func TestVia(t *testing.T) {
+	r := New(Unique, 1)
+	defer r.Close()
+	actor, dispose := goactor.NewParentActor(nil)
+	defer dispose()
+
+	assert.Equal(t, goactor.ErrSendNameNotFound, goactor.SendVia(r.Via("via"), "hello"))
+	if !assert.Nil(t, r.RegisterName("via", actor.Self())) {return}
+	pid, ok := r.Via("via").WhereIs()
+	assert.True(t, ok)
+	assert.True(t, p.Equal(actor.Self(), pid))
+
+	if !assert.Nil(t, goactor.SendVia(r.Via("via"), "hello")) {return}
+	err := actor.ReceiveWithTimeout(time.Second, func(message interface{}) (loop bool) {
+		assert.Equal(t, "hello", message)
+		return false
+	})
+	assert.Nil(t, err)
+
+	r.UnregisterName("via", actor.Self())
+	_, ok = r.WhereIsName("via")
+	assert.False(t, ok)
+}
+
+func TestVia_ChildSpec(t *testing.T) {
+	r := New(Unique, 1)
+	defer r.Close()
+	name := "registry via child"
+	received := make(chan interface{}, 1)
+	worker := spec.NewWorkerSpec(name, spec.RestartAlways, func(actor *goactor.Actor) {
+		_ = actor.Receive(func(message interface{}) (loop bool) {
+			received <- message
+			return true
+		})
+	}).SetVia(r)
+
+	sup, err := supervisor.Start(option.OneForOneStrategyOption(), worker)
+	if !assert.Nil(t, err) {return}
+	if !assert.Nil(t, goactor.SendVia(r.Via(name), "hello")) {return}
+	assert.Equal(t, "hello", <-received)
+
+	// the restarted child is registered by its name again
+	pid, _ := r.WhereIsName(name)
+	assert.Nil(t, sup.TerminateChild(name, time.Second))
+	assert.Nil(t, sup.RestartChild(name, time.Second))
+	assert.Eventually(t, func() bool {
+		restarted, ok := r.WhereIsName(name)
+		return ok && !p.Equal(restarted, pid)
+	}, time.Second, 5*time.Millisecond)
+	assert.Nil(t, goactor.SendVia(r.Via(name), "again"))
+	assert.Equal(t, "again", <-received)
+}
+
+func TestRegistry_Close(t *testing.T) {
+	r := New(Unique, 1)
+	actor, dispose := goactor.NewParentActor(nil)
+	defer dispose()
+
+	if !assert.Nil(t, r.Register("key", actor.Self(), "value")) {return}
+	r.Close()
+	r.Close()
+
+	// the registrations are kept, though no more actors can register
+	assert.Equal(t, []Entry{{PID: actor.Self(), Value: "value"}}, r.Lookup("key"))
+	assert.Equal(t, ErrClosed, r.Register("other", actor.Self(), nil))
+	assert.Nil(t, r.Unregister("key", actor.Self()))
+	assert.Empty(t, r.Lookup("key"))
+}
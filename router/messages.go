@@ -0,0 +1,33 @@
+package router
+
+import p "github.com/hedisam/goactor/pid"
+
+type request interface {
+	withRequester(pid *p.PID) interface{}
+}
+
+type resizeRequest struct {
+	size      int
+	requester *p.PID
+}
+
+func (req resizeRequest) withRequester(pid *p.PID) interface{} {
+	req.requester = pid
+	return req
+}
+
+type routeesRequest struct {
+	requester *p.PID
+}
+
+func (req routeesRequest) withRequester(pid *p.PID) interface{} {
+	req.requester = pid
+	return req
+}
+
+type stopRequest struct{}
+
+type response struct {
+	err     error
+	routees []*p.PID
+}
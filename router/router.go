@@ -0,0 +1,353 @@
+package router
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/hedisam/goactor"
+	"github.com/hedisam/goactor/internal/intlpid"
+	"github.com/hedisam/goactor/internal/pidconv"
+	p "github.com/hedisam/goactor/pid"
+	"github.com/hedisam/goactor/process"
+	"github.com/hedisam/goactor/reason"
+	"github.com/hedisam/goactor/supervisor"
+	"github.com/hedisam/goactor/supervisor/option"
+	"github.com/hedisam/goactor/supervisor/spec"
+	"github.com/hedisam/goactor/supervisor/supref"
+	"github.com/hedisam/goactor/sysmsg"
+	"log"
+	"time"
+)
+
+// supervisorTimeout is how long the router waits for its routees' supervisor to answer a request.
+const supervisorTimeout = 5 * time.Second
+
+var ErrNilStrategy = fmt.Errorf("router: strategy is nil")
+var ErrInvalidPoolSize = fmt.Errorf("router: pool size must be greater than zero")
+var ErrNotPool = fmt.Errorf("router: only pool routers can be resized")
+var ErrUnknownResponse = fmt.Errorf("router: unknown response")
+
+// Router is a reference to a router actor. Messages sent to the router's pid get routed to its routees based on the
+// router's Strategy.
+type Router struct {
+	pid *p.PID
+}
+
+// PID returns the router's pid. Use it to send messages to the routees.
+func (r *Router) PID() *p.PID {
+	return r.pid
+}
+
+// Resize grows or shrinks a pool router to the given size. Shrinking stops the most recently started routees.
+func (r *Router) Resize(size int, timeout time.Duration) error {
+	if size < 1 {
+		return ErrInvalidPoolSize
+	}
+	resp, err := r.request(resizeRequest{size: size}, timeout)
+	if err != nil {
+		return err
+	}
+	return resp.err
+}
+
+// Routees returns the pid of the routees that are currently running.
+func (r *Router) Routees(timeout time.Duration) ([]*p.PID, error) {
+	resp, err := r.request(routeesRequest{}, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return resp.routees, resp.err
+}
+
+// Stop stops the router. The routees of a pool router are stopped as well, while a group router's routees keep
+// running.
+func (r *Router) Stop() error {
+	return goactor.Send(r.pid, stopRequest{})
+}
+
+func (r *Router) request(req request, timeout time.Duration) (*response, error) {
+	future := goactor.NewFutureActor()
+	err := goactor.Send(r.pid, req.withRequester(future.Self()))
+	if err != nil {
+		return nil, fmt.Errorf("router: couldn't deliver request: %w", err)
+	}
+
+	var resp *response
+	var respErr error
+	err = future.ReceiveWithTimeout(timeout, func(message interface{}) (loop bool) {
+		if res, ok := message.(response); ok {
+			resp = &res
+			return false
+		}
+		respErr = fmt.Errorf("%w: %v", ErrUnknownResponse, message)
+		return false
+	})
+	if err != nil {
+		return nil, fmt.Errorf("router: request failed: %w", err)
+	}
+	if respErr != nil {
+		return nil, respErr
+	}
+	return resp, nil
+}
+
+// NewPool spawns a router along with size routees running the given ActorFunc. The routees are supervised and get
+// restarted whenever they exit.
+func NewPool(size int, strategy Strategy, fn goactor.ActorFunc, mailboxBuilder goactor.MailboxBuilderFunc) (*Router, error) {
+	if strategy == nil {
+		return nil, ErrNilStrategy
+	}
+	if size < 1 {
+		return nil, ErrInvalidPoolSize
+	}
+
+	r := &router{
+		strategy: strategy,
+		prefix:   "router-" + uuid.New().String() + "-routee-",
+		pids:     make(map[string]poolRoutee),
+		poolRefs: make(map[sysmsg.MonitorRef]string),
+		spec: func(name string) spec.WorkerSpec {
+			return spec.NewWorkerSpec(name, spec.RestartAlways, fn).SetMailboxBuilder(mailboxBuilder)
+		},
+	}
+	specs := make([]spec.Spec, 0, size)
+	for i := 0; i < size; i++ {
+		name := r.nextName()
+		r.names = append(r.names, name)
+		specs = append(specs, r.spec(name))
+	}
+	sup, err := supervisor.Start(option.OneForOneStrategyOption(), specs...)
+	if err != nil {
+		return nil, fmt.Errorf("router: failed to start the routees: %w", err)
+	}
+	r.sup = sup
+
+	return &Router{pid: goactor.Spawn(r.run, nil)}, nil
+}
+
+// NewGroup spawns a router which routes the messages to the given, already running, actors. A routee gets removed
+// from the group once it exits.
+func NewGroup(strategy Strategy, routees ...*p.PID) (*Router, error) {
+	if strategy == nil {
+		return nil, ErrNilStrategy
+	}
+	r := &router{
+		strategy: strategy,
+		group:    make(map[sysmsg.MonitorRef]Routee),
+		members:  routees,
+	}
+	return &Router{pid: goactor.Spawn(r.run, nil)}, nil
+}
+
+// poolRoutee is the cached pid of a pool router's routee, along with the reference of the router's monitor on it.
+type poolRoutee struct {
+	pid *p.PID
+	ref sysmsg.MonitorRef
+}
+
+type router struct {
+	actor    *goactor.Actor
+	strategy Strategy
+
+	// sup supervises a pool router's routees. each routee is a child of sup which is registered in the process
+	// registry by its name, so the router can find the routees' current pid even after they get restarted.
+	sup       *supref.SupRef
+	supRef    sysmsg.MonitorRef
+	spec      func(name string) spec.WorkerSpec
+	prefix    string
+	names     []string
+	nameIndex int
+	// pids caches the pid of the routees by their names, so routing a message doesn't look them up in the registry.
+	// a routee's pid is monitored while it's cached, and it's dropped once the routee exits, to be looked up again
+	// once the routee is restarted. poolRefs maps the monitors' references to the routees' names.
+	pids     map[string]poolRoutee
+	poolRefs map[sysmsg.MonitorRef]string
+
+	// group keeps a group router's routees by the reference of their monitors and order keeps the order they were
+	// given in. members holds the given pids until the router starts monitoring them.
+	group   map[sysmsg.MonitorRef]Routee
+	order   []sysmsg.MonitorRef
+	members []*p.PID
+}
+
+func (r *router) run(actor *goactor.Actor) {
+	r.actor = actor
+	defer r.stopRoutees()
+
+	if r.sup != nil {
+		ref, err := actor.Monitor(r.sup.PID())
+		if err != nil {
+			log.Printf("[!] router %s failed to monitor its routees' supervisor: %v\n", actor.Self().ID(), err)
+			return
+		}
+		r.supRef = ref
+	}
+	for _, pid := range r.members {
+		ref, err := actor.Monitor(pid)
+		if err != nil {
+			log.Printf("[!] router %s failed to monitor routee %s: %v\n", actor.Self().ID(), pid, err)
+			continue
+		}
+		r.group[ref] = Routee{Key: pid.ID(), PID: pid}
+		r.order = append(r.order, ref)
+	}
+	r.members = nil
+
+	_ = actor.Receive(r.handle)
+}
+
+func (r *router) handle(message interface{}) (loop bool) {
+	switch msg := message.(type) {
+	case resizeRequest:
+		if r.sup == nil {
+			r.reply(msg.requester, response{err: ErrNotPool})
+			return true
+		}
+		r.reply(msg.requester, response{err: r.resize(msg.size)})
+	case routeesRequest:
+		routees := r.routees()
+		pids := make([]*p.PID, 0, len(routees))
+		for _, routee := range routees {
+			pids = append(pids, routee.PID)
+		}
+		r.reply(msg.requester, response{routees: pids})
+	case stopRequest:
+		return false
+	case sysmsg.Down:
+		if r.sup != nil && msg.Ref == r.supRef {
+			log.Printf("[!] router %s: routees' supervisor has exited, reason: %v\n", r.actor.Self().ID(), msg.Reason)
+			r.sup = nil
+			return false
+		}
+		if name, ok := r.poolRefs[msg.Ref]; ok {
+			delete(r.poolRefs, msg.Ref)
+			delete(r.pids, name)
+			return true
+		}
+		r.removeMember(msg.Ref)
+	default:
+		r.route(message)
+	}
+	return true
+}
+
+func (r *router) route(msg interface{}) {
+	for _, routee := range r.strategy.Route(msg, r.routees()) {
+		err := goactor.Send(routee.PID, msg)
+		if err != nil {
+			log.Printf("[!] router %s failed to route a message to %s: %v\n", r.actor.Self().ID(), routee.PID, err)
+		}
+	}
+}
+
+// routees returns the routees which are currently running.
+func (r *router) routees() []Routee {
+	if r.sup == nil {
+		routees := make([]Routee, 0, len(r.order))
+		for _, ref := range r.order {
+			routees = append(routees, r.group[ref])
+		}
+		return routees
+	}
+
+	routees := make([]Routee, 0, len(r.names))
+	for _, name := range r.names {
+		pid, ok := r.poolPID(name)
+		if !ok {
+			continue
+		}
+		routees = append(routees, Routee{Key: name, PID: pid})
+	}
+	return routees
+}
+
+// poolPID returns the cached pid of the pool's routee, or looks it up by its name and caches it if it's not cached.
+func (r *router) poolPID(name string) (*p.PID, bool) {
+	if routee, ok := r.pids[name]; ok {
+		return routee.pid, true
+	}
+	// a routee which is being restarted is not registered for a moment.
+	pid, ok := process.WhereIs(name)
+	if !ok {
+		return nil, false
+	}
+	ref, err := r.actor.Monitor(pid)
+	if err != nil {
+		// it's looked up again the next time.
+		log.Printf("[!] router %s failed to monitor routee %s: %v\n", r.actor.Self().ID(), pid, err)
+		return pid, true
+	}
+	r.pids[name] = poolRoutee{pid: pid, ref: ref}
+	r.poolRefs[ref] = name
+	return pid, true
+}
+
+// forget drops the cached pid of the pool's routee, which is no longer a routee.
+func (r *router) forget(name string) {
+	routee, ok := r.pids[name]
+	if !ok {
+		return
+	}
+	_ = r.actor.Demonitor(routee.ref)
+	delete(r.poolRefs, routee.ref)
+	delete(r.pids, name)
+}
+
+func (r *router) resize(size int) error {
+	for len(r.names) < size {
+		name := r.nextName()
+		err := r.sup.StartNewChild(r.spec(name), supervisorTimeout)
+		if err != nil {
+			return fmt.Errorf("router: failed to start a new routee: %w", err)
+		}
+		r.names = append(r.names, name)
+	}
+	for len(r.names) > size {
+		name := r.names[len(r.names)-1]
+		err := r.sup.TerminateChild(name, supervisorTimeout)
+		if err != nil {
+			return fmt.Errorf("router: failed to stop routee %s: %w", name, err)
+		}
+		err = r.sup.DeleteChild(name, supervisorTimeout)
+		if err != nil {
+			return fmt.Errorf("router: failed to delete routee %s: %w", name, err)
+		}
+		r.forget(name)
+		r.names = r.names[:len(r.names)-1]
+	}
+	return nil
+}
+
+func (r *router) removeMember(ref sysmsg.MonitorRef) {
+	delete(r.group, ref)
+	for i := range r.order {
+		if r.order[i] == ref {
+			r.order = append(r.order[:i], r.order[i+1:]...)
+			return
+		}
+	}
+}
+
+func (r *router) nextName() string {
+	name := fmt.Sprintf("%s%d", r.prefix, r.nameIndex)
+	r.nameIndex++
+	return name
+}
+
+// stopRoutees shuts down the routees' supervisor of a pool router.
+func (r *router) stopRoutees() {
+	if r.sup == nil {
+		return
+	}
+	cmd := sysmsg.NewShutdownCMD(r.actor.Self(), reason.Shutdown, nil)
+	err := intlpid.SendSystemMessage(pidconv.Internal(r.sup.PID()), cmd)
+	if err != nil {
+		log.Printf("[!] router %s failed to stop its routees: %v\n", r.actor.Self().ID(), err)
+	}
+}
+
+func (r *router) reply(requester *p.PID, resp response) {
+	err := goactor.Send(requester, resp)
+	if err != nil {
+		log.Printf("[!] router %s couldn't send back a response: %v\n", r.actor.Self().ID(), err)
+	}
+}
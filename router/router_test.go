@@ -0,0 +1,182 @@
+package router
+
+import (
+	"github.com/hedisam/goactor"
+	p "github.com/hedisam/goactor/pid"
+	"github.com/hedisam/goactor/process"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type echo struct {
+	replyTo *p.PID
+}
+
+type crash struct{}
+
+// echoRoutee replies back with its own pid to echo messages and panics on crash messages.
+func echoRoutee(actor *goactor.Actor) {
+	_ = actor.Receive(func(message interface{}) (loop bool) {
+		switch msg := message.(type) {
+		case echo:
+			_ = goactor.Send(msg.replyTo, actor.Self())
+		case crash:
+			panic("crashing on purpose")
+		}
+		return true
+	})
+}
+
+// collect receives n echo replies and counts them per routee's id.
+func collect(t *testing.T, actor *goactor.Actor, n int) map[string]int {
+	counts := make(map[string]int)
+	var received int
+	err := actor.ReceiveWithTimeout(time.Second, func(message interface{}) (loop bool) {
+		pid, ok := message.(*p.PID)
+		if !assert.True(t, ok) {return false}
+		counts[pid.ID()]++
+		received++
+		return received < n
+	})
+	assert.Nil(t, err)
+	return counts
+}
+
+func TestNewPool(t *testing.T) {
+	_, err := NewPool(1, nil, echoRoutee, nil)
+	assert.Equal(t, ErrNilStrategy, err)
+	_, err = NewPool(0, RoundRobin(), echoRoutee, nil)
+	assert.Equal(t, ErrInvalidPoolSize, err)
+
+	parent, dispose := goactor.NewParentActor(nil)
+	defer dispose()
+
+	router, err := NewPool(3, RoundRobin(), echoRoutee, nil)
+	if !assert.Nil(t, err) {return}
+	defer router.Stop()
+
+	for i := 0; i < 6; i++ {
+		if !assert.Nil(t, goactor.Send(router.PID(), echo{replyTo: parent.Self()})) {return}
+	}
+	counts := collect(t, parent, 6)
+	assert.Len(t, counts, 3)
+	for _, count := range counts {
+		assert.Equal(t, 2, count)
+	}
+}
+
+func TestPool_Resize(t *testing.T) {
+	router, err := NewPool(2, Broadcast(), echoRoutee, nil)
+	if !assert.Nil(t, err) {return}
+	defer router.Stop()
+
+	if !assert.Nil(t, router.Resize(4, time.Second)) {return}
+	routees, err := router.Routees(time.Second)
+	if !assert.Nil(t, err) {return}
+	assert.Len(t, routees, 4)
+
+	if !assert.Nil(t, router.Resize(1, time.Second)) {return}
+	routees, err = router.Routees(time.Second)
+	if !assert.Nil(t, err) {return}
+	assert.Len(t, routees, 1)
+
+	assert.Equal(t, ErrInvalidPoolSize, router.Resize(0, time.Second))
+
+	parent, dispose := goactor.NewParentActor(nil)
+	defer dispose()
+	if !assert.Nil(t, goactor.Send(router.PID(), echo{replyTo: parent.Self()})) {return}
+	counts := collect(t, parent, 1)
+	assert.Equal(t, map[string]int{routees[0].ID(): 1}, counts)
+}
+
+func TestPool_RestartedRoutee(t *testing.T) {
+	router, err := NewPool(1, RoundRobin(), echoRoutee, nil)
+	if !assert.Nil(t, err) {return}
+	defer router.Stop()
+
+	routees, err := router.Routees(time.Second)
+	if !assert.Nil(t, err) {return}
+	if !assert.Len(t, routees, 1) {return}
+
+	if !assert.Nil(t, goactor.Send(router.PID(), crash{})) {return}
+
+	// wait for the supervisor to restart the routee
+	var restarted []*p.PID
+	assert.Eventually(t, func() bool {
+		restarted, err = router.Routees(time.Second)
+		return err == nil && len(restarted) == 1 && !p.Equal(restarted[0], routees[0])
+	}, time.Second, 10*time.Millisecond)
+
+	parent, dispose := goactor.NewParentActor(nil)
+	defer dispose()
+	if !assert.Nil(t, goactor.Send(router.PID(), echo{replyTo: parent.Self()})) {return}
+	counts := collect(t, parent, 1)
+	assert.Equal(t, map[string]int{restarted[0].ID(): 1}, counts)
+}
+
+func TestPool_CachedRoutees(t *testing.T) {
+	router, err := NewPool(1, RoundRobin(), echoRoutee, nil)
+	if !assert.Nil(t, err) {return}
+	defer router.Stop()
+
+	routees, err := router.Routees(time.Second)
+	if !assert.Nil(t, err) {return}
+	if !assert.Len(t, routees, 1) {return}
+
+	// the routee's pid is cached, so it's not looked up in the registry for each message
+	name, ok := process.NameOf(routees[0])
+	if !assert.True(t, ok) {return}
+	process.Unregister(name)
+
+	parent, dispose := goactor.NewParentActor(nil)
+	defer dispose()
+	if !assert.Nil(t, goactor.Send(router.PID(), echo{replyTo: parent.Self()})) {return}
+	counts := collect(t, parent, 1)
+	assert.Equal(t, map[string]int{routees[0].ID(): 1}, counts)
+}
+
+func TestNewGroup(t *testing.T) {
+	parent, dispose := goactor.NewParentActor(nil)
+	defer dispose()
+
+	routee1 := goactor.Spawn(echoRoutee, nil)
+	routee2 := goactor.Spawn(echoRoutee, nil)
+	router, err := NewGroup(Broadcast(), routee1, routee2)
+	if !assert.Nil(t, err) {return}
+	defer router.Stop()
+
+	assert.Equal(t, ErrNotPool, router.Resize(3, time.Second))
+
+	if !assert.Nil(t, goactor.Send(router.PID(), echo{replyTo: parent.Self()})) {return}
+	counts := collect(t, parent, 2)
+	assert.Equal(t, map[string]int{routee1.ID(): 1, routee2.ID(): 1}, counts)
+
+	// routees get removed from the group once they exit
+	if !assert.Nil(t, goactor.Send(routee1, crash{})) {return}
+	assert.Eventually(t, func() bool {
+		routees, err := router.Routees(time.Second)
+		return err == nil && len(routees) == 1 && p.Equal(routees[0], routee2)
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestRouter_Stop(t *testing.T) {
+	router, err := NewPool(2, RoundRobin(), echoRoutee, nil)
+	if !assert.Nil(t, err) {return}
+	routees, err := router.Routees(time.Second)
+	if !assert.Nil(t, err) {return}
+
+	if !assert.Nil(t, router.Stop()) {return}
+
+	// the routees are stopped along with the router
+	assert.Eventually(t, func() bool {
+		for _, routee := range routees {
+			if goactor.Send(routee, echo{}) == nil {
+				return false
+			}
+		}
+		return true
+	}, time.Second, 10*time.Millisecond)
+	_, err = router.Routees(100 * time.Millisecond)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,174 @@
+package router
+
+import (
+	"github.com/hedisam/goactor/internal/intlpid"
+	"github.com/hedisam/goactor/internal/pidconv"
+	p "github.com/hedisam/goactor/pid"
+	"hash/fnv"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// virtualNodes is the number of points each routee gets on the consistent hashing ring.
+const virtualNodes = 100
+
+// Routee is an actor which the router can route messages to.
+type Routee struct {
+	// Key identifies the routee. It stays the same when a pool's routee gets restarted by its supervisor.
+	Key string
+	PID *p.PID
+}
+
+// Strategy picks the routees that should receive a message. A router calls its strategy from its own goroutine only,
+// so strategies don't need to be safe for concurrent use, but a strategy must not be shared between routers.
+type Strategy interface {
+	Route(msg interface{}, routees []Routee) []Routee
+}
+
+type roundRobin struct {
+	next int
+}
+
+// RoundRobin routes the messages to the routees in turn.
+func RoundRobin() Strategy {
+	return &roundRobin{}
+}
+
+func (s *roundRobin) Route(_ interface{}, routees []Routee) []Routee {
+	if len(routees) == 0 {
+		return nil
+	}
+	s.next = s.next % len(routees)
+	routee := routees[s.next]
+	s.next++
+	return []Routee{routee}
+}
+
+type random struct{}
+
+// Random routes each message to a randomly picked routee.
+func Random() Strategy {
+	return random{}
+}
+
+func (random) Route(_ interface{}, routees []Routee) []Routee {
+	if len(routees) == 0 {
+		return nil
+	}
+	return []Routee{routees[rand.Intn(len(routees))]}
+}
+
+type broadcast struct{}
+
+// Broadcast routes each message to all of the routees.
+func Broadcast() Strategy {
+	return broadcast{}
+}
+
+func (broadcast) Route(_ interface{}, routees []Routee) []Routee {
+	return routees
+}
+
+type smallestMailbox struct{}
+
+// SmallestMailbox routes each message to the routee with the least number of pending messages. Routees whose mailbox
+// can not report its length are only picked if none of the mailboxes can.
+func SmallestMailbox() Strategy {
+	return smallestMailbox{}
+}
+
+func (smallestMailbox) Route(_ interface{}, routees []Routee) []Routee {
+	if len(routees) == 0 {
+		return nil
+	}
+	smallest := -1
+	var smallestLen int
+	for i, routee := range routees {
+		n, ok := intlpid.MailboxLen(pidconv.Internal(routee.PID))
+		if !ok {
+			continue
+		}
+		if smallest == -1 || n < smallestLen {
+			smallest, smallestLen = i, n
+		}
+	}
+	if smallest == -1 {
+		smallest = 0
+	}
+	return []Routee{routees[smallest]}
+}
+
+type consistentHash struct {
+	key    func(msg interface{}) string
+	ring   []uint32
+	owners map[uint32]string
+	// routees is the signature of the routees the ring was built for.
+	routees string
+}
+
+// ConsistentHash routes the messages with the same key, as returned by the key func, to the same routee. Adding or
+// removing a routee only moves the keys of a small portion of the routees.
+func ConsistentHash(key func(msg interface{}) string) Strategy {
+	return &consistentHash{key: key}
+}
+
+func (s *consistentHash) Route(msg interface{}, routees []Routee) []Routee {
+	if len(routees) == 0 {
+		return nil
+	}
+	s.build(routees)
+
+	h := hash(s.key(msg))
+	i := sort.Search(len(s.ring), func(i int) bool {
+		return s.ring[i] >= h
+	})
+	if i == len(s.ring) {
+		// wrap around the ring
+		i = 0
+	}
+	owner := s.owners[s.ring[i]]
+	for _, routee := range routees {
+		if routee.Key == owner {
+			return []Routee{routee}
+		}
+	}
+	return nil
+}
+
+// build rebuilds the ring if the routees have changed since the last time.
+func (s *consistentHash) build(routees []Routee) {
+	keys := make([]string, 0, len(routees))
+	for _, routee := range routees {
+		keys = append(keys, routee.Key)
+	}
+	sort.Strings(keys)
+	signature := strings.Join(keys, "\x00")
+	if signature == s.routees && s.ring != nil {
+		return
+	}
+
+	s.routees = signature
+	s.ring = make([]uint32, 0, len(keys)*virtualNodes)
+	s.owners = make(map[uint32]string, len(keys)*virtualNodes)
+	for _, key := range keys {
+		for i := 0; i < virtualNodes; i++ {
+			h := hash(key + "#" + strconv.Itoa(i))
+			if _, taken := s.owners[h]; taken {
+				continue
+			}
+			s.owners[h] = key
+			s.ring = append(s.ring, h)
+		}
+	}
+	sort.Slice(s.ring, func(i, j int) bool {
+		return s.ring[i] < s.ring[j]
+	})
+}
+
+func hash(key string) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return h.Sum32()
+}
@@ -0,0 +1,107 @@
+package router
+
+import (
+	"fmt"
+	"github.com/hedisam/goactor"
+	"github.com/hedisam/goactor/internal/intlpid"
+	"github.com/hedisam/goactor/internal/pidconv"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func mockRoutees(n int) []Routee {
+	routees := make([]Routee, 0, n)
+	for i := 0; i < n; i++ {
+		routees = append(routees, Routee{
+			Key: fmt.Sprintf("routee-%d", i),
+			PID: pidconv.ToPID(intlpid.NewMockInternalPID()),
+		})
+	}
+	return routees
+}
+
+func TestRoundRobin(t *testing.T) {
+	routees := mockRoutees(3)
+	strategy := RoundRobin()
+
+	for i := 0; i < 7; i++ {
+		picked := strategy.Route(i, routees)
+		if !assert.Len(t, picked, 1) {return}
+		assert.Equal(t, routees[i%3], picked[0])
+	}
+
+	// the routees could shrink in between
+	picked := strategy.Route("msg", routees[:1])
+	if !assert.Len(t, picked, 1) {return}
+	assert.Equal(t, routees[0], picked[0])
+
+	assert.Empty(t, strategy.Route("msg", nil))
+}
+
+func TestRandom(t *testing.T) {
+	routees := mockRoutees(3)
+	strategy := Random()
+
+	for i := 0; i < 10; i++ {
+		picked := strategy.Route(i, routees)
+		if !assert.Len(t, picked, 1) {return}
+		assert.Contains(t, routees, picked[0])
+	}
+	assert.Empty(t, strategy.Route("msg", nil))
+}
+
+func TestBroadcast(t *testing.T) {
+	routees := mockRoutees(3)
+	assert.Equal(t, routees, Broadcast().Route("msg", routees))
+	assert.Empty(t, Broadcast().Route("msg", nil))
+}
+
+func TestConsistentHash(t *testing.T) {
+	routees := mockRoutees(5)
+	strategy := ConsistentHash(func(msg interface{}) string {
+		return msg.(string)
+	})
+
+	owners := make(map[string]Routee)
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		picked := strategy.Route(key, routees)
+		if !assert.Len(t, picked, 1) {return}
+		owners[key] = picked[0]
+
+		// the same key is always routed to the same routee
+		assert.Equal(t, picked, strategy.Route(key, routees))
+	}
+
+	// removing a routee only moves its own keys
+	removed := routees[2]
+	remaining := append(append([]Routee{}, routees[:2]...), routees[3:]...)
+	for key, owner := range owners {
+		picked := strategy.Route(key, remaining)
+		if !assert.Len(t, picked, 1) {return}
+		if owner != removed {
+			assert.Equal(t, owner, picked[0])
+		}
+	}
+	assert.Empty(t, strategy.Route("key", nil))
+}
+
+func TestSmallestMailbox(t *testing.T) {
+	var routees []Routee
+	for i := 0; i < 3; i++ {
+		actor, dispose := goactor.NewParentActor(nil)
+		defer dispose()
+		routees = append(routees, Routee{Key: actor.Self().ID(), PID: actor.Self()})
+	}
+	// routee #0 has 2 pending messages, #1 has 1 and #2 has 3
+	for i, pending := range []int{2, 1, 3} {
+		for j := 0; j < pending; j++ {
+			if !assert.Nil(t, goactor.Send(routees[i].PID, j)) {return}
+		}
+	}
+
+	picked := SmallestMailbox().Route("msg", routees)
+	if !assert.Len(t, picked, 1) {return}
+	assert.Equal(t, routees[1], picked[0])
+	assert.Empty(t, SmallestMailbox().Route("msg", nil))
+}
@@ -0,0 +1,128 @@
+package sched
+
+import (
+	"github.com/hedisam/goactor/mailbox"
+	"time"
+)
+
+// schedMailbox is an unbounded mailbox whose receiving goroutine is driven by the scheduler. Instead of blocking, the
+// receiver gives the control back to the scheduler while it waits for a message, and after it handles each message.
+type schedMailbox struct {
+	s *Scheduler
+	// all of the fields are guarded by s.mu.
+	user   []interface{}
+	sys    []interface{}
+	closed bool
+	// waiter is the goroutine waiting for a message, if any.
+	waiter *task
+}
+
+// NewMailbox returns a new mailbox whose receiving goroutine must be one driven by the scheduler, otherwise its
+// receives fail with ErrNotScheduled.
+func (s *Scheduler) NewMailbox() *schedMailbox {
+	return &schedMailbox{s: s}
+}
+
+func (m *schedMailbox) Receive(msgHandler, sysMsgHandler func(interface{}) bool) error {
+	return m.ReceiveWithTimeout(0, msgHandler, sysMsgHandler)
+}
+
+func (m *schedMailbox) ReceiveWithTimeout(timeout time.Duration, msgHandler, sysMsgHandler func(interface{}) bool) error {
+	for {
+		m.s.mu.Lock()
+		if m.closed || m.s.stopping {
+			m.s.mu.Unlock()
+			return mailbox.ErrMailboxClosed
+		}
+		if !m.s.isRunning() {
+			// parking it would park the running goroutine instead.
+			m.s.mu.Unlock()
+			return ErrNotScheduled
+		}
+
+		var msg interface{}
+		handler := sysMsgHandler
+		switch {
+		case len(m.sys) > 0:
+			msg = m.sys[0]
+			m.sys[0] = nil
+			m.sys = m.sys[1:]
+		case len(m.user) > 0:
+			msg = m.user[0]
+			m.user[0] = nil
+			m.user = m.user[1:]
+			handler = msgHandler
+		default:
+			timedOut := m.s.park(m, timeout)
+			m.s.mu.Unlock()
+			if timedOut {
+				return mailbox.ErrMailboxReceiveTimeout
+			}
+			continue
+		}
+		m.s.mu.Unlock()
+
+		if !handler(msg) {
+			// stop looping
+			return nil
+		}
+		m.s.yield()
+	}
+}
+
+func (m *schedMailbox) PushMessage(msg interface{}) error {
+	return m.push(&m.user, msg)
+}
+
+func (m *schedMailbox) PushSystemMessage(msg interface{}) error {
+	return m.push(&m.sys, msg)
+}
+
+func (m *schedMailbox) push(queue *[]interface{}, msg interface{}) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+	if m.closed {
+		return mailbox.ErrMailboxClosed
+	}
+	*queue = append(*queue, msg)
+	m.s.wake(m)
+	return nil
+}
+
+// PrependMessages puts the messages, in the same order, in front of the user messages waiting in the mailbox.
+func (m *schedMailbox) PrependMessages(messages []interface{}) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+	if m.closed {
+		return mailbox.ErrMailboxClosed
+	}
+	user := make([]interface{}, 0, len(messages)+len(m.user))
+	user = append(user, messages...)
+	m.user = append(user, m.user...)
+	return nil
+}
+
+// Len returns the number of user messages waiting in the mailbox.
+func (m *schedMailbox) Len() int {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+	return len(m.user)
+}
+
+// Disposed returns true once the mailbox has been disposed.
+func (m *schedMailbox) Disposed() bool {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+	return m.closed
+}
+
+func (m *schedMailbox) Dispose() {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+	if m.closed {
+		return
+	}
+	m.closed = true
+	m.user, m.sys = nil, nil
+	m.s.wake(m)
+}
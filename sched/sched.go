@@ -0,0 +1,305 @@
+// Package sched provides a deterministic runtime for reproducible concurrency tests.
+//
+// While Run is running, the goroutines of the actors spawned by goactor.Spawn, SpawnLink, SpawnMonitor and by
+// supervisors are driven by a scheduler which lets only one of them run at a time. Actors are switched between at
+// message boundaries: once an actor has handled a message, or when it waits for one, the scheduler picks the next
+// goroutine to run using a pseudo-random generator seeded by the run's seed. The mailboxes of the actors are replaced
+// by the scheduler's own, whose receive timeouts are measured in virtual time which only moves once every actor is
+// waiting. So running again with the same seed replays the same interleaving, and a failing one can be reproduced
+// from its seed.
+//
+// The actors must only interact by messages while they're scheduled: an actor blocking on anything else, e.g. a
+// channel or a mutex held by another actor, blocks the whole run. Only the scheduled goroutines can receive messages:
+// a receive by any other goroutine, e.g. one started by a plain go statement, fails with ErrNotScheduled.
+//
+// Cancelling the parent context of an actor or a supervisor, given by goactor.WithContext or the supervisor's
+// options, isn't deterministic: the context is watched by a plain goroutine, which sends the ShutdownCMD whenever
+// it's woken up by the Go runtime. The task package isn't supported, its computations run in plain goroutines.
+package sched
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// MaxSteps is the number of times a run can switch between goroutines before it's given up, in case the actors never
+// stop sending messages to each other.
+const MaxSteps = 1000000
+
+var ErrStepLimit = fmt.Errorf("sched: the run exceeded the step limit")
+var ErrTaskPanicked = fmt.Errorf("sched: a scheduled goroutine panicked")
+var ErrNotScheduled = fmt.Errorf("sched: the receiving goroutine isn't driven by the scheduler")
+
+var (
+	// runMu makes sure only one run happens at a time.
+	runMu     sync.Mutex
+	currentMu sync.RWMutex
+	current   *Scheduler
+)
+
+// Scheduler runs the goroutines of a deterministic run one at a time.
+type Scheduler struct {
+	seed int64
+	rand *rand.Rand
+	// control is signaled by the running goroutine once it gives the control back to the scheduler.
+	control chan struct{}
+
+	mu       sync.Mutex
+	now      time.Time
+	running  *task
+	runnable []*task
+	// parked are the goroutines waiting for a message, in the order they started waiting.
+	parked   []*task
+	stopping bool
+	panicked interface{}
+	steps    int
+}
+
+// task is a goroutine driven by the scheduler.
+type task struct {
+	resume chan struct{}
+	// goid is the id of the task's goroutine, it's set once the task starts running.
+	goid uint64
+	// mailbox is the mailbox the task is waiting for a message from, and deadline is when its wait times out, if
+	// it's not zero.
+	mailbox  *schedMailbox
+	deadline time.Time
+	timedOut bool
+}
+
+// Run runs main, and the actors it spawns, deterministically with the given seed. It returns once main has returned
+// and all of the actors are waiting for messages that no one is going to send. The waiting actors are stopped
+// before Run returns, their receives fail with mailbox.ErrMailboxClosed.
+// An error is returned if main or any other goroutine that isn't an actor panics, or if the run exceeds MaxSteps.
+func Run(seed int64, main func()) error {
+	runMu.Lock()
+	defer runMu.Unlock()
+
+	s := &Scheduler{
+		seed:    seed,
+		rand:    rand.New(rand.NewSource(seed)),
+		control: make(chan struct{}),
+		now:     time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC),
+	}
+	currentMu.Lock()
+	current = s
+	currentMu.Unlock()
+	defer func() {
+		currentMu.Lock()
+		current = nil
+		currentMu.Unlock()
+	}()
+
+	s.Go(main)
+	return s.run()
+}
+
+// Current returns the scheduler of the ongoing run, or nil if there's none.
+func Current() *Scheduler {
+	currentMu.RLock()
+	defer currentMu.RUnlock()
+	return current
+}
+
+// Go runs fn in a new goroutine, which is driven by the scheduler of the ongoing run if there's one.
+func Go(fn func()) {
+	if s := Current(); s != nil {
+		s.Go(fn)
+		return
+	}
+	go fn()
+}
+
+// Seed returns the seed of the run.
+func (s *Scheduler) Seed() int64 {
+	return s.seed
+}
+
+// Now returns the run's virtual time.
+func (s *Scheduler) Now() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.now
+}
+
+// Go runs fn in a new goroutine driven by the scheduler. The goroutine doesn't start running before the scheduler
+// picks it.
+func (s *Scheduler) Go(fn func()) {
+	s.mu.Lock()
+	t := &task{resume: make(chan struct{})}
+	s.runnable = append(s.runnable, t)
+	s.mu.Unlock()
+
+	go func() {
+		<-t.resume
+		s.mu.Lock()
+		t.goid = goid()
+		s.mu.Unlock()
+		defer s.exit()
+		fn()
+	}()
+}
+
+// run switches between the goroutines until none of them can run.
+func (s *Scheduler) run() error {
+	for {
+		s.mu.Lock()
+		if len(s.runnable) == 0 && !s.wakeTimedOut() {
+			if s.stopping || len(s.parked) == 0 {
+				s.mu.Unlock()
+				break
+			}
+			s.stop()
+		}
+		if s.steps >= MaxSteps {
+			s.mu.Unlock()
+			return fmt.Errorf("%w: %d steps with seed %d", ErrStepLimit, MaxSteps, s.seed)
+		}
+		s.steps++
+		i := s.rand.Intn(len(s.runnable))
+		t := s.runnable[i]
+		s.runnable = append(s.runnable[:i], s.runnable[i+1:]...)
+		s.running = t
+		s.mu.Unlock()
+
+		t.resume <- struct{}{}
+		<-s.control
+	}
+
+	if s.panicked != nil {
+		return fmt.Errorf("%w with seed %d: %v", ErrTaskPanicked, s.seed, s.panicked)
+	}
+	return nil
+}
+
+// stop makes the parked goroutines runnable, so their receives fail and they can return.
+func (s *Scheduler) stop() {
+	s.stopping = true
+	for _, t := range s.parked {
+		t.mailbox.waiter = nil
+		t.mailbox = nil
+		s.runnable = append(s.runnable, t)
+	}
+	s.parked = nil
+}
+
+// wakeTimedOut moves the virtual time forward to the earliest deadline of the parked goroutines, and makes the ones
+// whose wait has timed out runnable. It returns false if there's no deadline.
+func (s *Scheduler) wakeTimedOut() bool {
+	var earliest time.Time
+	for _, t := range s.parked {
+		if !t.deadline.IsZero() && (earliest.IsZero() || t.deadline.Before(earliest)) {
+			earliest = t.deadline
+		}
+	}
+	if earliest.IsZero() {
+		return false
+	}
+	s.now = earliest
+
+	parked := s.parked[:0]
+	for _, t := range s.parked {
+		if !t.deadline.IsZero() && !t.deadline.After(s.now) {
+			t.timedOut = true
+			t.mailbox.waiter = nil
+			t.mailbox = nil
+			s.runnable = append(s.runnable, t)
+			continue
+		}
+		parked = append(parked, t)
+	}
+	s.parked = parked
+	return true
+}
+
+// isRunning returns true if it's called by the running goroutine. It must be called with s.mu locked.
+func (s *Scheduler) isRunning() bool {
+	return s.running != nil && s.running.goid == goid()
+}
+
+// goid returns the id of the calling goroutine, parsed from the first line of its stack trace, which reads
+// "goroutine <id> [running]:".
+func goid() uint64 {
+	var buf [64]byte
+	n := runtime.Stack(buf[:], false)
+	fields := bytes.Fields(buf[:n])
+	if len(fields) < 2 {
+		return 0
+	}
+	id, _ := strconv.ParseUint(string(fields[1]), 10, 64)
+	return id
+}
+
+// park gives the control back to the scheduler until the running goroutine, which waits for a message from the
+// mailbox, is made runnable again by a message or by a timeout. It must be called by the running goroutine, with s.mu
+// locked.
+func (s *Scheduler) park(m *schedMailbox, timeout time.Duration) (timedOut bool) {
+	t := s.running
+	t.mailbox = m
+	if timeout > 0 {
+		t.deadline = s.now.Add(timeout)
+	}
+	m.waiter = t
+	s.parked = append(s.parked, t)
+	s.running = nil
+	s.mu.Unlock()
+
+	s.control <- struct{}{}
+	<-t.resume
+
+	s.mu.Lock()
+	timedOut = t.timedOut
+	t.timedOut = false
+	t.deadline = time.Time{}
+	return timedOut
+}
+
+// yield lets the scheduler pick the next goroutine to run, which could be the running one again.
+func (s *Scheduler) yield() {
+	s.mu.Lock()
+	if !s.isRunning() {
+		// it's not called by the goroutine the scheduler is driving
+		s.mu.Unlock()
+		return
+	}
+	t := s.running
+	s.running = nil
+	s.runnable = append(s.runnable, t)
+	s.mu.Unlock()
+
+	s.control <- struct{}{}
+	<-t.resume
+}
+
+// wake makes the goroutine waiting for a message from the mailbox runnable.
+func (s *Scheduler) wake(m *schedMailbox) {
+	t := m.waiter
+	if t == nil {
+		return
+	}
+	m.waiter = nil
+	t.mailbox = nil
+	for i, parked := range s.parked {
+		if parked == t {
+			s.parked = append(s.parked[:i], s.parked[i+1:]...)
+			break
+		}
+	}
+	s.runnable = append(s.runnable, t)
+}
+
+func (s *Scheduler) exit() {
+	r := recover()
+	s.mu.Lock()
+	if r != nil && s.panicked == nil {
+		s.panicked = r
+	}
+	s.running = nil
+	s.mu.Unlock()
+	s.control <- struct{}{}
+}
@@ -0,0 +1,189 @@
+package sched_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/hedisam/goactor"
+	"github.com/hedisam/goactor/mailbox"
+	"github.com/hedisam/goactor/sched"
+	"github.com/hedisam/goactor/supervisor"
+	"github.com/hedisam/goactor/supervisor/option"
+	"github.com/hedisam/goactor/supervisor/spec"
+	"github.com/hedisam/goactor/sysmsg"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// arrivals returns the order in which the messages of a few concurrent senders arrive, when run with the seed.
+func arrivals(t *testing.T, seed int64) []interface{} {
+	var order []interface{}
+	err := sched.Run(seed, func() {
+		collector, dispose := goactor.NewParentActor(nil)
+		defer dispose()
+		for i := 0; i < 5; i++ {
+			i := i
+			goactor.Spawn(func(actor *goactor.Actor) {
+				_ = goactor.Send(collector.Self(), i)
+			}, nil)
+		}
+		_ = collector.Receive(func(message interface{}) (loop bool) {
+			order = append(order, message)
+			return len(order) < 5
+		})
+	})
+	assert.Nil(t, err)
+	return order
+}
+
+func TestRun_Replay(t *testing.T) {
+	distinct := map[string]bool{}
+	for seed := int64(1); seed <= 10; seed++ {
+		order := arrivals(t, seed)
+		if !assert.Len(t, order, 5) {return}
+		// the same seed replays the same interleaving
+		assert.Equal(t, order, arrivals(t, seed))
+		distinct[fmt.Sprint(order)] = true
+	}
+	// while different seeds explore different ones
+	assert.Greater(t, len(distinct), 1)
+}
+
+func TestRun_Link(t *testing.T) {
+	var received []interface{}
+	err := sched.Run(1, func() {
+		parent, dispose := goactor.NewParentActor(nil)
+		defer dispose()
+		parent.SetTrapExit(true)
+
+		pid, err := goactor.SpawnLink(parent, func(actor *goactor.Actor) {
+			panic("crashed")
+		}, nil)
+		if !assert.Nil(t, err) {return}
+		_, err = parent.Monitor(pid)
+		if !assert.Nil(t, err) {return}
+
+		_ = parent.Receive(func(message interface{}) (loop bool) {
+			received = append(received, message)
+			return len(received) < 2
+		})
+	})
+	assert.Nil(t, err)
+	if !assert.Len(t, received, 2) {return}
+	types := map[string]bool{}
+	for _, msg := range received {
+		types[fmt.Sprintf("%T", msg)] = true
+	}
+	assert.True(t, types[fmt.Sprintf("%T", sysmsg.AbnormalExit{})])
+	assert.True(t, types[fmt.Sprintf("%T", sysmsg.Down{})])
+}
+
+func TestRun_VirtualTime(t *testing.T) {
+	var err error
+	var elapsed time.Duration
+	start := time.Now()
+	runErr := sched.Run(1, func() {
+		actor, dispose := goactor.NewParentActor(nil)
+		defer dispose()
+		before := sched.Current().Now()
+		err = actor.ReceiveWithTimeout(time.Hour, func(message interface{}) (loop bool) {
+			return false
+		})
+		elapsed = sched.Current().Now().Sub(before)
+	})
+	assert.Nil(t, runErr)
+	assert.Equal(t, mailbox.ErrMailboxReceiveTimeout, err)
+	assert.Equal(t, time.Hour, elapsed)
+	assert.Less(t, int64(time.Since(start)), int64(time.Minute))
+}
+
+func TestRun_StopsWaitingActors(t *testing.T) {
+	var err error
+	runErr := sched.Run(1, func() {
+		goactor.Spawn(func(actor *goactor.Actor) {
+			// no one is going to send a message
+			err = actor.Receive(func(message interface{}) (loop bool) {
+				return true
+			})
+		}, nil)
+	})
+	assert.Nil(t, runErr)
+	assert.Equal(t, mailbox.ErrMailboxClosed, err)
+}
+
+func TestRun_NotScheduled(t *testing.T) {
+	var received []interface{}
+	var errs []error
+	runErr := sched.Run(1, func() {
+		parent, dispose := goactor.NewParentActor(nil)
+		defer dispose()
+
+		// the goroutine isn't driven by the scheduler, so its receives fail instead of parking the running one
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			errs = append(errs, parent.ReceiveWithTimeout(time.Second, func(message interface{}) (loop bool) {
+				return false
+			}))
+			future := goactor.NewFutureActor()
+			errs = append(errs, future.Receive(func(message interface{}) (loop bool) {
+				return false
+			}))
+		}()
+		<-done
+
+		// the run goes on as usual
+		goactor.Spawn(func(actor *goactor.Actor) {
+			_ = goactor.Send(parent.Self(), "hello")
+		}, nil)
+		_ = parent.Receive(func(message interface{}) (loop bool) {
+			received = append(received, message)
+			return false
+		})
+	})
+	assert.Nil(t, runErr)
+	if !assert.Len(t, errs, 2) {return}
+	for _, err := range errs {
+		assert.True(t, errors.Is(err, sched.ErrNotScheduled), err)
+	}
+	assert.Equal(t, []interface{}{"hello"}, received)
+}
+
+func TestRun_Panic(t *testing.T) {
+	err := sched.Run(42, func() {
+		panic("main panicked")
+	})
+	assert.True(t, errors.Is(err, sched.ErrTaskPanicked))
+	assert.Contains(t, err.Error(), "seed 42")
+}
+
+func TestRun_Supervisor(t *testing.T) {
+	var starts int
+	err := sched.Run(1, func() {
+		probe, dispose := goactor.NewParentActor(nil)
+		defer dispose()
+
+		worker := spec.NewWorkerSpec("worker "+uuid.New().String(), spec.RestartAlways, func(actor *goactor.Actor) {
+			_ = goactor.Send(probe.Self(), "started")
+			_ = actor.Receive(func(message interface{}) (loop bool) {
+				panic(message)
+			})
+		})
+		sup, err := supervisor.Start(option.OneForOneStrategyOption(), worker)
+		if !assert.Nil(t, err) {return}
+
+		_ = probe.Receive(func(message interface{}) (loop bool) {
+			starts++
+			if starts == 1 {
+				children, err := sup.WhichChildren(time.Second)
+				if assert.Nil(t, err) && assert.Len(t, children, 1) {
+					_ = goactor.Send(children[0].PID, "crash")
+				}
+			}
+			return starts < 2
+		})
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, starts)
+}
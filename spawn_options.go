@@ -0,0 +1,150 @@
+package goactor
+
+import (
+	"context"
+	"fmt"
+	p "github.com/hedisam/goactor/pid"
+	"github.com/hedisam/goactor/process"
+	"github.com/hedisam/goactor/sched"
+	"github.com/hedisam/goactor/sysmsg"
+)
+
+// SpawnOption configures an actor spawned by SpawnWithOptions.
+type SpawnOption func(options *spawnOptions)
+
+type spawnOptions struct {
+	name       string
+	link       Linker
+	linkSet    bool
+	monitor    *Actor
+	monitorRef *sysmsg.MonitorRef
+	monitorSet bool
+	trapExit   bool
+	mailbox    MailboxBuilderFunc
+	labels     map[string]string
+	ctx        context.Context
+}
+
+// WithName registers the actor by the given name in the process registry.
+func WithName(name string) SpawnOption {
+	return func(options *spawnOptions) {
+		options.name = name
+	}
+}
+
+// WithLink links the actor to the parent.
+func WithLink(parent Linker) SpawnOption {
+	return func(options *spawnOptions) {
+		options.link = parent
+		options.linkSet = true
+	}
+}
+
+// WithMonitor makes the parent monitor the actor. The monitor's reference is stored in ref, unless it's nil.
+func WithMonitor(parent *Actor, ref *sysmsg.MonitorRef) SpawnOption {
+	return func(options *spawnOptions) {
+		options.monitor = parent
+		options.monitorRef = ref
+		options.monitorSet = true
+	}
+}
+
+// WithTrapExit sets whether the actor traps the exits of its linked actors.
+func WithTrapExit(trap bool) SpawnOption {
+	return func(options *spawnOptions) {
+		options.trapExit = trap
+	}
+}
+
+// WithMailbox sets the builder of the actor's mailbox. The actor gets a DefaultQueueMailbox otherwise.
+func WithMailbox(mailboxBuilder MailboxBuilderFunc) SpawnOption {
+	return func(options *spawnOptions) {
+		options.mailbox = mailboxBuilder
+	}
+}
+
+// WithLabels attaches the labels to the actor, which are returned by Actor.Labels. Labels given by more than one
+// option are merged.
+func WithLabels(labels map[string]string) SpawnOption {
+	return func(options *spawnOptions) {
+		if options.labels == nil {
+			options.labels = make(map[string]string, len(labels))
+		}
+		for k, v := range labels {
+			options.labels[k] = v
+		}
+	}
+}
+
+// WithContext sets the parent of the actor's context, which is context.Background() otherwise. Cancelling the parent
+// shuts the actor down: it exits with the reason.Shutdown reason, and its links and monitors are notified of it.
+func WithContext(ctx context.Context) SpawnOption {
+	return func(options *spawnOptions) {
+		options.ctx = ctx
+	}
+}
+
+// SpawnWithOptions spawns a new actor configured by the options. All of the options are applied before the actor's
+// ActorFunc starts running, so none of them races with the actor. If any of them fails, the ones which have already
+// been applied are undone and the actor never runs.
+func SpawnWithOptions(fn ActorFunc, opts ...SpawnOption) (*p.PID, error) {
+	var options spawnOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if (options.linkSet && options.link == nil) || (options.monitorSet && options.monitor == nil) {
+		return nil, ErrSpawnNilParent
+	}
+	ctx := options.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	actor, pid := setupActorWithContext(ctx, options.mailbox)
+	actor.SetTrapExit(options.trapExit)
+	actor.labels = options.labels
+
+	// the options which can be undone come first, the link is the only one that can't.
+	if options.name != "" {
+		err := process.Register(options.name, pid)
+		if err != nil {
+			actor.shutdown()
+			return nil, fmt.Errorf("spawn failed: %w", err)
+		}
+	}
+	var ref sysmsg.MonitorRef
+	if options.monitor != nil {
+		var err error
+		ref, err = options.monitor.Monitor(pid)
+		if err != nil {
+			unregister(options.name)
+			actor.shutdown()
+			return nil, fmt.Errorf("spawn failed: %w", err)
+		}
+	}
+	if options.link != nil {
+		err := options.link.Link(pid)
+		if err != nil {
+			if options.monitor != nil {
+				_ = options.monitor.Demonitor(ref)
+			}
+			unregister(options.name)
+			actor.shutdown()
+			return nil, fmt.Errorf("spawn failed: %w", err)
+		}
+	}
+	if options.monitorRef != nil {
+		*options.monitorRef = ref
+	}
+
+	sched.Go(func() {
+		spawn(fn, actor)
+	})
+	return pid, nil
+}
+
+func unregister(name string) {
+	if name != "" {
+		process.Unregister(name)
+	}
+}
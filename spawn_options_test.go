@@ -0,0 +1,186 @@
+package goactor
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	p "github.com/hedisam/goactor/pid"
+	"github.com/hedisam/goactor/process"
+	"github.com/hedisam/goactor/reason"
+	"github.com/hedisam/goactor/sysmsg"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type optionsKey struct{}
+
+func TestSpawnWithOptions(t *testing.T) {
+	t.Run("nil parent", func(t *testing.T) {
+		pid, err := SpawnWithOptions(func(a *Actor) {}, WithLink(nil))
+		assert.Nil(t, pid)
+		assert.Equal(t, ErrSpawnNilParent, err)
+
+		pid, err = SpawnWithOptions(func(a *Actor) {}, WithMonitor(nil, nil))
+		assert.Nil(t, pid)
+		assert.Equal(t, ErrSpawnNilParent, err)
+	})
+
+	t.Run("options applied before the actor's fn runs", func(t *testing.T) {
+		parent, dispose := NewParentActor(nil)
+		defer dispose()
+		parent.SetTrapExit(true)
+
+		type seen struct {
+			name     string
+			trapExit bool
+			labels   map[string]string
+			value    interface{}
+		}
+		result := make(chan seen, 1)
+		name := "spawn options " + uuid.New().String()
+		defer process.Unregister(name)
+		ctx := context.WithValue(context.Background(), optionsKey{}, "value")
+		var ref sysmsg.MonitorRef
+		pid, err := SpawnWithOptions(func(a *Actor) {
+			registered, _ := process.NameOf(a.Self())
+			result <- seen{
+				name:     registered,
+				trapExit: a.TrapExit(),
+				labels:   a.Labels(),
+				value:    a.Context().Value(optionsKey{}),
+			}
+			panic("exiting right away")
+		},
+			WithName(name),
+			WithLink(parent),
+			WithMonitor(parent, &ref),
+			WithTrapExit(true),
+			WithMailbox(DefaultChanMailbox),
+			WithLabels(map[string]string{"role": "worker"}),
+			WithLabels(map[string]string{"zone": "eu"}),
+			WithContext(ctx),
+		)
+		if !assert.Nil(t, err) {return}
+		assert.NotEmpty(t, ref)
+
+		select {
+		case s := <-result:
+			assert.Equal(t, name, s.name)
+			assert.True(t, s.trapExit)
+			assert.Equal(t, map[string]string{"role": "worker", "zone": "eu"}, s.labels)
+			assert.Equal(t, "value", s.value)
+		case <-time.After(time.Second):
+			t.Fatal("the actor's fn didn't run")
+		}
+
+		var exited, down bool
+		err = parent.ReceiveWithTimeout(time.Second, func(message interface{}) (loop bool) {
+			switch message := message.(type) {
+			case sysmsg.AbnormalExit:
+				exited = p.Equal(pid, message.Sender())
+			case sysmsg.Down:
+				down = message.Ref == ref && p.Equal(pid, message.PID)
+			}
+			return !(exited && down)
+		})
+		assert.Nil(t, err)
+	})
+
+	t.Run("undone when an option fails", func(t *testing.T) {
+		parent, dispose := NewParentActor(nil)
+		defer dispose()
+		disposedParent, disposeParent := NewParentActor(nil)
+		disposeParent()
+
+		name := "spawn options " + uuid.New().String()
+		invoked := make(chan struct{}, 1)
+		pid, err := SpawnWithOptions(func(a *Actor) {
+			invoked <- struct{}{}
+		}, WithName(name), WithMonitor(parent, nil), WithLink(disposedParent))
+		assert.NotNil(t, err)
+		assert.Nil(t, pid)
+
+		_, found := process.WhereIs(name)
+		assert.False(t, found)
+		select {
+		case <-invoked:
+			t.Error("the actor's fn should not run when an option fails")
+		case <-time.After(10 * time.Millisecond):
+		}
+	})
+
+	t.Run("name taken", func(t *testing.T) {
+		name := "spawn options " + uuid.New().String()
+		_, err := SpawnWithOptions(func(a *Actor) {
+			_ = a.Receive(func(message interface{}) (loop bool) {return false})
+		}, WithName(name))
+		if !assert.Nil(t, err) {return}
+		defer func() {
+			pid, _ := process.WhereIs(name)
+			_ = Send(pid, "stop")
+			process.Unregister(name)
+		}()
+
+		_, err = SpawnWithOptions(func(a *Actor) {}, WithName(name))
+		assert.True(t, errors.Is(err, process.ErrNameTaken))
+	})
+}
+
+func TestSpawnWithOptions_ContextCancelled(t *testing.T) {
+	fns := map[string]ActorFunc{
+		"receiving": func(a *Actor) {
+			_ = a.Receive(func(message interface{}) (loop bool) {return true})
+		},
+		"busy": func(a *Actor) {
+			<-a.Context().Done()
+		},
+	}
+	for name, fn := range fns {
+		t.Run(name, func(t *testing.T) {
+			parent, dispose := NewParentActor(nil)
+			defer dispose()
+			parent.SetTrapExit(true)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			var ref sysmsg.MonitorRef
+			pid, err := SpawnWithOptions(fn, WithContext(ctx), WithLink(parent), WithMonitor(parent, &ref))
+			if !assert.Nil(t, err) {return}
+
+			// cancelling the parent context is a shutdown exit, the links and monitors are notified of it
+			cancel()
+			var exited, down bool
+			err = parent.ReceiveWithTimeout(time.Second, func(message interface{}) (loop bool) {
+				switch message := message.(type) {
+				case sysmsg.AbnormalExit:
+					assert.True(t, p.Equal(pid, message.Sender()))
+					assert.Equal(t, reason.Shutdown, message.Reason())
+					exited = true
+				case sysmsg.Down:
+					assert.Equal(t, sysmsg.Down{Ref: ref, PID: pid, Reason: reason.Shutdown}, message)
+					down = true
+				}
+				return !(exited && down)
+			})
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func TestActor_Labels(t *testing.T) {
+	labels := make(chan map[string]string, 1)
+	_, err := SpawnWithOptions(func(a *Actor) {
+		l := a.Labels()
+		l["changed"] = "yes"
+		labels <- a.Labels()
+	}, WithLabels(map[string]string{"role": "worker"}))
+	if !assert.Nil(t, err) {return}
+
+	select {
+	case l := <-labels:
+		assert.Equal(t, map[string]string{"role": "worker"}, l)
+	case <-time.After(time.Second):
+		t.Fatal("the actor's fn didn't run")
+	}
+}
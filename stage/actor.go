@@ -0,0 +1,245 @@
+package stage
+
+import (
+	"github.com/google/uuid"
+	"github.com/hedisam/goactor"
+	p "github.com/hedisam/goactor/pid"
+	"github.com/hedisam/goactor/sysmsg"
+	"log"
+)
+
+// subscription is a consumer's subscription to a producer, as seen by the consumer.
+type subscription struct {
+	ref        string
+	producer   *p.PID
+	monitorRef sysmsg.MonitorRef
+	opts       SubscribeOptions
+	// pending is the number of events asked for and not received yet, and processed is the number of received events
+	// which haven't been asked for again.
+	pending   int
+	processed int
+}
+
+// stage is the actor of a producer, a producer-consumer or a consumer. A producer-consumer has both the producer and
+// the consumer sides.
+type stage struct {
+	actor *goactor.Actor
+
+	producer   Producer
+	pc         ProducerConsumer
+	consumer   Consumer
+	dispatcher Dispatcher
+	// buffer keeps the events which couldn't be dispatched for the lack of demand.
+	buffer []interface{}
+	// subscribers maps the monitors of the producer's consumers to their subscription ref.
+	subscribers map[sysmsg.MonitorRef]string
+
+	subscriptions map[string]*subscription
+	producers     map[sysmsg.MonitorRef]string
+}
+
+func newStage() *stage {
+	return &stage{
+		subscribers:   make(map[sysmsg.MonitorRef]string),
+		subscriptions: make(map[string]*subscription),
+		producers:     make(map[sysmsg.MonitorRef]string),
+	}
+}
+
+func (s *stage) run(actor *goactor.Actor) {
+	s.actor = actor
+	_ = actor.Receive(s.handle)
+}
+
+func (s *stage) isProducer() bool {
+	return s.dispatcher != nil
+}
+
+func (s *stage) isConsumer() bool {
+	return s.pc != nil || s.consumer != nil
+}
+
+func (s *stage) handle(message interface{}) (loop bool) {
+	switch msg := message.(type) {
+	case subscribeRequest:
+		s.subscribeTo(msg)
+	case subscribe:
+		s.addSubscriber(msg)
+	case ask:
+		if s.isProducer() {
+			s.produce(s.dispatcher.ask(msg.ref, msg.demand))
+		}
+	case events:
+		s.consume(msg)
+	case cancel:
+		s.cancel(msg.ref)
+	case sysmsg.Down:
+		if ref, ok := s.subscribers[msg.Ref]; ok {
+			delete(s.subscribers, msg.Ref)
+			s.dispatcher.cancel(ref)
+		} else if ref, ok := s.producers[msg.Ref]; ok {
+			delete(s.producers, msg.Ref)
+			delete(s.subscriptions, ref)
+		}
+	case stopRequest:
+		return false
+	default:
+		handler, ok := s.handler()
+		if !ok {
+			log.Printf("[!] stage %s received an unknown message: %v\n", s.actor.Self().ID(), message)
+			return true
+		}
+		s.emit(handler.HandleInfo(message))
+	}
+	return true
+}
+
+func (s *stage) handler() (InfoHandler, bool) {
+	if s.producer != nil {
+		handler, ok := s.producer.(InfoHandler)
+		return handler, ok
+	}
+	handler, ok := s.pc.(InfoHandler)
+	return handler, ok
+}
+
+// subscribeTo subscribes the consumer to a producer and asks for the first batch of events.
+func (s *stage) subscribeTo(req subscribeRequest) {
+	if !s.isConsumer() {
+		s.reply(req.requester, subscribeResponse{err: ErrNotConsumer})
+		return
+	}
+	monitorRef, err := s.actor.Monitor(req.producer)
+	if err != nil {
+		s.reply(req.requester, subscribeResponse{err: err})
+		return
+	}
+
+	sub := &subscription{
+		ref:        uuid.New().String(),
+		producer:   req.producer,
+		monitorRef: monitorRef,
+		opts:       req.opts,
+		pending:    req.opts.MaxDemand,
+	}
+	s.subscriptions[sub.ref] = sub
+	s.producers[monitorRef] = sub.ref
+
+	// the producer replies to the requester.
+	s.send(req.producer, subscribe{ref: sub.ref, consumer: s.actor.Self(), partition: req.opts.Partition, requester: req.requester})
+	s.send(req.producer, ask{ref: sub.ref, demand: sub.pending})
+}
+
+func (s *stage) addSubscriber(req subscribe) {
+	if !s.isProducer() {
+		s.reply(req.requester, subscribeResponse{err: ErrNotProducer})
+		s.send(req.consumer, cancel{ref: req.ref})
+		return
+	}
+	monitorRef, err := s.actor.Monitor(req.consumer)
+	if err != nil {
+		s.reply(req.requester, subscribeResponse{err: err})
+		return
+	}
+	err = s.dispatcher.subscribe(&subscriber{ref: req.ref, pid: req.consumer, partition: req.partition})
+	if err != nil {
+		_ = s.actor.Demonitor(monitorRef)
+		s.reply(req.requester, subscribeResponse{err: err})
+		s.send(req.consumer, cancel{ref: req.ref})
+		return
+	}
+	s.subscribers[monitorRef] = req.ref
+	s.reply(req.requester, subscribeResponse{})
+}
+
+// produce dispatches the buffered events, and asks the producer for the rest of the new demand. A producer-consumer
+// asks its own producers for more events once its buffer is drained.
+func (s *stage) produce(demand int) {
+	buffered := len(s.buffer)
+	s.buffer = s.dispatcher.dispatch(s.buffer)
+	demand -= buffered - len(s.buffer)
+
+	if s.producer != nil && demand > 0 {
+		s.emit(s.producer.HandleDemand(demand))
+	}
+	if s.pc != nil && len(s.buffer) == 0 {
+		for _, sub := range s.subscriptions {
+			s.askMore(sub)
+		}
+	}
+}
+
+// emit dispatches the events after the buffered ones, and buffers the rest.
+func (s *stage) emit(events []interface{}) {
+	if len(events) == 0 {
+		return
+	}
+	s.buffer = s.dispatcher.dispatch(append(s.buffer, events...))
+	if dropped := len(s.buffer) - DefaultBufferSize; dropped > 0 {
+		log.Printf("[!] stage %s dropped %d event(s) since its buffer is full\n", s.actor.Self().ID(), dropped)
+		s.buffer = append(s.buffer[:0], s.buffer[dropped:]...)
+	}
+}
+
+func (s *stage) consume(msg events) {
+	sub, ok := s.subscriptions[msg.ref]
+	if !ok {
+		return
+	}
+	sub.pending -= len(msg.events)
+	sub.processed += len(msg.events)
+
+	if s.consumer != nil {
+		s.consumer.HandleEvents(msg.events)
+	} else {
+		s.emit(s.pc.HandleEvents(msg.events))
+	}
+	s.askMore(sub)
+}
+
+// askMore asks the producer for as many events as processed once the pending events reach the min demand. A
+// producer-consumer doesn't ask for more while it has buffered events.
+func (s *stage) askMore(sub *subscription) {
+	if sub.processed == 0 || sub.pending > sub.opts.MinDemand {
+		return
+	}
+	if s.pc != nil && len(s.buffer) > 0 {
+		return
+	}
+	s.send(sub.producer, ask{ref: sub.ref, demand: sub.processed})
+	sub.pending += sub.processed
+	sub.processed = 0
+}
+
+// cancel removes the subscription from whichever end of it this stage is.
+func (s *stage) cancel(ref string) {
+	if sub, ok := s.subscriptions[ref]; ok {
+		_ = s.actor.Demonitor(sub.monitorRef)
+		delete(s.producers, sub.monitorRef)
+		delete(s.subscriptions, ref)
+		return
+	}
+	for monitorRef, subRef := range s.subscribers {
+		if subRef == ref {
+			_ = s.actor.Demonitor(monitorRef)
+			delete(s.subscribers, monitorRef)
+			s.dispatcher.cancel(ref)
+			return
+		}
+	}
+}
+
+func (s *stage) send(pid *p.PID, message interface{}) {
+	err := goactor.Send(pid, message)
+	if err != nil {
+		// a dead stage is noticed by its monitor.
+		log.Printf("[!] stage %s couldn't send a message to %s: %v\n", s.actor.Self().ID(), pid, err)
+	}
+}
+
+func (s *stage) reply(requester *p.PID, resp subscribeResponse) {
+	err := goactor.Send(requester, resp)
+	if err != nil {
+		log.Printf("[!] stage %s couldn't send back a response: %v\n", s.actor.Self().ID(), err)
+	}
+}
@@ -0,0 +1,231 @@
+package stage
+
+import (
+	"github.com/hedisam/goactor"
+	p "github.com/hedisam/goactor/pid"
+	"log"
+)
+
+// Dispatcher decides which of a producer's consumers get its events. It's only used by the producer's actor.
+type Dispatcher interface {
+	subscribe(c *subscriber) error
+	cancel(ref string)
+	// ask adds the consumer's demand and returns the new demand of the producer.
+	ask(ref string, demand int) int
+	// dispatch sends the events to the consumers as far as their demand allows and returns the rest.
+	dispatch(events []interface{}) []interface{}
+}
+
+// subscriber is a consumer subscribed to a producer, as seen by the producer's dispatcher.
+type subscriber struct {
+	ref       string
+	pid       *p.PID
+	partition int
+	// pending is the number of events the consumer has asked for and not received yet.
+	pending int
+}
+
+func (c *subscriber) deliver(batch []interface{}) {
+	c.pending -= len(batch)
+	// the batch is copied since it shares its backing array with the producer's buffer.
+	batch = append([]interface{}(nil), batch...)
+	err := goactor.Send(c.pid, events{ref: c.ref, events: batch})
+	if err != nil {
+		// the consumer's Down message cancels the subscription.
+		log.Printf("[!] stage: couldn't deliver %d event(s) to consumer %s: %v\n", len(batch), c.pid, err)
+	}
+}
+
+// subscribers keeps the subscribers in the order they subscribed.
+type subscribers []*subscriber
+
+func (s subscribers) get(ref string) *subscriber {
+	for _, c := range s {
+		if c.ref == ref {
+			return c
+		}
+	}
+	return nil
+}
+
+func (s subscribers) remove(ref string) subscribers {
+	for i, c := range s {
+		if c.ref == ref {
+			return append(s[:i], s[i+1:]...)
+		}
+	}
+	return s
+}
+
+type demandDispatcher struct {
+	subscribers subscribers
+}
+
+// DemandDispatcher returns a dispatcher which sends each event to only one consumer, the one with the highest demand.
+func DemandDispatcher() Dispatcher {
+	return &demandDispatcher{}
+}
+
+func (d *demandDispatcher) subscribe(c *subscriber) error {
+	d.subscribers = append(d.subscribers, c)
+	return nil
+}
+
+func (d *demandDispatcher) cancel(ref string) {
+	d.subscribers = d.subscribers.remove(ref)
+}
+
+func (d *demandDispatcher) ask(ref string, demand int) int {
+	c := d.subscribers.get(ref)
+	if c == nil {
+		return 0
+	}
+	c.pending += demand
+	return demand
+}
+
+func (d *demandDispatcher) dispatch(events []interface{}) []interface{} {
+	for len(events) > 0 {
+		var next *subscriber
+		for _, c := range d.subscribers {
+			if c.pending > 0 && (next == nil || c.pending > next.pending) {
+				next = c
+			}
+		}
+		if next == nil {
+			break
+		}
+		n := min(next.pending, len(events))
+		next.deliver(events[:n])
+		events = events[n:]
+	}
+	return events
+}
+
+type broadcastDispatcher struct {
+	subscribers subscribers
+}
+
+// BroadcastDispatcher returns a dispatcher which sends each event to all the consumers. The producer's demand is the
+// lowest demand among its consumers.
+func BroadcastDispatcher() Dispatcher {
+	return &broadcastDispatcher{}
+}
+
+func (d *broadcastDispatcher) subscribe(c *subscriber) error {
+	d.subscribers = append(d.subscribers, c)
+	return nil
+}
+
+func (d *broadcastDispatcher) cancel(ref string) {
+	d.subscribers = d.subscribers.remove(ref)
+}
+
+func (d *broadcastDispatcher) ask(ref string, demand int) int {
+	c := d.subscribers.get(ref)
+	if c == nil {
+		return 0
+	}
+	before := d.demand()
+	c.pending += demand
+	return d.demand() - before
+}
+
+func (d *broadcastDispatcher) demand() int {
+	if len(d.subscribers) == 0 {
+		return 0
+	}
+	demand := d.subscribers[0].pending
+	for _, c := range d.subscribers[1:] {
+		demand = min(demand, c.pending)
+	}
+	return demand
+}
+
+func (d *broadcastDispatcher) dispatch(events []interface{}) []interface{} {
+	n := min(d.demand(), len(events))
+	if n == 0 {
+		return events
+	}
+	for _, c := range d.subscribers {
+		c.deliver(events[:n])
+	}
+	return events[n:]
+}
+
+type partitionDispatcher struct {
+	partitions []*subscriber
+	hash       func(event interface{}) int
+}
+
+// PartitionDispatcher returns a dispatcher which sends each event to the consumer of the partition returned by hash.
+// Each partition can have one consumer which chooses it by SubscribeOptions.Partition. The events of a partition
+// without a consumer are kept in the producer's buffer until one subscribes. There's at least one partition.
+func PartitionDispatcher(partitions int, hash func(event interface{}) int) Dispatcher {
+	if partitions < 1 {
+		partitions = 1
+	}
+	return &partitionDispatcher{partitions: make([]*subscriber, partitions), hash: hash}
+}
+
+func (d *partitionDispatcher) subscribe(c *subscriber) error {
+	if c.partition < 0 || c.partition >= len(d.partitions) {
+		return ErrInvalidPartition
+	}
+	if d.partitions[c.partition] != nil {
+		return ErrPartitionTaken
+	}
+	d.partitions[c.partition] = c
+	return nil
+}
+
+func (d *partitionDispatcher) cancel(ref string) {
+	for i, c := range d.partitions {
+		if c != nil && c.ref == ref {
+			d.partitions[i] = nil
+			return
+		}
+	}
+}
+
+func (d *partitionDispatcher) ask(ref string, demand int) int {
+	for _, c := range d.partitions {
+		if c != nil && c.ref == ref {
+			c.pending += demand
+			return demand
+		}
+	}
+	return 0
+}
+
+func (d *partitionDispatcher) dispatch(events []interface{}) []interface{} {
+	batches := make([][]interface{}, len(d.partitions))
+	var rest []interface{}
+	for _, event := range events {
+		partition := d.partition(event)
+		c := d.partitions[partition]
+		if c == nil || len(batches[partition]) == c.pending {
+			rest = append(rest, event)
+			continue
+		}
+		batches[partition] = append(batches[partition], event)
+	}
+	for partition, batch := range batches {
+		if len(batch) > 0 {
+			d.partitions[partition].deliver(batch)
+		}
+	}
+	return rest
+}
+
+func (d *partitionDispatcher) partition(event interface{}) int {
+	n := len(d.partitions)
+	return (d.hash(event)%n + n) % n
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
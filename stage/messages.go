@@ -0,0 +1,41 @@
+package stage
+
+import p "github.com/hedisam/goactor/pid"
+
+// subscribeRequest asks a consumer to subscribe to a producer.
+type subscribeRequest struct {
+	producer  *p.PID
+	opts      SubscribeOptions
+	requester *p.PID
+}
+
+type subscribeResponse struct {
+	err error
+}
+
+// subscribe is sent by a consumer to a producer. The producer replies to the requester.
+type subscribe struct {
+	ref       string
+	consumer  *p.PID
+	partition int
+	requester *p.PID
+}
+
+// ask is sent by a consumer to a producer to demand more events.
+type ask struct {
+	ref    string
+	demand int
+}
+
+// events is sent by a producer to a consumer.
+type events struct {
+	ref    string
+	events []interface{}
+}
+
+// cancel is sent to the other end of a subscription to cancel it.
+type cancel struct {
+	ref string
+}
+
+type stopRequest struct{}
@@ -0,0 +1,145 @@
+package stage
+
+import (
+	"fmt"
+	"github.com/hedisam/goactor"
+	p "github.com/hedisam/goactor/pid"
+	"time"
+)
+
+const (
+	DefaultMaxDemand = 1000
+	// DefaultBufferSize is the max number of events a producer keeps while there's no demand for them. The oldest
+	// events are dropped once the buffer is full.
+	DefaultBufferSize = 10000
+)
+
+var ErrNilStage = fmt.Errorf("stage: stage is nil")
+var ErrNotProducer = fmt.Errorf("stage: only producers and producer-consumers can be subscribed to")
+var ErrNotConsumer = fmt.Errorf("stage: only consumers and producer-consumers can subscribe to a producer")
+var ErrInvalidDemand = fmt.Errorf("stage: max demand must be greater than zero and min demand must be less than max demand")
+var ErrInvalidPartition = fmt.Errorf("stage: invalid partition")
+var ErrPartitionTaken = fmt.Errorf("stage: partition already has a consumer")
+var ErrUnknownResponse = fmt.Errorf("stage: unknown response")
+
+// Producer emits events upon demand.
+type Producer interface {
+	// HandleDemand is called with the new demand of the producer's consumers. It can return fewer events than
+	// demanded, in which case the producer should keep track of the rest of the demand and emit the events later, e.g.
+	// from HandleInfo. The extra events, if any, are buffered.
+	HandleDemand(demand int) []interface{}
+}
+
+// InfoHandler can be implemented by producers and producer-consumers to receive the messages sent to their pid, other
+// than the stage's own messages. The returned events are dispatched to the consumers.
+type InfoHandler interface {
+	HandleInfo(message interface{}) []interface{}
+}
+
+// ProducerConsumer receives events from its producers and emits events to its consumers. It only asks its producers
+// for more events once the events it has emitted are taken by its consumers.
+type ProducerConsumer interface {
+	HandleEvents(events []interface{}) []interface{}
+}
+
+// Consumer receives events from its producers. It asks for more events once it's done with a batch.
+type Consumer interface {
+	HandleEvents(events []interface{})
+}
+
+// SubscribeOptions are the options of a consumer's subscription to a producer.
+type SubscribeOptions struct {
+	// MaxDemand is the max number of events the consumer can have in flight. DefaultMaxDemand is used if it's zero.
+	MaxDemand int
+	// MinDemand is the number of in flight events that when reached, the consumer asks for more events. It's 3/4 of
+	// MaxDemand if it's zero.
+	MinDemand int
+	// Partition is the partition the consumer subscribes to when the producer uses a PartitionDispatcher.
+	Partition int
+}
+
+func (opts *SubscribeOptions) normalize() error {
+	if opts.MaxDemand == 0 {
+		opts.MaxDemand = DefaultMaxDemand
+	}
+	if opts.MinDemand == 0 {
+		opts.MinDemand = opts.MaxDemand * 3 / 4
+	}
+	if opts.MaxDemand < 1 || opts.MinDemand < 0 || opts.MinDemand >= opts.MaxDemand {
+		return ErrInvalidDemand
+	}
+	return nil
+}
+
+// Stage is a reference to a stage's actor.
+type Stage struct {
+	pid *p.PID
+}
+
+// StartProducer spawns a producer stage which dispatches its events to its consumers by the given dispatcher.
+// A dispatcher must not be shared by more than one producer.
+func StartProducer(producer Producer, dispatcher Dispatcher) *Stage {
+	s := newStage()
+	s.producer = producer
+	s.dispatcher = dispatcher
+	return &Stage{pid: goactor.Spawn(s.run, nil)}
+}
+
+// StartProducerConsumer spawns a producer-consumer stage which dispatches its events to its consumers by the given
+// dispatcher. A dispatcher must not be shared by more than one producer.
+func StartProducerConsumer(pc ProducerConsumer, dispatcher Dispatcher) *Stage {
+	s := newStage()
+	s.pc = pc
+	s.dispatcher = dispatcher
+	return &Stage{pid: goactor.Spawn(s.run, nil)}
+}
+
+// StartConsumer spawns a consumer stage.
+func StartConsumer(consumer Consumer) *Stage {
+	s := newStage()
+	s.consumer = consumer
+	return &Stage{pid: goactor.Spawn(s.run, nil)}
+}
+
+// PID returns the stage's pid.
+func (s *Stage) PID() *p.PID {
+	return s.pid
+}
+
+// Stop stops the stage. Its subscriptions get cancelled on both ends.
+func (s *Stage) Stop() error {
+	return goactor.Send(s.pid, stopRequest{})
+}
+
+// Subscribe subscribes the consumer, or producer-consumer, to the producer. The producer and the consumer monitor
+// each other, so the subscription gets cancelled once any of them exits.
+func Subscribe(consumer, producer *Stage, opts SubscribeOptions, timeout time.Duration) error {
+	if consumer == nil || producer == nil {
+		return ErrNilStage
+	}
+	err := opts.normalize()
+	if err != nil {
+		return err
+	}
+
+	future := goactor.NewFutureActor()
+	req := subscribeRequest{producer: producer.pid, opts: opts, requester: future.Self()}
+	err = goactor.Send(consumer.pid, req)
+	if err != nil {
+		return fmt.Errorf("stage: couldn't deliver subscribe request: %w", err)
+	}
+
+	var respErr error
+	err = future.ReceiveWithTimeout(timeout, func(message interface{}) (loop bool) {
+		if resp, ok := message.(subscribeResponse); ok {
+			respErr = resp.err
+			return false
+		}
+		respErr = fmt.Errorf("%w: %v", ErrUnknownResponse, message)
+		return false
+	})
+	if err != nil {
+		return fmt.Errorf("stage: subscribe request failed: %w", err)
+	}
+	return respErr
+}
@@ -0,0 +1,249 @@
+package stage
+
+import (
+	"github.com/hedisam/goactor"
+	"github.com/stretchr/testify/assert"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// counter emits increasing integers upon demand.
+type counter struct {
+	next    int64
+	emitted int64
+}
+
+func (c *counter) HandleDemand(demand int) []interface{} {
+	events := make([]interface{}, 0, demand)
+	for i := 0; i < demand; i++ {
+		events = append(events, int(c.next))
+		c.next++
+	}
+	atomic.AddInt64(&c.emitted, int64(demand))
+	return events
+}
+
+// pusher only emits the events sent to it.
+type pusher struct{}
+
+func (pusher) HandleDemand(demand int) []interface{} {
+	return nil
+}
+
+func (pusher) HandleInfo(message interface{}) []interface{} {
+	return []interface{}{message}
+}
+
+type doubler struct{}
+
+func (doubler) HandleEvents(events []interface{}) []interface{} {
+	doubled := make([]interface{}, 0, len(events))
+	for _, event := range events {
+		doubled = append(doubled, event.(int)*2)
+	}
+	return doubled
+}
+
+type collector struct {
+	events chan interface{}
+	// block, if not nil, is received from before handling each batch.
+	block chan struct{}
+	crash bool
+}
+
+func newCollector() *collector {
+	return &collector{events: make(chan interface{}, 1000)}
+}
+
+func (c *collector) HandleEvents(events []interface{}) {
+	if c.crash {
+		panic("consumer crashed")
+	}
+	if c.block != nil {
+		<-c.block
+	}
+	for _, event := range events {
+		c.events <- event
+	}
+}
+
+func (c *collector) take(t *testing.T, n int) []interface{} {
+	var events []interface{}
+	for len(events) < n {
+		select {
+		case event := <-c.events:
+			events = append(events, event)
+		case <-time.After(time.Second):
+			t.Errorf("received %d event(s), expected %d", len(events), n)
+			return events
+		}
+	}
+	return events
+}
+
+func TestSubscribe(t *testing.T) {
+	producer := StartProducer(&counter{}, DemandDispatcher())
+	defer producer.Stop()
+	consumer := StartConsumer(newCollector())
+	defer consumer.Stop()
+
+	err := Subscribe(producer, producer, SubscribeOptions{}, time.Second)
+	assert.Equal(t, ErrNotConsumer, err)
+	err = Subscribe(consumer, consumer, SubscribeOptions{}, time.Second)
+	assert.Equal(t, ErrNotProducer, err)
+	err = Subscribe(consumer, producer, SubscribeOptions{MaxDemand: 10, MinDemand: 10}, time.Second)
+	assert.Equal(t, ErrInvalidDemand, err)
+	err = Subscribe(nil, producer, SubscribeOptions{}, time.Second)
+	assert.Equal(t, ErrNilStage, err)
+
+	partitioned := StartProducer(&counter{}, PartitionDispatcher(2, func(event interface{}) int {
+		return event.(int)
+	}))
+	defer partitioned.Stop()
+	err = Subscribe(consumer, partitioned, SubscribeOptions{Partition: 2}, time.Second)
+	assert.Equal(t, ErrInvalidPartition, err)
+	assert.Nil(t, Subscribe(consumer, partitioned, SubscribeOptions{Partition: 1}, time.Second))
+	other := StartConsumer(newCollector())
+	defer other.Stop()
+	err = Subscribe(other, partitioned, SubscribeOptions{Partition: 1}, time.Second)
+	assert.Equal(t, ErrPartitionTaken, err)
+}
+
+func TestPipeline(t *testing.T) {
+	source := &counter{}
+	producer := StartProducer(source, DemandDispatcher())
+	defer producer.Stop()
+	pc := StartProducerConsumer(doubler{}, DemandDispatcher())
+	defer pc.Stop()
+	sink := newCollector()
+	consumer := StartConsumer(sink)
+	defer consumer.Stop()
+
+	if !assert.Nil(t, Subscribe(consumer, pc, SubscribeOptions{MaxDemand: 10}, time.Second)) {return}
+	if !assert.Nil(t, Subscribe(pc, producer, SubscribeOptions{MaxDemand: 10}, time.Second)) {return}
+
+	events := sink.take(t, 100)
+	for i, event := range events {
+		assert.Equal(t, i*2, event)
+	}
+}
+
+func TestBackpressure(t *testing.T) {
+	source := &counter{}
+	producer := StartProducer(source, DemandDispatcher())
+	defer producer.Stop()
+	pc := StartProducerConsumer(doubler{}, DemandDispatcher())
+	defer pc.Stop()
+	sink := newCollector()
+	sink.block = make(chan struct{})
+	consumer := StartConsumer(sink)
+	defer consumer.Stop()
+
+	if !assert.Nil(t, Subscribe(consumer, pc, SubscribeOptions{MaxDemand: 5}, time.Second)) {return}
+	if !assert.Nil(t, Subscribe(pc, producer, SubscribeOptions{MaxDemand: 5}, time.Second)) {return}
+
+	// the consumer is blocked on its first batch, the producer-consumer keeps a batch in its buffer and stops asking
+	// for more.
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt64(&source.emitted) == 10
+	}, time.Second, 5*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, int64(10), atomic.LoadInt64(&source.emitted))
+
+	close(sink.block)
+	events := sink.take(t, 50)
+	for i, event := range events {
+		assert.Equal(t, i*2, event)
+	}
+}
+
+func TestBroadcastDispatcher(t *testing.T) {
+	producer := StartProducer(pusher{}, BroadcastDispatcher())
+	defer producer.Stop()
+
+	var sinks []*collector
+	for i := 0; i < 2; i++ {
+		sink := newCollector()
+		consumer := StartConsumer(sink)
+		defer consumer.Stop()
+		if !assert.Nil(t, Subscribe(consumer, producer, SubscribeOptions{MaxDemand: 10}, time.Second)) {return}
+		sinks = append(sinks, sink)
+	}
+
+	for i := 0; i < 50; i++ {
+		assert.Nil(t, goactor.Send(producer.PID(), i))
+	}
+	// both consumers get all the events
+	first := sinks[0].take(t, 50)
+	assert.Equal(t, first, sinks[1].take(t, 50))
+	for i, event := range first {
+		assert.Equal(t, i, event)
+	}
+}
+
+func TestPartitionDispatcher(t *testing.T) {
+	producer := StartProducer(&counter{}, PartitionDispatcher(2, func(event interface{}) int {
+		return event.(int) % 2
+	}))
+	defer producer.Stop()
+
+	var sinks []*collector
+	for i := 0; i < 2; i++ {
+		sink := newCollector()
+		consumer := StartConsumer(sink)
+		defer consumer.Stop()
+		err := Subscribe(consumer, producer, SubscribeOptions{MaxDemand: 10, Partition: i}, time.Second)
+		if !assert.Nil(t, err) {return}
+		sinks = append(sinks, sink)
+	}
+
+	for partition, sink := range sinks {
+		events := sink.take(t, 30)
+		for i, event := range events {
+			assert.Equal(t, i*2+partition, event)
+		}
+	}
+}
+
+func TestConsumerCrashCancelsSubscription(t *testing.T) {
+	producer := StartProducer(pusher{}, DemandDispatcher())
+	defer producer.Stop()
+
+	crashing := newCollector()
+	crashing.crash = true
+	crashingConsumer := StartConsumer(crashing)
+	if !assert.Nil(t, Subscribe(crashingConsumer, producer, SubscribeOptions{MaxDemand: 100}, time.Second)) {return}
+	sink := newCollector()
+	consumer := StartConsumer(sink)
+	defer consumer.Stop()
+	if !assert.Nil(t, Subscribe(consumer, producer, SubscribeOptions{MaxDemand: 10}, time.Second)) {return}
+
+	// the crashing consumer has the highest demand so it gets the first event
+	assert.Nil(t, goactor.Send(producer.PID(), -1))
+	// once its subscription is cancelled, the events go to the other consumer
+	assert.Eventually(t, func() bool {
+		_ = goactor.Send(producer.PID(), 0)
+		select {
+		case event := <-sink.events:
+			return event == 0
+		case <-time.After(10 * time.Millisecond):
+			return false
+		}
+	}, time.Second, time.Millisecond)
+
+	for i := 1; i <= 20; i++ {
+		assert.Nil(t, goactor.Send(producer.PID(), i))
+	}
+	var events []interface{}
+	for _, event := range sink.take(t, 20) {
+		// skip the late events of the retries above
+		if event != 0 {
+			events = append(events, event)
+		}
+	}
+	events = append(events, sink.take(t, 20-len(events))...)
+	for i, event := range events {
+		assert.Equal(t, i+1, event)
+	}
+}
@@ -0,0 +1,222 @@
+package statem
+
+import (
+	"fmt"
+	"github.com/hedisam/goactor"
+	"github.com/hedisam/goactor/clock"
+	"github.com/hedisam/goactor/reason"
+	"log"
+	"time"
+)
+
+// timeoutMsg is sent to the machine by its timers. seq tells a fired timer apart from the one which replaced it.
+type timeoutMsg struct {
+	seq     uint64
+	state   bool
+	name    string
+	content interface{}
+}
+
+type timer struct {
+	seq   uint64
+	timer clock.Timer
+}
+
+type machine struct {
+	actor *goactor.Actor
+	def   Machine
+	state State
+	data  interface{}
+
+	// queue holds the events to handle before receiving another message, which are the postponed events after a
+	// state change.
+	queue     []Event
+	postponed []Event
+
+	seq          uint64
+	stateTimer   *timer
+	genericTimer map[string]*timer
+
+	exitReason reason.Reason
+}
+
+func actorFunc(def Machine) goactor.ActorFunc {
+	return func(actor *goactor.Actor) {
+		m := &machine{actor: actor, def: def, genericTimer: make(map[string]*timer)}
+		defer m.stopTimers()
+
+		if m.init() {
+			_ = actor.Receive(m.handle)
+		}
+		if m.exitReason != nil && m.exitReason != reason.Normal {
+			panic(m.exitReason)
+		}
+	}
+}
+
+func (m *machine) init() bool {
+	state, data, actions := m.def.Init()
+	m.state = state
+	m.data = data
+	m.takeActions(Event{}, actions)
+	if m.def.StateEnter {
+		return m.enter(state)
+	}
+	return true
+}
+
+func (m *machine) handle(message interface{}) (loop bool) {
+	var event Event
+	switch msg := message.(type) {
+	case callRequest:
+		event = Event{Type: CallEvent, Content: msg.request, From: msg.requester}
+	case castRequest:
+		event = Event{Type: CastEvent, Content: msg.message}
+	case timeoutMsg:
+		var ok bool
+		event, ok = m.timeout(msg)
+		if !ok {
+			return true
+		}
+	default:
+		event = Event{Type: InfoEvent, Content: message}
+	}
+
+	m.queue = append(m.queue, event)
+	for len(m.queue) > 0 {
+		event = m.queue[0]
+		m.queue = m.queue[1:]
+		if !m.handleEvent(event) {
+			return false
+		}
+	}
+	return true
+}
+
+// timeout returns the event of a timer which has fired, unless the timer has been cancelled or replaced.
+func (m *machine) timeout(msg timeoutMsg) (Event, bool) {
+	if msg.state {
+		if m.stateTimer == nil || m.stateTimer.seq != msg.seq {
+			return Event{}, false
+		}
+		m.stateTimer = nil
+		return Event{Type: StateTimeoutEvent, Content: msg.content}, true
+	}
+	t, ok := m.genericTimer[msg.name]
+	if !ok || t.seq != msg.seq {
+		return Event{}, false
+	}
+	delete(m.genericTimer, msg.name)
+	return Event{Type: GenericTimeoutEvent, Content: msg.content, Name: msg.name}, true
+}
+
+func (m *machine) handleEvent(event Event) bool {
+	tr := m.call(event)
+	if tr.stop != nil {
+		m.takeActions(event, tr.actions)
+		m.exitReason = tr.stop
+		return false
+	}
+	if !tr.keepData {
+		m.data = tr.data
+	}
+
+	changed := !tr.keep && tr.next != m.state
+	previous := m.state
+	if changed {
+		m.cancelStateTimer()
+		m.state = tr.next
+	}
+	if m.takeActions(event, tr.actions) {
+		m.postponed = append(m.postponed, event)
+	}
+	if changed {
+		m.queue = append(m.postponed, m.queue...)
+		m.postponed = nil
+		if m.def.StateEnter {
+			return m.enter(previous)
+		}
+	}
+	return true
+}
+
+// enter delivers an enter event to the current state.
+func (m *machine) enter(previous State) bool {
+	event := Event{Type: EnterEvent, Content: previous}
+	tr := m.call(event)
+	if tr.stop == nil && !tr.keep && tr.next != m.state {
+		tr = Stop(reason.FromError(fmt.Errorf("%w: %s -> %s", ErrStateChangeOnEnter, m.state, tr.next)))
+	}
+	if tr.stop != nil {
+		m.takeActions(event, tr.actions)
+		m.exitReason = tr.stop
+		return false
+	}
+	if !tr.keepData {
+		m.data = tr.data
+	}
+	m.takeActions(event, tr.actions)
+	return true
+}
+
+func (m *machine) call(event Event) Transition {
+	fn, ok := m.def.States[m.state]
+	if !ok {
+		panic(fmt.Errorf("%w: %s", ErrUnknownState, m.state))
+	}
+	return fn(event, m.data)
+}
+
+// takeActions takes the actions and returns true if the event is postponed.
+func (m *machine) takeActions(event Event, actions []Action) (postponed bool) {
+	for _, action := range actions {
+		switch a := action.(type) {
+		case postpone:
+			postponed = event.Type != EnterEvent
+		case reply:
+			err := goactor.Send(a.to, callResponse{value: a.value})
+			if err != nil {
+				log.Printf("[!] statem %s couldn't send back a reply: %v\n", m.actor.Self().ID(), err)
+			}
+		case stateTimeout:
+			m.cancelStateTimer()
+			if !a.cancel {
+				m.stateTimer = m.startTimer(timeoutMsg{state: true, content: a.content}, a.after)
+			}
+		case genericTimeout:
+			if t, ok := m.genericTimer[a.name]; ok {
+				t.timer.Stop()
+				delete(m.genericTimer, a.name)
+			}
+			if !a.cancel {
+				m.genericTimer[a.name] = m.startTimer(timeoutMsg{name: a.name, content: a.content}, a.after)
+			}
+		}
+	}
+	return postponed
+}
+
+func (m *machine) startTimer(msg timeoutMsg, after time.Duration) *timer {
+	m.seq++
+	msg.seq = m.seq
+	self := m.actor.Self()
+	return &timer{seq: msg.seq, timer: clock.OrReal(m.def.Clock).AfterFunc(after, func() {
+		// the machine could have stopped, there's nothing to do if it fails.
+		_ = goactor.Send(self, msg)
+	})}
+}
+
+func (m *machine) cancelStateTimer() {
+	if m.stateTimer != nil {
+		m.stateTimer.timer.Stop()
+		m.stateTimer = nil
+	}
+}
+
+func (m *machine) stopTimers() {
+	m.cancelStateTimer()
+	for name, t := range m.genericTimer {
+		t.timer.Stop()
+		delete(m.genericTimer, name)
+	}
+}
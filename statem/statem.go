@@ -0,0 +1,230 @@
+package statem
+
+import (
+	"fmt"
+	"github.com/hedisam/goactor"
+	"github.com/hedisam/goactor/clock"
+	p "github.com/hedisam/goactor/pid"
+	"github.com/hedisam/goactor/reason"
+	"github.com/hedisam/goactor/supervisor/spec"
+	"time"
+)
+
+var ErrUnknownState = fmt.Errorf("statem: no func for state")
+var ErrStateChangeOnEnter = fmt.Errorf("statem: state can not be changed by an enter event")
+var ErrUnknownResponse = fmt.Errorf("statem: unknown response")
+
+// State is the name of a state machine's state.
+type State string
+
+type EventType int
+
+const (
+	// EnterEvent is delivered to a state's func when the machine enters the state, if Machine.StateEnter is set.
+	// Its content is the previous state, or the state itself for the initial state.
+	EnterEvent EventType = iota
+	// CallEvent is a request made by Call. The caller waits for a Reply action.
+	CallEvent
+	// CastEvent is a message sent by Cast.
+	CastEvent
+	// InfoEvent is any other message sent to the machine's pid.
+	InfoEvent
+	// StateTimeoutEvent is delivered once a state timeout fires.
+	StateTimeoutEvent
+	// GenericTimeoutEvent is delivered once a generic timeout fires.
+	GenericTimeoutEvent
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EnterEvent:
+		return "enter"
+	case CallEvent:
+		return "call"
+	case CastEvent:
+		return "cast"
+	case InfoEvent:
+		return "info"
+	case StateTimeoutEvent:
+		return "state_timeout"
+	case GenericTimeoutEvent:
+		return "generic_timeout"
+	}
+	return fmt.Sprintf("EventType(%d)", int(t))
+}
+
+// Event is an event handled by a state's func.
+type Event struct {
+	Type EventType
+	// Content is the message of call, cast and info events, the previous state of enter events, and the content of
+	// timeout events.
+	Content interface{}
+	// From is the caller of a call event.
+	From *p.PID
+	// Name is the name of a generic timeout event.
+	Name string
+}
+
+// StateFunc handles the events the machine receives while it's in a state.
+type StateFunc func(event Event, data interface{}) Transition
+
+// Machine declares a state machine.
+type Machine struct {
+	// Init returns the initial state and data, and the actions to take. It's called each time the machine is started,
+	// e.g. when it's restarted by its supervisor.
+	Init func() (State, interface{}, []Action)
+	// States holds the func of each state.
+	States map[State]StateFunc
+	// StateEnter enables enter events.
+	StateEnter bool
+	// Clock measures the state and generic timeouts. The real clock is used if it's nil.
+	Clock clock.Clock
+}
+
+// Transition is returned by a state's func to tell the machine what to do next.
+type Transition struct {
+	next     State
+	keep     bool
+	data     interface{}
+	keepData bool
+	actions  []Action
+	stop     reason.Reason
+}
+
+// NextState moves the machine to the given state. The postponed events are delivered again, and the state timeout is
+// cancelled, if the state is not the current one.
+func NextState(state State, data interface{}, actions ...Action) Transition {
+	return Transition{next: state, data: data, actions: actions}
+}
+
+// KeepState keeps the current state and replaces the data.
+func KeepState(data interface{}, actions ...Action) Transition {
+	return Transition{keep: true, data: data, actions: actions}
+}
+
+// KeepStateAndData keeps both the current state and data.
+func KeepStateAndData(actions ...Action) Transition {
+	return Transition{keep: true, keepData: true, actions: actions}
+}
+
+// Stop stops the machine with the given reason, once the actions are taken.
+func Stop(exitReason reason.Reason, actions ...Action) Transition {
+	return Transition{stop: exitReason, actions: actions}
+}
+
+// Action is an action taken by the machine after a transition.
+type Action interface {
+	action()
+}
+
+type postpone struct{}
+
+type reply struct {
+	to    *p.PID
+	value interface{}
+}
+
+type stateTimeout struct {
+	after   time.Duration
+	content interface{}
+	cancel  bool
+}
+
+type genericTimeout struct {
+	name    string
+	after   time.Duration
+	content interface{}
+	cancel  bool
+}
+
+func (postpone) action()       {}
+func (reply) action()          {}
+func (stateTimeout) action()   {}
+func (genericTimeout) action() {}
+
+// Postpone postpones the current event until the machine moves to another state. It's ignored for enter events.
+func Postpone() Action {
+	return postpone{}
+}
+
+// Reply replies to the caller of a call event.
+func Reply(to *p.PID, value interface{}) Action {
+	return reply{to: to, value: value}
+}
+
+// StateTimeout delivers a state timeout event with the given content after the given duration, unless the machine
+// moves to another state first. It replaces the running state timeout, if any.
+func StateTimeout(after time.Duration, content interface{}) Action {
+	return stateTimeout{after: after, content: content}
+}
+
+// CancelStateTimeout cancels the running state timeout.
+func CancelStateTimeout() Action {
+	return stateTimeout{cancel: true}
+}
+
+// GenericTimeout delivers a generic timeout event with the given name and content after the given duration. It's
+// not cancelled by state changes, and replaces the running timeout of the same name.
+func GenericTimeout(name string, after time.Duration, content interface{}) Action {
+	return genericTimeout{name: name, after: after, content: content}
+}
+
+// CancelGenericTimeout cancels the running generic timeout of the given name.
+func CancelGenericTimeout(name string) Action {
+	return genericTimeout{name: name, cancel: true}
+}
+
+type callRequest struct {
+	request   interface{}
+	requester *p.PID
+}
+
+type castRequest struct {
+	message interface{}
+}
+
+type callResponse struct {
+	value interface{}
+}
+
+// Spawn spawns the state machine.
+func Spawn(machine Machine, mailboxBuilder goactor.MailboxBuilderFunc) *p.PID {
+	return goactor.Spawn(actorFunc(machine), mailboxBuilder)
+}
+
+// ChildSpec returns a worker spec which starts the state machine under a supervisor. The machine is registered by
+// the given name, and each restart begins with a fresh call to Machine.Init.
+func ChildSpec(name string, restartWhen int, machine Machine) spec.WorkerSpec {
+	return spec.NewWorkerSpec(name, restartWhen, actorFunc(machine))
+}
+
+// Call sends a call event to the machine and waits for its reply.
+func Call(pid *p.PID, request interface{}, timeout time.Duration) (interface{}, error) {
+	future := goactor.NewFutureActor()
+	err := goactor.Send(pid, callRequest{request: request, requester: future.Self()})
+	if err != nil {
+		return nil, fmt.Errorf("statem: couldn't deliver call request: %w", err)
+	}
+
+	var resp *callResponse
+	var respErr error
+	err = future.ReceiveWithTimeout(timeout, func(message interface{}) (loop bool) {
+		if r, ok := message.(callResponse); ok {
+			resp = &r
+			return false
+		}
+		respErr = fmt.Errorf("%w: %v", ErrUnknownResponse, message)
+		return false
+	})
+	if err != nil {
+		return nil, fmt.Errorf("statem: call request failed: %w", err)
+	} else if respErr != nil {
+		return nil, respErr
+	}
+	return resp.value, nil
+}
+
+// Cast sends a cast event to the machine.
+func Cast(pid *p.PID, message interface{}) error {
+	return goactor.Send(pid, castRequest{message: message})
+}
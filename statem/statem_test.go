@@ -0,0 +1,227 @@
+package statem
+
+import (
+	"errors"
+	"github.com/hedisam/goactor"
+	"github.com/hedisam/goactor/clock"
+	"github.com/hedisam/goactor/process"
+	"github.com/hedisam/goactor/reason"
+	"github.com/hedisam/goactor/supervisor"
+	"github.com/hedisam/goactor/supervisor/option"
+	"github.com/hedisam/goactor/supervisor/spec"
+	"github.com/hedisam/goactor/sysmsg"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+const (
+	locked State = "locked"
+	open   State = "open"
+)
+
+// door is locked until the right code is entered, and locks itself again once its state timeout fires. enters gets
+// the states the door enters.
+func door(code string, openFor time.Duration, enters chan<- State) Machine {
+	return Machine{
+		Init: func() (State, interface{}, []Action) {
+			return locked, "", nil
+		},
+		StateEnter: enters != nil,
+		States: map[State]StateFunc{
+			locked: func(event Event, data interface{}) Transition {
+				switch event.Type {
+				case EnterEvent:
+					enters <- locked
+					return KeepState("")
+				case CastEvent:
+					entered := data.(string) + event.Content.(string)
+					if entered == code {
+						return NextState(open, "", StateTimeout(openFor, "lock"))
+					}
+					return KeepState(entered)
+				case CallEvent:
+					if event.Content == "wait until open" {
+						return KeepStateAndData(Postpone())
+					}
+					return KeepStateAndData(Reply(event.From, locked))
+				}
+				return KeepStateAndData()
+			},
+			open: func(event Event, data interface{}) Transition {
+				switch event.Type {
+				case EnterEvent:
+					enters <- open
+					return KeepStateAndData()
+				case StateTimeoutEvent:
+					return NextState(locked, "")
+				case CallEvent:
+					if event.Content == "stop" {
+						return Stop(reason.Normal, Reply(event.From, "stopped"))
+					}
+					return KeepStateAndData(Reply(event.From, open))
+				}
+				return KeepStateAndData()
+			},
+		},
+	}
+}
+
+func TestMachine(t *testing.T) {
+	pid := Spawn(door("123", 50*time.Millisecond, nil), nil)
+
+	state, err := Call(pid, "state", time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, locked, state)
+
+	for _, digit := range []string{"1", "2", "3"} {
+		assert.Nil(t, Cast(pid, digit))
+	}
+	state, err = Call(pid, "state", time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, open, state)
+
+	// the state timeout locks the door
+	assert.Eventually(t, func() bool {
+		state, err := Call(pid, "state", time.Second)
+		return err == nil && state == locked
+	}, time.Second, 5*time.Millisecond)
+
+	for _, digit := range []string{"1", "2", "3"} {
+		assert.Nil(t, Cast(pid, digit))
+	}
+	value, err := Call(pid, "stop", time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, "stopped", value)
+	assert.Eventually(t, func() bool {
+		return goactor.Send(pid, "ping") != nil
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestMachine_Postpone(t *testing.T) {
+	pid := Spawn(door("1", time.Hour, nil), nil)
+
+	result := make(chan interface{}, 1)
+	go func() {
+		value, err := Call(pid, "wait until open", time.Second)
+		assert.Nil(t, err)
+		result <- value
+	}()
+
+	// the postponed call is only handled once the door is open
+	select {
+	case <-result:
+		t.Fatal("the postponed call was handled before the state changed")
+	case <-time.After(20 * time.Millisecond):
+	}
+	assert.Nil(t, Cast(pid, "1"))
+	select {
+	case value := <-result:
+		assert.Equal(t, open, value)
+	case <-time.After(time.Second):
+		t.Error("the postponed call was not handled after the state changed")
+	}
+}
+
+func TestMachine_StateEnter(t *testing.T) {
+	enters := make(chan State, 10)
+	pid := Spawn(door("1", 10*time.Millisecond, enters), nil)
+
+	assert.Nil(t, Cast(pid, "1"))
+	for _, expected := range []State{locked, open, locked} {
+		select {
+		case state := <-enters:
+			assert.Equal(t, expected, state)
+		case <-time.After(time.Second):
+			t.Fatalf("the machine didn't enter %s", expected)
+		}
+	}
+}
+
+func TestMachine_GenericTimeout(t *testing.T) {
+	const idle State = "idle"
+	fired := make(chan string, 10)
+	c := clock.NewFake(time.Now())
+	pid := Spawn(Machine{
+		Init: func() (State, interface{}, []Action) {
+			return idle, nil, []Action{
+				GenericTimeout("first", 10*time.Millisecond, "first"),
+				GenericTimeout("cancelled", 10*time.Millisecond, "cancelled"),
+			}
+		},
+		States: map[State]StateFunc{
+			idle: func(event Event, data interface{}) Transition {
+				switch event.Type {
+				case CastEvent:
+					return KeepStateAndData(CancelGenericTimeout("cancelled"))
+				case CallEvent:
+					return KeepStateAndData(Reply(event.From, len(fired)))
+				case GenericTimeoutEvent:
+					fired <- event.Name
+				}
+				return KeepStateAndData()
+			},
+		},
+		Clock: c,
+	}, nil)
+	assert.Nil(t, Cast(pid, "cancel"))
+	assert.Eventually(t, func() bool {
+		return c.Timers() == 1
+	}, time.Second, time.Millisecond)
+
+	// nothing fires until the clock is advanced
+	count, err := Call(pid, "fired", time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, count)
+	c.Advance(10 * time.Millisecond)
+	count, err = Call(pid, "fired", time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, "first", <-fired)
+	assert.Equal(t, 0, c.Timers())
+}
+
+func TestMachine_UnknownState(t *testing.T) {
+	parent, dispose := goactor.NewParentActor(nil)
+	defer dispose()
+
+	_, _, err := goactor.SpawnMonitor(parent, actorFunc(Machine{
+		Init: func() (State, interface{}, []Action) {
+			return "missing", nil, nil
+		},
+		StateEnter: true,
+	}), nil)
+	if !assert.Nil(t, err) {return}
+	err = parent.ReceiveWithTimeout(time.Second, func(message interface{}) (loop bool) {
+		down, ok := message.(sysmsg.Down)
+		if !assert.True(t, ok) {return false}
+		assert.True(t, errors.Is(down.Reason, ErrUnknownState))
+		return false
+	})
+	assert.Nil(t, err)
+}
+
+func TestChildSpec(t *testing.T) {
+	name := "supervised door"
+	_, err := supervisor.Start(option.OneForOneStrategyOption(), ChildSpec(name, spec.RestartAlways, door("1", time.Hour, nil)))
+	if !assert.Nil(t, err) {return}
+
+	pid, ok := process.WhereIs(name)
+	if !assert.True(t, ok) {return}
+	assert.Nil(t, Cast(pid, "1"))
+	state, err := Call(pid, "state", time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, open, state)
+
+	// the machine is restarted in its initial state
+	_, err = Call(pid, "stop", time.Second)
+	assert.Nil(t, err)
+	assert.Eventually(t, func() bool {
+		restarted, ok := process.WhereIs(name)
+		if !ok || restarted.ID() == pid.ID() {
+			return false
+		}
+		state, err := Call(restarted, "state", time.Second)
+		return err == nil && state == locked
+	}, time.Second, 5*time.Millisecond)
+}
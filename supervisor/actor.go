@@ -1,26 +1,50 @@
 package supervisor
 
 import (
+	"context"
 	"fmt"
 	"github.com/hedisam/goactor/internal/intlpid"
+	"github.com/hedisam/goactor/internal/pidconv"
+	"github.com/hedisam/goactor/internal/relations"
 	p "github.com/hedisam/goactor/pid"
-	"github.com/hedisam/goactor/supervisor/models"
+	"github.com/hedisam/goactor/reason"
 	"github.com/hedisam/goactor/sysmsg"
 	"log"
 )
 
+type relationManager interface {
+	AddLink(pid intlpid.InternalPID) error
+	RemoveLink(pid intlpid.InternalPID) error
+
+	LinkedActors() *relations.RelationIterator
+	MonitorActors() *relations.MonitorIterator
+
+	Dispose()
+}
+
+type supervisorMailbox interface {
+	Receive(msgHandler, sysMsgHandler func(interface{}) bool) error
+	Dispose()
+}
+
 type Supervisor struct {
-	relationManager models.RelationManager
-	mailbox         models.Mailbox
+	relationManager relationManager
+	mailbox         supervisorMailbox
 	self            *p.PID
+	// ctx is passed down to the children, it's cancelled once the supervisor exits or its parent context is cancelled.
+	ctx       context.Context
+	ctxCancel func()
+	parent    context.Context
 }
 
-func newSupervisorActor(mailbox models.Mailbox, self intlpid.InternalPID, manager models.RelationManager) *Supervisor {
+func newSupervisorActor(ctx context.Context, mb supervisorMailbox, self intlpid.InternalPID, manager relationManager) *Supervisor {
 	sup := &Supervisor{
-		mailbox:         mailbox,
+		mailbox:         mb,
 		relationManager: manager,
-		self:            p.ToPID(self),
+		self:            pidconv.ToPID(self),
+		parent:          ctx,
 	}
+	sup.ctx, sup.ctxCancel = context.WithCancel(ctx)
 	return sup
 }
 
@@ -32,64 +56,111 @@ func (sup *Supervisor) Self() *p.PID {
 	return sup.self
 }
 
+// Context returns the supervisor's context.
+func (sup *Supervisor) Context() context.Context {
+	return sup.ctx
+}
+
+// parentCancelled returns true if the supervisor's parent context has been cancelled.
+func (sup *Supervisor) parentCancelled() bool {
+	return sup.parent.Err() != nil
+}
+
+// watchParent sends a ShutdownCMD to the supervisor once its parent context is cancelled. Like an actor's, the
+// context is watched by a plain goroutine, so under sched.Run the ShutdownCMD isn't deterministically interleaved.
+func (sup *Supervisor) watchParent() {
+	if sup.parent.Done() == nil {
+		return
+	}
+	go func() {
+		<-sup.ctx.Done()
+		if !sup.parentCancelled() {
+			return
+		}
+		cmd := sysmsg.NewShutdownCMD(sup.self, reason.Shutdown, nil)
+		_ = intlpid.SendSystemMessage(pidconv.Internal(sup.self), cmd)
+	}()
+}
+
 func (sup *Supervisor) Receive(handler func(message interface{}) (loop bool)) {
 	sup.mailbox.Receive(handler, handler)
 }
 
 func (sup *Supervisor) Link(pid *p.PID) error {
 	// first we need to ask the other actor to link to this actor.
-	err := intlpid.Link(pid.InternalPID(), sup.self.InternalPID())
+	err := intlpid.Link(pidconv.Internal(pid), pidconv.Internal(sup.self))
 	if err != nil {
 		return fmt.Errorf("failed to add this supervisor to the child's linked actors list: %w", err)
 	}
 	// add the target actor to our linked actors list
-	sup.relationManager.AddLink(pid.InternalPID())
+	sup.relationManager.AddLink(pidconv.Internal(pid))
 	return nil
 }
 
 func (sup *Supervisor) Unlink(pid *p.PID) error {
 	// attempt to remove the link from the other actor
-	err := intlpid.Unlink(pid.InternalPID(), sup.self.InternalPID())
+	err := intlpid.Unlink(pidconv.Internal(pid), pidconv.Internal(sup.self))
 	if err != nil {
 		return fmt.Errorf("failed to remove this supervisor from the child's linked actors list: %w", err)
 	}
 	// remove the target actor from our linked actors list
-	sup.relationManager.RemoveLink(pid.InternalPID())
+	sup.relationManager.RemoveLink(pidconv.Internal(pid))
 	return nil
 }
 
+// discard releases the mailbox, the relations and the context of a supervisor which has never been spawned, e.g.
+// because it couldn't be linked to its parent.
+func (sup *Supervisor) discard() {
+	sup.ctxCancel()
+	sup.mailbox.Dispose()
+	sup.relationManager.Dispose()
+}
+
 func (sup *Supervisor) systemMessageHandler(_ interface{}) (loop bool) {
 	return true
 }
 
 func (sup *Supervisor) dispose(service *Service) {
+	sup.ctxCancel()
 	sup.mailbox.Dispose()
 
 	var msg sysmsg.SystemMessage
 	switch r := recover().(type) {
 	case sysmsg.AbnormalExit:
 		log.Println("[----] supervisor dispose: recovered from an AbnormalExit")
+		msg = sysmsg.NewAbnormalExitMsg(sup.self, r.Reason(), &r)
 	case sysmsg.NormalExit:
 		log.Println("[----] supervisor dispose: recovered from a NormalExit")
+		msg = sysmsg.NewNormalExitMsg(sup.self, &r)
 	case sysmsg.KillExit:
 		log.Println("[----] supervisor dispose: recovered from a KillExit")
+		msg = sysmsg.NewKillMessage(sup.self, r.Reason(), &r)
 	case sysmsg.ShutdownCMD:
 		log.Println("[----] supervisor dispose: recovered fro a ShutdownCMD")
+		if sup.parentCancelled() {
+			// like an actor whose parent context is cancelled, the supervisor exits with the shutdown reason. a
+			// ShutdownCMD would make a parent supervisor shut down as well.
+			msg = sysmsg.NewAbnormalExitMsg(sup.self, reason.Shutdown, &r)
+			break
+		}
+		msg = sysmsg.NewShutdownCMD(sup.self, reason.Shutdown, &r)
 	default:
 		if r != nil {
 			// something abnormal has happened.
 			log.Printf("[----] supervisor dispose: %v had a panic, reason: %v\n", sup.self.ID(), r)
-			msg = sysmsg.NewAbnormalExitMsg(sup.self.InternalPID(), r, nil)
+			msg = sysmsg.NewAbnormalExitMsg(sup.self, reason.FromPanic(r), nil)
 			// the supervisor hasn't had the chance to shutdown its children, so we explicitly do this.
 			// shutting down the children makes them unlinked, too. Therefore, those related actors who remain linked
 			// would be non-child actors for the supervisor which will get notified about the supervisor's exit.
 			service.ShutdownChildren(msg)
 		} else {
 			// it's just a normal exit
-			msg = sysmsg.NewNormalExitMsg(sup.self.InternalPID(), nil)
+			msg = sysmsg.NewNormalExitMsg(sup.self, nil)
 		}
 	}
 
+	// no relation can be added once the related actors are being notified, the ones added from now on fail instead.
+	sup.relationManager.Dispose()
 	sup.notifyRelatedActors(msg)
 }
 
@@ -100,12 +171,26 @@ func (sup *Supervisor) notifyRelatedActors(msg sysmsg.SystemMessage) {
 	}
 	monitorIterator := sup.relationManager.MonitorActors()
 	for monitorIterator.HasNext() {
-		sup.notify(monitorIterator.Value(), msg)
+		monitor := monitorIterator.Value()
+		sup.notifyMonitor(monitor, msg)
+	}
+}
+
+func (sup *Supervisor) notifyMonitor(monitor relations.Monitor, msg sysmsg.SystemMessage) {
+	down := sysmsg.Down{
+		Ref:    sysmsg.MonitorRef(monitor.Ref),
+		PID:    sup.self,
+		Reason: msg.Reason(),
+	}
+	err := intlpid.SendSystemMessage(monitor.PID, down)
+	if err != nil {
+		log.Printf("supervisor: notifyRelatedActors: could not deliver down message to pid: %v, sender: %v, err: %v\n",
+			monitor.PID.ID(), sup.self.ID(), err)
 	}
 }
 
 func (sup *Supervisor) notify(pid intlpid.InternalPID, msg sysmsg.SystemMessage) {
-	if msg.Origin() != nil && msg.Origin().Sender() == pid {
+	if msg.Origin() != nil && pidconv.Internal(msg.Origin().Sender()) == pid {
 		return
 	}
 	err := intlpid.SendSystemMessage(pid, msg)
@@ -1,6 +1,10 @@
 package childstate
 
-import "github.com/hedisam/goactor/internal/intlpid"
+import (
+	"github.com/hedisam/goactor/internal/intlpid"
+	"github.com/hedisam/goactor/internal/pidconv"
+	p "github.com/hedisam/goactor/pid"
+)
 
 type ChildrenManager struct {
 	children map[string]*ChildState
@@ -23,17 +27,17 @@ func (manager *ChildrenManager) Iterator() *ChildrenStateIterator {
 	return newChildrenStateIterator(states)
 }
 
-func (manager *ChildrenManager) Index(pid intlpid.InternalPID, name string) {
-	manager.index[pid] = name
+func (manager *ChildrenManager) Index(pid *p.PID, name string) {
+	manager.index[pidconv.Internal(pid)] = name
 }
 
-func (manager *ChildrenManager) SearchIndex(pid intlpid.InternalPID) (string, bool) {
-	name, ok := manager.index[pid]
+func (manager *ChildrenManager) SearchIndex(pid *p.PID) (string, bool) {
+	name, ok := manager.index[pidconv.Internal(pid)]
 	return name, ok
 }
 
-func (manager *ChildrenManager) RemoveIndex(pid intlpid.InternalPID) {
-	delete(manager.index, pid)
+func (manager *ChildrenManager) RemoveIndex(pid *p.PID) {
+	delete(manager.index, pidconv.Internal(pid))
 }
 
 func (manager *ChildrenManager) Put(name string, state *ChildState) {
@@ -45,7 +49,7 @@ func (manager *ChildrenManager) Get(name string) (*ChildState, bool) {
 	return state, ok
 }
 
-func (manager *ChildrenManager) GetByPID(pid intlpid.InternalPID) (*ChildState, bool) {
+func (manager *ChildrenManager) GetByPID(pid *p.PID) (*ChildState, bool) {
 	name, ok := manager.SearchIndex(pid)
 	if !ok {
 		return nil, false
@@ -1,10 +1,14 @@
 package childstate
 
 import (
+	"errors"
 	"fmt"
+	"github.com/hedisam/goactor/internal/fault"
 	"github.com/hedisam/goactor/internal/intlpid"
+	"github.com/hedisam/goactor/internal/pidconv"
 	p "github.com/hedisam/goactor/pid"
 	"github.com/hedisam/goactor/process"
+	"github.com/hedisam/goactor/reason"
 	"github.com/hedisam/goactor/sysmsg"
 	"log"
 	"time"
@@ -65,7 +69,7 @@ func (child *ChildState) Restart() error {
 		return fmt.Errorf("failed to Restart the child #%s: %w", child.spec.Name(), err)
 	}
 	// add a restart timestamp to the list
-	child.restarts = append(child.restarts, time.Now().Unix())
+	child.restarts = append(child.restarts, child.supService.Clock().Now().Unix())
 
 	return nil
 }
@@ -73,24 +77,57 @@ func (child *ChildState) Restart() error {
 // Start spawns a new child process for the given child spec, which is will be linked to the supervisor.
 // The child spec can be a worker actor or a supervisor.
 func (child *ChildState) Start() error {
-	// invoke the function that spawns the child process
-	pid, err := child.spec.StartLink()
+	// faults can be injected into the start by the chaos package
+	hook := fault.CurrentStartHook()
+	if hook != nil {
+		err := hook.BeforeStart(child.supService.Self(), child.Name())
+		if err != nil {
+			return fmt.Errorf("supervisor failed to Start the child #%s: %w", child.spec.Name(), err)
+		}
+	}
+
+	// invoke the function that spawns the child process. the child gets linked to the supervisor before it starts
+	// running, so we won't miss its exit message if it exits right away.
+	pid, err := child.spec.StartLink(child.supService)
 	if err != nil {
 		return fmt.Errorf("supervisor failed to Start the child #%s: %w", child.spec.Name(), err)
 	}
 	child.self = pid
-	// link the supervisor to the child
-	err = child.supService.Link(pid)
-	if err != nil {
-		return fmt.Errorf("supervisor failed linking to child #%s: %w", child.spec.Name(), err)
-	}
 
 	// index the internal_pid
-	child.childrenManager.Index(pid.InternalPID(), child.Name())
+	child.childrenManager.Index(pid, child.Name())
 	child.dead = false
 
 	// register the child in the process registry by its name
-	process.Register(child.Name(), pid)
+	err = process.Register(child.Name(), pid)
+	if errors.Is(err, process.ErrNameTaken) {
+		// the name belongs to another actor, e.g. a child of another supervisor with the same spec name. the new child
+		// would be unreachable by its name, so it's stopped.
+		child.Shutdown(sysmsg.NewShutdownCMD(child.supService.Self(), reason.Shutdown, nil))
+		// it's been declared dead by Shutdown already, DisposeChild unlinks it.
+		child.supService.DisposeChild(child)
+		return fmt.Errorf("supervisor failed to register the child #%s: %w", child.Name(), err)
+	} else if err != nil {
+		// a child which has already exited can't be registered. its exit message is handled by the supervisor.
+		log.Printf("[!] supervisor failed to register the child #%s: %v\n", child.Name(), err)
+	}
+	if registry := child.via(); registry != nil {
+		err = registry.RegisterName(child.Name(), pid)
+		if err != nil {
+			log.Printf("[!] supervisor failed to register the child #%s via its registry: %v\n", child.Name(), err)
+		}
+	}
+	if hook != nil {
+		hook.Started(child.supService.Self(), child.Name(), pid)
+	}
+	return nil
+}
+
+// via returns the registry the child is registered to, other than the default one, if any.
+func (child *ChildState) via() process.Registry {
+	if spec, ok := child.spec.(viaSpec); ok {
+		return spec.Via()
+	}
 	return nil
 }
 
@@ -100,7 +137,7 @@ func (child *ChildState) hasReachedMaxRestarts() bool {
 	// restarts that are not expired, meaning they are in the same last Period
 	var restartsNotEx []int64
 
-	now := time.Now()
+	now := child.supService.Clock().Now()
 	periodStartTime := now.Add(time.Duration(-1*child.supService.RestartsPeriod()) * time.Second).Unix()
 	// check how many restarts we've got in the same Period
 	for _, restartTime := range child.restarts {
@@ -129,7 +166,13 @@ func (child *ChildState) Shutdown(reason sysmsg.SystemMessage) {
 		return
 	}
 	child.DeclareDead()
-	intlpid.Shutdown(child.self.InternalPID(), reason)
+	if child.IsSupervisor() {
+		// a supervisor has no shutdown func, it can only be shut down by a ShutdownCMD.
+		cmd := sysmsg.NewShutdownCMD(child.supService.Self(), reason.Reason(), nil)
+		_ = intlpid.SendSystemMessage(pidconv.Internal(child.self), cmd)
+		return
+	}
+	intlpid.Shutdown(pidconv.Internal(child.self), reason)
 }
 
 // DeclareDead removes the child's pid from the children manager's index and unregisters the process from the
@@ -138,9 +181,15 @@ func (child *ChildState) Shutdown(reason sysmsg.SystemMessage) {
 // we can treat that message as an invalid one and do nothing.
 // This way we show that we're only interested in the new pid, or new respawned actor.
 func (child *ChildState) DeclareDead() {
-	child.childrenManager.RemoveIndex(child.self.InternalPID())
+	child.childrenManager.RemoveIndex(child.self)
 	child.dead = true
-	process.Unregister(child.Name())
+	// the name could have been taken by another actor after the child exited.
+	if name, ok := process.NameOf(child.self); ok && name == child.Name() {
+		process.Unregister(child.Name())
+	}
+	if registry := child.via(); registry != nil {
+		registry.UnregisterName(child.Name(), child.self)
+	}
 }
 
 func NewChildState(spec Spec, supRef supService, manager *ChildrenManager) *ChildState {
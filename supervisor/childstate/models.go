@@ -1,17 +1,29 @@
 package childstate
 
-import "github.com/hedisam/goactor/pid"
+import (
+	"github.com/hedisam/goactor"
+	"github.com/hedisam/goactor/clock"
+	"github.com/hedisam/goactor/pid"
+	"github.com/hedisam/goactor/process"
+)
 
 type supService interface {
+	Self() *pid.PID
 	Link(*pid.PID) error
 	RestartsPeriod() int
+	Clock() clock.Clock
 	MaxRestartsAllowed() int
 	MaxRestartsReached()
 	DisposeChild(*ChildState)
 }
 
 type Spec interface {
-	StartLink() (*pid.PID, error)
+	StartLink(parent goactor.Linker) (*pid.PID, error)
 	RestartWhen() int
 	Name() string
 }
+
+// viaSpec is implemented by the specs whose child is registered to another registry too.
+type viaSpec interface {
+	Via() process.Registry
+}
@@ -1,8 +1,8 @@
 package handler
 
 import (
-	"github.com/hedisam/goactor/internal/intlpid"
 	p "github.com/hedisam/goactor/pid"
+	"github.com/hedisam/goactor/reason"
 	"github.com/hedisam/goactor/supervisor/childstate"
 	"github.com/hedisam/goactor/supervisor/models"
 	"github.com/hedisam/goactor/sysmsg"
@@ -11,7 +11,7 @@ import (
 type supervisorService interface {
 	Init() error
 	Strategy() models.StrategyHandler
-	GetChildByPID(pid intlpid.InternalPID) (*childstate.ChildState, bool)
+	GetChildByPID(pid *p.PID) (*childstate.ChildState, bool)
 	DisposeChild(state *childstate.ChildState)
 	Shutdown(reason sysmsg.SystemMessage)
 	Self() *p.PID
@@ -22,3 +22,16 @@ const (
 	RestartTransient
 	RestartNever
 )
+
+// shouldRestart returns true if a child with the given restart type must be restarted after exiting with the given
+// reason. Transient children are only restarted if they exit with an unexpected reason.
+func shouldRestart(restartWhen int, exitReason reason.Reason) bool {
+	switch restartWhen {
+	case RestartAlways:
+		return true
+	case RestartTransient:
+		return !reason.IsNormal(exitReason)
+	default:
+		return false
+	}
+}
@@ -2,7 +2,6 @@ package handler
 
 import (
 	"github.com/hedisam/goactor"
-	p "github.com/hedisam/goactor/pid"
 	"github.com/hedisam/goactor/sysmsg"
 	"log"
 )
@@ -17,8 +16,7 @@ func NewInitHandler(s supervisorService) *InitHandler {
 
 func (h *InitHandler) Run(update sysmsg.SystemMessage) bool {
 	initErr := h.service.Init()
-	sender := p.ToPID(update.Sender())
-	err := goactor.Send(sender, initErr)
+	err := goactor.Send(update.Sender(), initErr)
 	if err != nil {
 		log.Println("supervisor failed to send back initMsg: %w", err)
 	}
@@ -5,17 +5,12 @@ import (
 	"log"
 )
 
-var killExitHandler *KillExitHandler
-
 type KillExitHandler struct {
 	service supervisorService
 }
 
-func GetKillExitHandler(s supervisorService) *KillExitHandler {
-	if killExitHandler == nil {
-		killExitHandler = &KillExitHandler{service: s}
-	}
-	return killExitHandler
+func NewKillExitHandler(s supervisorService) *KillExitHandler {
+	return &KillExitHandler{service: s}
 }
 
 func (h *KillExitHandler) Run(update sysmsg.SystemMessage) bool {
@@ -24,18 +19,16 @@ func (h *KillExitHandler) Run(update sysmsg.SystemMessage) bool {
 		// no child found with the given internal_pid
 		return true
 	}
-	// check the child's restart type
-	switch childState.RestartWhen() {
-	case RestartAlways, RestartTransient:
-		strategyHandler := h.service.Strategy()
-		err := strategyHandler.Apply(childState)
-		if err != nil {
-			log.Printf("[!] supervisor, failed restarting child %s from an kill exit, err: %v\n",
-				childState.Name(), err)
-		}
-		break
-	case RestartNever:
+	// check the child's restart type against its exit reason
+	if !shouldRestart(childState.RestartWhen(), update.Reason()) {
 		h.service.DisposeChild(childState)
+		return true
+	}
+	strategyHandler := h.service.Strategy()
+	err := strategyHandler.Apply(childState)
+	if err != nil {
+		log.Printf("[!] supervisor, failed restarting child %s from an kill exit, err: %v\n",
+			childState.Name(), err)
 	}
 	return true
 }
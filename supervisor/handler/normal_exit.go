@@ -5,17 +5,12 @@ import (
 	"log"
 )
 
-var normalExitHandler *NormalExitHandler
-
 type NormalExitHandler struct {
 	service supervisorService
 }
 
-func GetNormalExitHandler(s supervisorService) *NormalExitHandler {
-	if normalExitHandler == nil {
-		normalExitHandler = &NormalExitHandler{service: s}
-	}
-	return normalExitHandler
+func NewNormalExitHandler(s supervisorService) *NormalExitHandler {
+	return &NormalExitHandler{service: s}
 }
 
 func (h *NormalExitHandler) Run(update sysmsg.SystemMessage) bool {
@@ -24,18 +19,16 @@ func (h *NormalExitHandler) Run(update sysmsg.SystemMessage) bool {
 		// no child found with the given internal_pid
 		return true
 	}
-	// check the child's restart type
-	switch childState.RestartWhen() {
-	case RestartAlways:
-		strategyHandler := h.service.Strategy()
-		err := strategyHandler.Apply(childState)
-		if err != nil {
-			log.Printf("[!] supervisor, failed restarting child %s from a normal exit, err: %v\n",
-				childState.Name(), err)
-		}
-		break
-	case RestartNever, RestartTransient:
+	// check the child's restart type against its exit reason
+	if !shouldRestart(childState.RestartWhen(), update.Reason()) {
 		h.service.DisposeChild(childState)
+		return true
+	}
+	strategyHandler := h.service.Strategy()
+	err := strategyHandler.Apply(childState)
+	if err != nil {
+		log.Printf("[!] supervisor, failed restarting child %s from a normal exit, err: %v\n",
+			childState.Name(), err)
 	}
 	return true
 }
@@ -2,6 +2,7 @@ package intlspec
 
 import (
 	"fmt"
+	"github.com/hedisam/goactor"
 	"github.com/hedisam/goactor/pid"
 	"github.com/hedisam/goactor/supervisor/option"
 	"strings"
@@ -10,15 +11,15 @@ import (
 type ChildType uint8
 
 type Spec interface {
-	StartLink() (*pid.PID, error)
+	StartLink(parent goactor.Linker) (*pid.PID, error)
 	SupervisorOptions() *option.Options
 	RestartWhen() int
 	Name() string
 }
 
-var DefaultSupervisorStartLink func(option.Options, ...Spec) (*pid.PID, error)
+var DefaultSupervisorStartLink func(goactor.Linker, option.Options, ...Spec) (*pid.PID, error)
 
-func SetDefaultSupStartLink(sl func(option.Options, ...Spec) (*pid.PID, error)) {
+func SetDefaultSupStartLink(sl func(goactor.Linker, option.Options, ...Spec) (*pid.PID, error)) {
 	DefaultSupervisorStartLink = sl
 }
 
@@ -1,6 +1,7 @@
 package supervisor
 
 import (
+	"github.com/hedisam/goactor/reason"
 	"github.com/hedisam/goactor/supervisor/handler"
 	"github.com/hedisam/goactor/supervisor/models"
 	"github.com/hedisam/goactor/supervisor/supref"
@@ -10,6 +11,15 @@ import (
 func listen(actor *Supervisor, service *Service) {
 
 	actor.Receive(func(message interface{}) (loop bool) {
+		switch message.(type) {
+		case sysmsg.NormalExit, sysmsg.AbnormalExit, sysmsg.KillExit:
+			if actor.parentCancelled() {
+				// the children are exiting because the parent context has been cancelled, so they must not be
+				// restarted. the supervisor shuts down instead.
+				cmd := sysmsg.NewShutdownCMD(actor.Self(), reason.Shutdown, nil)
+				return handler.NewShutdownCMDHandler(service).Run(cmd)
+			}
+		}
 		supHandler, update := getHandler(service, message)
 		return supHandler.Run(update)
 	})
@@ -22,13 +32,13 @@ func getHandler(service *Service, message interface{}) (models.SupHandler, sysms
 		return handler.NewInitHandler(service), &update
 	case sysmsg.NormalExit:
 		// some child actor has terminated normally.
-		return handler.GetNormalExitHandler(service), update
+		return handler.NewNormalExitHandler(service), update
 	case sysmsg.AbnormalExit:
 		// some child actor has exited abnormally.
-		return handler.GetAbnormalHandler(service), update
+		return handler.NewAbnormalExitHandler(service), update
 	case sysmsg.KillExit:
 		// some child actor(supervisor) has killed its process.
-		return handler.GetKillExitHandler(service), update
+		return handler.NewKillExitHandler(service), update
 	case sysmsg.ShutdownCMD:
 		// the parent supervisor wants us to Shutdown
 		return handler.NewShutdownCMDHandler(service), update
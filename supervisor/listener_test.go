@@ -0,0 +1,49 @@
+package supervisor
+
+import (
+	"github.com/hedisam/goactor"
+	p "github.com/hedisam/goactor/pid"
+	"github.com/hedisam/goactor/supervisor/option"
+	"github.com/hedisam/goactor/supervisor/spec"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// crashingWorker returns a worker spec which sends its pid to started each time it starts, and panics once it
+// receives a message.
+func crashingWorker(name string, started chan<- *p.PID) spec.WorkerSpec {
+	return spec.NewWorkerSpec(name, spec.RestartAlways, func(actor *goactor.Actor) {
+		started <- actor.Self()
+		_ = actor.Receive(func(message interface{}) (loop bool) {
+			panic(message)
+		})
+	})
+}
+
+func TestListen_SeveralSupervisors(t *testing.T) {
+	first := make(chan *p.PID, 10)
+	second := make(chan *p.PID, 10)
+	_, err := Start(option.OneForOneStrategyOption(), crashingWorker("", first))
+	if !assert.Nil(t, err) {return}
+	_, err = Start(option.OneForOneStrategyOption(), crashingWorker("", second))
+	if !assert.Nil(t, err) {return}
+
+	receive := func(started <-chan *p.PID) *p.PID {
+		select {
+		case pid := <-started:
+			return pid
+		case <-time.After(time.Second):
+			return nil
+		}
+	}
+	// each supervisor handles the exits of its own children, whichever of them has handled an exit first
+	for _, started := range []chan *p.PID{first, second} {
+		pid := receive(started)
+		if !assert.NotNil(t, pid) {return}
+		if !assert.Nil(t, goactor.Send(pid, "crash")) {return}
+		restarted := receive(started)
+		if !assert.NotNil(t, restarted, "the child wasn't restarted by its supervisor") {return}
+		assert.NotEqual(t, pid.ID(), restarted.ID())
+	}
+}
@@ -1,27 +1,12 @@
 package models
 
 import (
-	"github.com/hedisam/goactor/internal/intlpid"
-	"github.com/hedisam/goactor/internal/relations"
+	"github.com/hedisam/goactor/pid"
+	"github.com/hedisam/goactor/reason"
 	"github.com/hedisam/goactor/supervisor/childstate"
 	"github.com/hedisam/goactor/sysmsg"
 )
 
-type RelationManager interface {
-	AddLink(pid intlpid.InternalPID) error
-	RemoveLink(pid intlpid.InternalPID) error
-
-	LinkedActors() *relations.RelationIterator
-	MonitorActors() *relations.RelationIterator
-
-	Dispose()
-}
-
-type Mailbox interface {
-	Receive(msgHandler, sysMsgHandler func(interface{}) bool) error
-	Dispose()
-}
-
 type SupHandler interface {
 	Run(update sysmsg.SystemMessage) bool
 }
@@ -31,14 +16,15 @@ type StrategyHandler interface {
 }
 
 type InitMsg struct {
-	SenderPID intlpid.InternalPID
+	SenderPID *pid.PID
 }
 
-func (m *InitMsg) Reason() interface{} {
-	return "init_supervisor"
+// Reason returns nil since an init message is not an exit message.
+func (m *InitMsg) Reason() reason.Reason {
+	return nil
 }
 
-func (m *InitMsg) Sender() intlpid.InternalPID {
+func (m *InitMsg) Sender() *pid.PID {
 	return m.SenderPID
 }
 
@@ -1,7 +1,9 @@
 package option
 
 import (
+	"context"
 	"fmt"
+	"github.com/hedisam/goactor/clock"
 )
 
 const (
@@ -28,6 +30,12 @@ type Options struct {
 	Strategy    StrategyType
 	MaxRestarts int
 	Period      int
+	// Clock measures the restarts period. The real clock is used if it's nil.
+	Clock clock.Clock
+	// Context is the parent of the supervisor's context, which is passed down to its children. Cancelling it shuts
+	// the supervisor down. A child supervisor inherits its parent's context if it's nil, and a top level supervisor
+	// uses context.Background().
+	Context context.Context
 }
 
 func OneForOneStrategyOption() Options {
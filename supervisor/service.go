@@ -1,9 +1,11 @@
 package supervisor
 
 import (
+	"context"
 	"fmt"
-	"github.com/hedisam/goactor/internal/intlpid"
+	"github.com/hedisam/goactor/clock"
 	p "github.com/hedisam/goactor/pid"
+	"github.com/hedisam/goactor/reason"
 	"github.com/hedisam/goactor/supervisor/childstate"
 	"github.com/hedisam/goactor/supervisor/internal/intlspec"
 	"github.com/hedisam/goactor/supervisor/models"
@@ -42,6 +44,11 @@ func (service *Service) Self() *p.PID {
 	return service.supervisor.Self()
 }
 
+// Context returns the supervisor's context, which its children's contexts are derived from.
+func (service *Service) Context() context.Context {
+	return service.supervisor.Context()
+}
+
 func (service *Service) ChildrenIterator() *childstate.ChildrenStateIterator {
 	return service.childrenManager.Iterator()
 }
@@ -80,7 +87,7 @@ func (service *Service) StartChild(spec intlspec.Spec) error {
 	return nil
 }
 
-func (service *Service) GetChildByPID(pid intlpid.InternalPID) (*childstate.ChildState, bool) {
+func (service *Service) GetChildByPID(pid *p.PID) (*childstate.ChildState, bool) {
 	return service.childrenManager.GetByPID(pid)
 }
 
@@ -96,6 +103,11 @@ func (service *Service) RestartsPeriod() int {
 	return service.options.Period
 }
 
+// Clock returns the clock which measures the restarts period.
+func (service *Service) Clock() clock.Clock {
+	return clock.OrReal(service.options.Clock)
+}
+
 func (service *Service) MaxRestartsAllowed() int {
 	return service.options.MaxRestarts
 }
@@ -103,8 +115,8 @@ func (service *Service) MaxRestartsAllowed() int {
 func (service *Service) MaxRestartsReached() {
 	// shutting down this supervisor because a child reached its max allowed restarts in a specified period
 	service.Shutdown(sysmsg.NewKillMessage(
-		service.supervisor.Self().InternalPID(),
-		"supervisor's child reached its max allowed restarts",
+		service.supervisor.Self(),
+		reason.Shutdown,
 		nil),
 	)
 }
@@ -1,10 +1,15 @@
 package spec
 
 import (
+	"github.com/hedisam/goactor"
 	"github.com/hedisam/goactor/pid"
+	"github.com/hedisam/goactor/supervisor/internal/intlspec"
 )
 
-type StartLink func() (*pid.PID, error)
+// Spec is implemented by the child specs, i.e. WorkerSpec and SupervisorSpec.
+type Spec = intlspec.Spec
+
+type StartLink func(parent goactor.Linker) (*pid.PID, error)
 
 const (
 	RestartAlways = iota
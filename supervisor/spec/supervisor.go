@@ -2,6 +2,7 @@ package spec
 
 import (
 	"github.com/google/uuid"
+	"github.com/hedisam/goactor"
 	"github.com/hedisam/goactor/pid"
 	"github.com/hedisam/goactor/supervisor/internal/intlspec"
 	"github.com/hedisam/goactor/supervisor/option"
@@ -16,8 +17,8 @@ type SupervisorSpec struct {
 	SupOptions    option.Options
 }
 
-func (s SupervisorSpec) StartLink() (*pid.PID, error) {
-	return intlspec.DefaultSupervisorStartLink(s.SupOptions, s.Children...)
+func (s SupervisorSpec) StartLink(parent goactor.Linker) (*pid.PID, error) {
+	return intlspec.DefaultSupervisorStartLink(parent, s.SupOptions, s.Children...)
 }
 
 func (s SupervisorSpec) SupervisorOptions() *option.Options {
@@ -1,9 +1,11 @@
 package spec
 
 import (
+	"context"
 	"github.com/google/uuid"
 	"github.com/hedisam/goactor"
 	p "github.com/hedisam/goactor/pid"
+	"github.com/hedisam/goactor/process"
 	"github.com/hedisam/goactor/supervisor/option"
 	"strings"
 )
@@ -12,12 +14,25 @@ type WorkerSpec struct {
 	Id             string
 	actorFunc      goactor.ActorFunc
 	mailboxBuilder goactor.MailboxBuilderFunc
+	via            process.Registry
 	WhenToRestart  int
 }
 
-func (w WorkerSpec) StartLink() (*p.PID, error) {
-	pid := goactor.Spawn(w.actorFunc, w.mailboxBuilder)
-	return pid, nil
+// contextProvider is implemented by supervisors, which pass their context down to their children.
+type contextProvider interface {
+	Context() context.Context
+}
+
+func (w WorkerSpec) StartLink(parent goactor.Linker) (*p.PID, error) {
+	provider, ok := parent.(contextProvider)
+	if !ok {
+		return goactor.SpawnLink(parent, w.actorFunc, w.mailboxBuilder)
+	}
+	return goactor.SpawnWithOptions(w.actorFunc,
+		goactor.WithLink(parent),
+		goactor.WithMailbox(w.mailboxBuilder),
+		goactor.WithContext(provider.Context()),
+	)
 }
 
 func (w WorkerSpec) SupervisorOptions() *option.Options {
@@ -37,6 +52,18 @@ func (w WorkerSpec) SetMailboxBuilder(fn goactor.MailboxBuilderFunc) WorkerSpec
 	return w
 }
 
+// Via returns the registry the worker is registered to by its name, in addition to the default one.
+func (w WorkerSpec) Via() process.Registry {
+	return w.via
+}
+
+// SetVia makes the supervisor register the worker by its name to the given registry too, so it can be addressed by
+// a process.Via name.
+func (w WorkerSpec) SetVia(registry process.Registry) WorkerSpec {
+	w.via = registry
+	return w
+}
+
 func NewWorkerSpec(name string, restartWhen int, fn goactor.ActorFunc) WorkerSpec {
 	if strings.TrimSpace(name) == "" {
 		name = uuid.New().String()
@@ -1,12 +1,15 @@
 package supervisor
 
 import (
+	"context"
 	"fmt"
 	"github.com/hedisam/goactor"
 	"github.com/hedisam/goactor/internal/intlpid"
+	"github.com/hedisam/goactor/internal/pidconv"
 	"github.com/hedisam/goactor/internal/relations"
 	"github.com/hedisam/goactor/mailbox"
 	p "github.com/hedisam/goactor/pid"
+	"github.com/hedisam/goactor/sched"
 	"github.com/hedisam/goactor/supervisor/internal/intlspec"
 	"github.com/hedisam/goactor/supervisor/models"
 	"github.com/hedisam/goactor/supervisor/option"
@@ -15,10 +18,21 @@ import (
 
 var noShutdown func()
 
+// contextProvider is implemented by the supervisor service, which passes its context down to its children.
+type contextProvider interface {
+	Context() context.Context
+}
+
 // Start a new supervisor for the given children specifications. It returns a supervisor reference that can be used
 // to interact with the supervisor.
 // An error is returned if the supervisor's options or any of children specs are invalid.
 func Start(options option.Options, specs ...intlspec.Spec) (*supref.SupRef, error) {
+	return startLink(nil, options, specs...)
+}
+
+// startLink starts a new supervisor just like Start, but if a parent is provided the supervisor gets linked to it
+// before it starts running.
+func startLink(parent goactor.Linker, options option.Options, specs ...intlspec.Spec) (*supref.SupRef, error) {
 	specsMap, err := intlspec.SpecsToMap(specs...)
 	if err != nil {
 		return nil, fmt.Errorf("invalid specs: %w", err)
@@ -30,22 +44,40 @@ func Start(options option.Options, specs ...intlspec.Spec) (*supref.SupRef, erro
 	}
 
 	// creating a supervisor actor
-	m := mailbox.NewQueueMailbox(1, 100, mailbox.DefaultMailboxTimeout, mailbox.DefaultGoSchedulerInterval)
+	var m goactor.Mailbox = mailbox.NewQueueMailbox(1, 100, mailbox.DefaultMailboxTimeout, mailbox.DefaultGoSchedulerInterval)
+	if s := sched.Current(); s != nil {
+		m = s.NewMailbox()
+	}
 	relationManager := relations.NewRelation()
 
+	ctx := options.Context
+	if ctx == nil {
+		ctx = context.Background()
+		if provider, ok := parent.(contextProvider); ok {
+			ctx = provider.Context()
+		}
+	}
+
 	// we don't provide a Shutdown func to a supervisor's internal_pid
 	// so the only way to Shutdown a supervisor is by sending a Shutdown Command
 	pid := intlpid.NewLocalPID(m, relationManager, true, noShutdown)
 
-	supervisor := newSupervisorActor(m, pid, relationManager)
+	supervisor := newSupervisorActor(ctx, m, pid, relationManager)
 
 	supService := newService(supervisor, specsMap, &options)
+	if parent != nil {
+		err = parent.Link(supervisor.Self())
+		if err != nil {
+			supervisor.discard()
+			return nil, fmt.Errorf("could not link the supervisor to its parent: %w", err)
+		}
+	}
 	// spawn our new supervisor
 	spawn(supService)
 
 	// sending an Init msg so the supervisor starts spawning its childrenManager
 	future := goactor.NewFutureActor()
-	err = intlpid.SendMessage(supervisor.Self().InternalPID(), models.InitMsg{SenderPID: future.Self().InternalPID()})
+	err = intlpid.SendMessage(pidconv.Internal(supervisor.Self()), models.InitMsg{SenderPID: future.Self()})
 	if err != nil {
 		return nil, fmt.Errorf("could not initialize supervisor: %w", err)
 	}
@@ -66,18 +98,19 @@ func Start(options option.Options, specs ...intlspec.Spec) (*supref.SupRef, erro
 }
 
 func spawn(service *Service) {
-	go func() {
+	sched.Go(func() {
 		sup := service.supervisor
 		defer sup.dispose(service)
+		sup.watchParent()
 		listen(sup, service)
-	}()
+	})
 }
 
 // start is assigned to spec.SupervisorSpec's StartLink function to start a new supervisor child process.
 // Basically the goal of this function is to decouple the spec.SupervisorSpec from the Start function when spawning a
 // supervisor child process. So the spec package would not depend on its root package (this package).
-func start(options option.Options, specs ...intlspec.Spec) (*p.PID, error) {
-	supRef, err := Start(options, specs...)
+func start(parent goactor.Linker, options option.Options, specs ...intlspec.Spec) (*p.PID, error) {
+	supRef, err := startLink(parent, options, specs...)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,178 @@
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/hedisam/goactor"
+	"github.com/hedisam/goactor/internal/intlpid"
+	"github.com/hedisam/goactor/internal/pidconv"
+	p "github.com/hedisam/goactor/pid"
+	"github.com/hedisam/goactor/process"
+	"github.com/hedisam/goactor/reason"
+	"github.com/hedisam/goactor/supervisor/option"
+	"github.com/hedisam/goactor/supervisor/spec"
+	"github.com/hedisam/goactor/sysmsg"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestStart_NameConflict(t *testing.T) {
+	name := "conflicting child " + uuid.New().String()
+	worker := spec.NewWorkerSpec(name, spec.RestartAlways, func(actor *goactor.Actor) {
+		_ = actor.Receive(func(message interface{}) (loop bool) {
+			return true
+		})
+	})
+
+	_, err := Start(option.OneForOneStrategyOption(), worker)
+	if !assert.Nil(t, err) {return}
+	registered, ok := process.WhereIs(name)
+	if !assert.True(t, ok) {return}
+
+	// the second supervisor can't steal the name of the first one's child
+	_, err = Start(option.OneForOneStrategyOption(), worker)
+	assert.True(t, errors.Is(err, process.ErrNameTaken), err)
+	pid, ok := process.WhereIs(name)
+	assert.True(t, ok)
+	assert.Equal(t, registered.ID(), pid.ID())
+}
+
+func TestStart_NestedNameConflict(t *testing.T) {
+	name := "conflicting child supervisor " + uuid.New().String()
+	// only the child supervisors' names conflict, their workers' names don't
+	child := func() spec.SupervisorSpec {
+		worker := spec.NewWorkerSpec("worker "+uuid.New().String(), spec.RestartAlways, func(actor *goactor.Actor) {
+			_ = actor.Receive(func(message interface{}) (loop bool) {
+				return true
+			})
+		})
+		return spec.NewSupervisorSpec(name, spec.RestartAlways, option.OneForOneStrategyOption(), worker)
+	}
+
+	_, err := Start(option.OneForOneStrategyOption(), child())
+	if !assert.Nil(t, err) {return}
+	registered, ok := process.WhereIs(name)
+	if !assert.True(t, ok) {return}
+
+	// the conflicting child supervisor is shut down, and the second supervisor fails to start instead of hanging
+	result := make(chan error, 1)
+	go func() {
+		_, err := Start(option.OneForOneStrategyOption(), child())
+		result <- err
+	}()
+	select {
+	case err = <-result:
+		assert.True(t, errors.Is(err, process.ErrNameTaken), err)
+	case <-time.After(time.Second):
+		t.Fatal("the supervisor didn't fail to start")
+	}
+	pid, ok := process.WhereIs(name)
+	assert.True(t, ok)
+	assert.Equal(t, registered.ID(), pid.ID())
+}
+
+var errLinkFailed = errors.New("link failed")
+
+// failingLinker fails to link to the supervisor it's the parent of.
+type failingLinker struct {
+	linked *p.PID
+}
+
+func (l *failingLinker) Link(pid *p.PID) error {
+	l.linked = pid
+	return errLinkFailed
+}
+
+func TestStartLink_LinkFailure(t *testing.T) {
+	worker := spec.NewWorkerSpec("worker "+uuid.New().String(), spec.RestartAlways, func(actor *goactor.Actor) {})
+	parent := &failingLinker{}
+	_, err := startLink(parent, option.OneForOneStrategyOption(), worker)
+	assert.True(t, errors.Is(err, errLinkFailed), err)
+
+	// the supervisor never runs, its mailbox is disposed right away
+	if !assert.NotNil(t, parent.linked) {return}
+	assert.False(t, intlpid.IsAlive(pidconv.Internal(parent.linked)))
+}
+
+type contextKey struct{}
+
+func TestStart_Context(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), contextKey{}, "value"))
+	defer cancel()
+	started := make(chan context.Context, 10)
+	worker := spec.NewWorkerSpec("worker "+uuid.New().String(), spec.RestartAlways, func(actor *goactor.Actor) {
+		started <- actor.Context()
+		_ = actor.Receive(func(message interface{}) (loop bool) {
+			return true
+		})
+	})
+	// the child supervisor inherits its parent's context
+	child := spec.NewSupervisorSpec("child "+uuid.New().String(), spec.RestartAlways, option.OneForOneStrategyOption(), worker)
+	options := option.OneForOneStrategyOption()
+	options.Context = ctx
+	sup, err := Start(options, child)
+	if !assert.Nil(t, err) {return}
+
+	parent, dispose := goactor.NewParentActor(nil)
+	defer dispose()
+	ref, err := parent.Monitor(sup.PID())
+	if !assert.Nil(t, err) {return}
+
+	var workerCtx context.Context
+	select {
+	case workerCtx = <-started:
+	case <-time.After(time.Second):
+		t.Fatal("the worker didn't start")
+	}
+	assert.Equal(t, "value", workerCtx.Value(contextKey{}))
+
+	// cancelling the context shuts the whole tree down, and the worker isn't restarted
+	cancel()
+	err = parent.ReceiveWithTimeout(time.Second, func(message interface{}) (loop bool) {
+		assert.Equal(t, sysmsg.Down{Ref: ref, PID: sup.PID(), Reason: reason.Shutdown}, message)
+		return false
+	})
+	assert.Nil(t, err)
+	assert.NotNil(t, workerCtx.Err())
+	select {
+	case <-started:
+		t.Error("the worker should not be restarted")
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestSupervisor_MonitorExited(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	worker := spec.NewWorkerSpec("worker "+uuid.New().String(), spec.RestartAlways, func(actor *goactor.Actor) {
+		_ = actor.Receive(func(message interface{}) (loop bool) {
+			return true
+		})
+	})
+	options := option.OneForOneStrategyOption()
+	options.Context = ctx
+	sup, err := Start(options, worker)
+	if !assert.Nil(t, err) {return}
+
+	parent, dispose := goactor.NewParentActor(nil)
+	defer dispose()
+	ref, err := parent.Monitor(sup.PID())
+	if !assert.Nil(t, err) {return}
+	cancel()
+	err = parent.ReceiveWithTimeout(time.Second, func(message interface{}) (loop bool) {
+		assert.Equal(t, sysmsg.Down{Ref: ref, PID: sup.PID(), Reason: reason.Shutdown}, message)
+		return false
+	})
+	if !assert.Nil(t, err) {return}
+
+	// monitoring the exited supervisor delivers a noproc Down right away
+	ref, err = parent.Monitor(sup.PID())
+	if !assert.Nil(t, err) {return}
+	err = parent.ReceiveWithTimeout(time.Second, func(message interface{}) (loop bool) {
+		assert.Equal(t, sysmsg.Down{Ref: ref, PID: sup.PID(), Reason: reason.NoProc}, message)
+		return false
+	})
+	assert.Nil(t, err)
+}
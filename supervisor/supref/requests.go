@@ -3,7 +3,9 @@ package supref
 import (
 	"fmt"
 	"github.com/hedisam/goactor/internal/intlpid"
+	"github.com/hedisam/goactor/internal/pidconv"
 	"github.com/hedisam/goactor/pid"
+	"github.com/hedisam/goactor/reason"
 	"github.com/hedisam/goactor/supervisor/childstate"
 	"github.com/hedisam/goactor/supervisor/internal/intlspec"
 	"github.com/hedisam/goactor/sysmsg"
@@ -11,7 +13,7 @@ import (
 )
 
 type refRequest interface {
-	SetRequester(pid intlpid.InternalPID)
+	SetRequester(pid *pid.PID)
 }
 
 type SupervisorService interface {
@@ -25,15 +27,15 @@ type SupervisorService interface {
 
 type refBaseRequest struct {
 	service   SupervisorService
-	requester intlpid.InternalPID
+	requester *pid.PID
 }
 
-func (req *refBaseRequest) SetRequester(pid intlpid.InternalPID) {
+func (req *refBaseRequest) SetRequester(pid *pid.PID) {
 	req.requester = pid
 }
 
 func (req *refBaseRequest) Reply(tag string, resp interface{}) {
-	err := intlpid.SendMessage(req.requester, resp)
+	err := intlpid.SendMessage(pidconv.Internal(req.requester), resp)
 	if err != nil {
 		log.Printf("[!] supervisor couldn't send response to a %s - err: %v\n", tag, err)
 	}
@@ -77,6 +79,31 @@ func (req *ChildrenCountRequest) Run(_ sysmsg.SystemMessage) bool {
 	return true
 }
 
+type WhichChildrenRequest struct {
+	*refBaseRequest
+}
+
+func NewWhichChildrenRequest() *WhichChildrenRequest {
+	return &WhichChildrenRequest{&refBaseRequest{}}
+}
+
+func (req *WhichChildrenRequest) Run(_ sysmsg.SystemMessage) bool {
+	childrenIterator := req.service.ChildrenIterator()
+	resp := make(Children, 0, childrenIterator.Size())
+	for childrenIterator.HasNext() {
+		child := childrenIterator.Value()
+		resp = append(resp, Child{
+			Name:         child.Name(),
+			PID:          child.PID(),
+			IsSupervisor: child.IsSupervisor(),
+			Dead:         child.Dead(),
+		})
+	}
+
+	req.Reply("WhichChildrenRequest", resp)
+	return true
+}
+
 type DeleteChildRequest struct {
 	*refBaseRequest
 	name string
@@ -212,7 +239,7 @@ func (req *TerminateChildRequest) Run(_ sysmsg.SystemMessage) bool {
 
 	err := req.service.ShutdownChild(
 		child,
-		sysmsg.NewKillMessage(req.service.Self().InternalPID(), "terminated by user's request", nil),
+		sysmsg.NewKillMessage(req.service.Self(), reason.Shutdown, nil),
 	)
 	if err != nil {
 		req.Reply(tag, fmt.Errorf("failed to terminate the child: %w", err))
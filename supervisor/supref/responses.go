@@ -1,6 +1,9 @@
 package supref
 
-import "fmt"
+import (
+	"fmt"
+	p "github.com/hedisam/goactor/pid"
+)
 
 type supRefResponse interface {
 	response()
@@ -32,3 +35,17 @@ func (info *ChildrenCount) String() string {
 		"\t- workers: 		%d\n",
 		info.Specs, info.Active, info.Supervisors, info.Workers)
 }
+
+// Child describes one of the supervisor's children.
+type Child struct {
+	Name string
+	// PID is the child's current pid, or the last one if the child is dead.
+	PID          *p.PID
+	IsSupervisor bool
+	Dead         bool
+}
+
+// Children is the list of the supervisor's children.
+type Children []Child
+
+func (Children) response() {}
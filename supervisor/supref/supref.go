@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/hedisam/goactor"
 	"github.com/hedisam/goactor/internal/intlpid"
+	"github.com/hedisam/goactor/internal/pidconv"
 	p "github.com/hedisam/goactor/pid"
 	"github.com/hedisam/goactor/supervisor/internal/intlspec"
 	"time"
@@ -40,6 +41,20 @@ func (ref *SupRef) ChildrenCount(timeout time.Duration) (*ChildrenCount, error)
 	return response, nil
 }
 
+// WhichChildren returns the supervisor's children, dead or alive, in no particular order.
+func (ref *SupRef) WhichChildren(timeout time.Duration) (Children, error) {
+	req := NewWhichChildrenRequest()
+	resp, err := ref.request(req, timeout)
+	if err != nil {
+		return nil, err
+	}
+	response, ok := resp.(Children)
+	if !ok {
+		return nil, fmt.Errorf("unknown response from supervisor: %v", resp)
+	}
+	return response, nil
+}
+
 func (ref *SupRef) DeleteChild(name string, timeout time.Duration) error {
 	req := NewDeleteChildRequest(name)
 	_, err := ref.request(req, timeout)
@@ -67,9 +82,9 @@ func (ref *SupRef) TerminateChild(name string, timeout time.Duration) error {
 func (ref *SupRef) request(request refRequest, timeout time.Duration) (resp interface{}, err error) {
 	future := goactor.NewFutureActor()
 
-	request.SetRequester(future.Self().InternalPID())
+	request.SetRequester(future.Self())
 
-	err = intlpid.SendSystemMessage(ref.pid.InternalPID(), request)
+	err = intlpid.SendSystemMessage(pidconv.Internal(ref.pid), request)
 	if err != nil {
 		return nil, fmt.Errorf("couldn't deliver request to the supervisor: %w", err)
 	}
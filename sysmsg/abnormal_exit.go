@@ -1,26 +1,29 @@
 package sysmsg
 
-import "github.com/hedisam/goactor/internal/intlpid"
+import (
+	"github.com/hedisam/goactor/pid"
+	"github.com/hedisam/goactor/reason"
+)
 
 type AbnormalExit struct {
-	from   intlpid.InternalPID
-	reason interface{}
+	from   *pid.PID
+	reason reason.Reason
 	origin SystemMessage
 }
 
-func NewAbnormalExitMsg(from intlpid.InternalPID, reason interface{}, origin SystemMessage) AbnormalExit {
+func NewAbnormalExitMsg(from *pid.PID, exitReason reason.Reason, origin SystemMessage) AbnormalExit {
 	return AbnormalExit{
 		from:   from,
-		reason: reason,
+		reason: exitReason,
 		origin: origin,
 	}
 }
 
-func (m AbnormalExit) Sender() intlpid.InternalPID {
+func (m AbnormalExit) Sender() *pid.PID {
 	return m.from
 }
 
-func (m AbnormalExit) Reason() interface{} {
+func (m AbnormalExit) Reason() reason.Reason {
 	return m.reason
 }
 
@@ -0,0 +1,30 @@
+package sysmsg
+
+import (
+	"github.com/google/uuid"
+	"github.com/hedisam/goactor/pid"
+	"github.com/hedisam/goactor/reason"
+)
+
+// MonitorRef is a unique reference to a monitor. Monitoring the same actor more than once results in different
+// references.
+type MonitorRef string
+
+func NewMonitorRef() MonitorRef {
+	return MonitorRef(uuid.New().String())
+}
+
+func (ref MonitorRef) String() string {
+	return string(ref)
+}
+
+// Down is delivered to a monitor actor when the actor it's monitoring exits.
+type Down struct {
+	// Ref is the reference returned when the monitor was created
+	Ref MonitorRef
+	// PID is the pid of the monitored actor
+	PID *pid.PID
+	// Reason is the reason the monitored actor has exited with. It's reason.NoProc if the actor had already exited
+	// when it was monitored.
+	Reason reason.Reason
+}
@@ -1,28 +1,29 @@
 package sysmsg
 
 import (
-	"github.com/hedisam/goactor/internal/intlpid"
+	"github.com/hedisam/goactor/pid"
+	"github.com/hedisam/goactor/reason"
 )
 
 type KillExit struct {
-	from   intlpid.InternalPID
-	reason interface{}
+	from   *pid.PID
+	reason reason.Reason
 	origin SystemMessage
 }
 
-func NewKillMessage(from intlpid.InternalPID, reason interface{}, origin SystemMessage) KillExit {
+func NewKillMessage(from *pid.PID, exitReason reason.Reason, origin SystemMessage) KillExit {
 	return KillExit{
 		from:   from,
-		reason: reason,
+		reason: exitReason,
 		origin: origin,
 	}
 }
 
-func (m KillExit) Sender() intlpid.InternalPID {
+func (m KillExit) Sender() *pid.PID {
 	return m.from
 }
 
-func (m KillExit) Reason() interface{} {
+func (m KillExit) Reason() reason.Reason {
 	return m.reason
 }
 
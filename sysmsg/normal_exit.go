@@ -1,25 +1,28 @@
 package sysmsg
 
-import "github.com/hedisam/goactor/internal/intlpid"
+import (
+	"github.com/hedisam/goactor/pid"
+	"github.com/hedisam/goactor/reason"
+)
 
 type NormalExit struct {
-	from   intlpid.InternalPID
+	from   *pid.PID
 	origin SystemMessage
 }
 
-func NewNormalExitMsg(from intlpid.InternalPID, originalMsg SystemMessage) NormalExit {
+func NewNormalExitMsg(from *pid.PID, originalMsg SystemMessage) NormalExit {
 	return NormalExit{
 		from:   from,
 		origin: originalMsg,
 	}
 }
 
-func (m NormalExit) Sender() intlpid.InternalPID {
+func (m NormalExit) Sender() *pid.PID {
 	return m.from
 }
 
-func (m NormalExit) Reason() interface{} {
-	return "normal_exit"
+func (m NormalExit) Reason() reason.Reason {
+	return reason.Normal
 }
 
 func (m NormalExit) Origin() SystemMessage {
@@ -1,28 +1,29 @@
 package sysmsg
 
 import (
-	"github.com/hedisam/goactor/internal/intlpid"
+	"github.com/hedisam/goactor/pid"
+	"github.com/hedisam/goactor/reason"
 )
 
 type ShutdownCMD struct {
-	from   intlpid.InternalPID
-	reason interface{}
+	from   *pid.PID
+	reason reason.Reason
 	origin SystemMessage
 }
 
-func NewShutdownCMD(from intlpid.InternalPID, reason interface{}, origin SystemMessage) ShutdownCMD {
+func NewShutdownCMD(from *pid.PID, exitReason reason.Reason, origin SystemMessage) ShutdownCMD {
 	return ShutdownCMD{
 		from:   from,
-		reason: reason,
+		reason: exitReason,
 		origin: origin,
 	}
 }
 
-func (cmd ShutdownCMD) Sender() intlpid.InternalPID {
+func (cmd ShutdownCMD) Sender() *pid.PID {
 	return cmd.from
 }
 
-func (cmd ShutdownCMD) Reason() interface{} {
+func (cmd ShutdownCMD) Reason() reason.Reason {
 	return cmd.reason
 }
 
@@ -1,11 +1,12 @@
 package sysmsg
 
 import (
-	"github.com/hedisam/goactor/internal/intlpid"
+	"github.com/hedisam/goactor/pid"
+	"github.com/hedisam/goactor/reason"
 )
 
 type SystemMessage interface {
-	Sender() intlpid.InternalPID
-	Reason() interface{}
+	Sender() *pid.PID
+	Reason() reason.Reason
 	Origin() SystemMessage
 }
@@ -0,0 +1,113 @@
+package task
+
+import (
+	"fmt"
+	"github.com/hedisam/goactor"
+	"github.com/hedisam/goactor/internal/intlpid"
+	"github.com/hedisam/goactor/internal/pidconv"
+	p "github.com/hedisam/goactor/pid"
+	"github.com/hedisam/goactor/reason"
+	"github.com/hedisam/goactor/sysmsg"
+	"log"
+	"time"
+)
+
+var ErrUnknownResponse = fmt.Errorf("task: unknown response")
+
+// Supervisor runs tasks which are linked to the supervisor instead of the caller, so a crashing task doesn't take
+// down its caller. It's meant for fire-and-forget tasks, or for tasks whose crash is reported by Await only.
+type Supervisor struct {
+	pid *p.PID
+}
+
+type startRequest struct {
+	fn        Func
+	requester *p.PID
+}
+
+type startResponse struct {
+	task *Task
+	err  error
+}
+
+type stopRequest struct{}
+
+// NewSupervisor spawns a new task supervisor.
+func NewSupervisor() *Supervisor {
+	return &Supervisor{pid: goactor.Spawn(superviseTasks, nil)}
+}
+
+// PID returns the supervisor's pid.
+func (s *Supervisor) PID() *p.PID {
+	return s.pid
+}
+
+// Async runs fn in a new task under the supervisor. The task's crash is only reported by Await.
+func (s *Supervisor) Async(fn Func, timeout time.Duration) (*Task, error) {
+	future := goactor.NewFutureActor()
+	err := goactor.Send(s.pid, startRequest{fn: fn, requester: future.Self()})
+	if err != nil {
+		return nil, fmt.Errorf("task: couldn't deliver start request to the supervisor: %w", err)
+	}
+
+	var resp *startResponse
+	var respErr error
+	err = future.ReceiveWithTimeout(timeout, func(message interface{}) (loop bool) {
+		if r, ok := message.(startResponse); ok {
+			resp = &r
+			return false
+		}
+		respErr = fmt.Errorf("%w: %v", ErrUnknownResponse, message)
+		return false
+	})
+	if err != nil {
+		return nil, fmt.Errorf("task: start request failed: %w", err)
+	} else if respErr != nil {
+		return nil, respErr
+	}
+	return resp.task, resp.err
+}
+
+// Start runs fn in a new task under the supervisor without waiting for its result.
+func (s *Supervisor) Start(fn Func, timeout time.Duration) error {
+	_, err := s.Async(fn, timeout)
+	return err
+}
+
+// Stop shuts down the running tasks by cancelling their context, and then stops the supervisor.
+func (s *Supervisor) Stop() error {
+	return goactor.Send(s.pid, stopRequest{})
+}
+
+func superviseTasks(actor *goactor.Actor) {
+	actor.SetTrapExit(true)
+	running := make(map[string]*p.PID)
+
+	_ = actor.Receive(func(message interface{}) (loop bool) {
+		switch msg := message.(type) {
+		case startRequest:
+			t := newTask()
+			pid, err := goactor.SpawnLink(actor, t.actorFunc(msg.fn), nil)
+			if err != nil {
+				err = fmt.Errorf("task: failed to start a supervised task: %w", err)
+				_ = goactor.Send(msg.requester, startResponse{err: err})
+				return true
+			}
+			t.pid = pid
+			running[pid.ID()] = pid
+			_ = goactor.Send(msg.requester, startResponse{task: t})
+		case sysmsg.NormalExit:
+			delete(running, msg.Sender().ID())
+		case sysmsg.AbnormalExit:
+			delete(running, msg.Sender().ID())
+			log.Printf("[!] task supervisor %s: task %s has exited, reason: %v\n",
+				actor.Self().ID(), msg.Sender().ID(), msg.Reason())
+		case stopRequest:
+			for _, pid := range running {
+				intlpid.Shutdown(pidconv.Internal(pid), reason.Shutdown)
+			}
+			return false
+		}
+		return true
+	})
+}
@@ -0,0 +1,229 @@
+// Package task runs one-off computations in their own actors, and awaits their results.
+//
+// Tasks aren't supported under sched.Run: a task's Func runs in a plain goroutine, so the actor keeps receiving its
+// caller's exit signal meanwhile, and awaiting a task blocks on a channel, which would block the whole run.
+package task
+
+import (
+	"context"
+	"fmt"
+	"github.com/hedisam/goactor"
+	p "github.com/hedisam/goactor/pid"
+	"github.com/hedisam/goactor/reason"
+	"github.com/hedisam/goactor/sysmsg"
+	"time"
+)
+
+var ErrTimeout = fmt.Errorf("task: timeout")
+var ErrInvalidConcurrency = fmt.Errorf("task: max concurrency must be greater than zero")
+
+// Func is the computation run by a task. ctx is the task actor's context which is cancelled if the task gets
+// shut down, or if its linked caller crashes.
+type Func func(ctx context.Context) (interface{}, error)
+
+// ExitError is returned when a task has exited before returning its result, e.g. it has panicked.
+type ExitError struct {
+	Reason reason.Reason
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("task exited: %v", e.Reason)
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Reason
+}
+
+// Task is a one-off computation running in its own actor.
+type Task struct {
+	pid   *p.PID
+	done  chan struct{}
+	value interface{}
+	err   error
+}
+
+// Result is the outcome of a task as returned by YieldMany and AsyncStream.
+type Result struct {
+	Task  *Task
+	Value interface{}
+	// Err is the error returned by the task, an *ExitError if it has exited, or ErrTimeout if it had not finished in
+	// time.
+	Err error
+}
+
+func newTask() *Task {
+	return &Task{done: make(chan struct{})}
+}
+
+// PID returns the pid of the task's actor.
+func (t *Task) PID() *p.PID {
+	return t.pid
+}
+
+// Async runs fn in a new task which is linked to the caller, so a crashing task takes down the caller unless it's
+// trapping exits, and vice versa: a crashing caller cancels the ctx passed to fn, and the task exits with the
+// caller's reason without waiting for fn to return.
+func Async(caller goactor.Linker, fn Func) (*Task, error) {
+	t := newTask()
+	pid, err := goactor.SpawnLink(caller, t.actorFunc(fn), nil)
+	if err != nil {
+		return nil, fmt.Errorf("task: async failed: %w", err)
+	}
+	t.pid = pid
+	return t, nil
+}
+
+// Await waits up to timeout for the task's result. A zero timeout waits forever. Awaiting a task more than once
+// returns the same result.
+func Await(t *Task, timeout time.Duration) (interface{}, error) {
+	if timeout <= 0 {
+		<-t.done
+		return t.value, t.err
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-t.done:
+		return t.value, t.err
+	case <-timer.C:
+		return nil, ErrTimeout
+	}
+}
+
+// YieldMany waits up to timeout for all of the tasks, and returns their results in the same order. The tasks that
+// have not finished in time get ErrTimeout as their result's error.
+func YieldMany(tasks []*Task, timeout time.Duration) []Result {
+	var deadline <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	results := make([]Result, len(tasks))
+	timedOut := false
+	for i, t := range tasks {
+		results[i].Task = t
+		if !timedOut {
+			select {
+			case <-t.done:
+			case <-deadline:
+				timedOut = true
+			}
+		}
+		select {
+		case <-t.done:
+			results[i].Value, results[i].Err = t.value, t.err
+		default:
+			results[i].Err = ErrTimeout
+		}
+	}
+	return results
+}
+
+// AsyncStream runs fn for each of the items in tasks linked to the caller, running at most maxConcurrency of them at
+// the same time. The results are delivered in the same order as the items, and the returned channel gets closed
+// after the last one. Each task is awaited up to timeout, or forever if it's zero. The channel must be drained,
+// otherwise no more tasks get started.
+func AsyncStream(caller goactor.Linker, items []interface{}, maxConcurrency int, timeout time.Duration,
+	fn func(ctx context.Context, item interface{}) (interface{}, error)) (<-chan Result, error) {
+	if maxConcurrency < 1 {
+		return nil, ErrInvalidConcurrency
+	}
+
+	results := make(chan Result)
+	go func() {
+		defer close(results)
+
+		inFlight := make([]*Task, 0, maxConcurrency)
+		for _, item := range items {
+			if len(inFlight) == maxConcurrency {
+				results <- yield(inFlight[0], timeout)
+				inFlight = inFlight[1:]
+			}
+			item := item
+			t, err := Async(caller, func(ctx context.Context) (interface{}, error) {
+				return fn(ctx, item)
+			})
+			if err != nil {
+				// keep the results in order by queueing a task which has already failed.
+				t = newTask()
+				t.complete(nil, err)
+			}
+			inFlight = append(inFlight, t)
+		}
+		for _, t := range inFlight {
+			results <- yield(t, timeout)
+		}
+	}()
+	return results, nil
+}
+
+func yield(t *Task, timeout time.Duration) Result {
+	value, err := Await(t, timeout)
+	return Result{Task: t, Value: value, Err: err}
+}
+
+// outcome is what fn has returned, or the reason it has panicked with.
+type outcome struct {
+	value    interface{}
+	err      error
+	panicked reason.Reason
+}
+
+// fnDone tells the task's actor that fn has returned.
+type fnDone struct{}
+
+// actorFunc wraps fn into an actor func which completes the task once fn returns or panics. fn runs in its own
+// goroutine, so the actor keeps receiving the exit signal of its caller meanwhile. Being a plain goroutine is why
+// tasks aren't supported under sched.Run.
+func (t *Task) actorFunc(fn Func) goactor.ActorFunc {
+	return func(actor *goactor.Actor) {
+		defer func() {
+			switch r := recover().(type) {
+			case nil:
+			case sysmsg.AbnormalExit:
+				// the linked caller has crashed, which has cancelled fn's ctx by shutting down the actor.
+				t.complete(nil, &ExitError{Reason: r.Reason()})
+				panic(r)
+			default:
+				exitReason := reason.FromPanic(r)
+				t.complete(nil, &ExitError{Reason: exitReason})
+				// panic again so the linked actors get notified of the crash.
+				panic(exitReason)
+			}
+		}()
+
+		outcomes := make(chan outcome, 1)
+		self := actor.Self()
+		go func() {
+			var o outcome
+			defer func() {
+				if r := recover(); r != nil {
+					o.panicked = reason.FromPanic(r)
+				}
+				outcomes <- o
+				_ = goactor.Send(self, fnDone{})
+			}()
+			o.value, o.err = fn(actor.Context())
+		}()
+
+		// Receive returns once fn is done, or once the actor has been shut down, in which case fn's ctx is cancelled
+		// and its outcome is still waited for.
+		_ = actor.Receive(func(message interface{}) (loop bool) {
+			_, done := message.(fnDone)
+			return !done
+		})
+		o := <-outcomes
+		if o.panicked != nil {
+			panic(o.panicked)
+		}
+		t.complete(o.value, o.err)
+	}
+}
+
+func (t *Task) complete(value interface{}, err error) {
+	t.value, t.err = value, err
+	close(t.done)
+}
@@ -0,0 +1,225 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"github.com/hedisam/goactor"
+	"github.com/hedisam/goactor/reason"
+	"github.com/hedisam/goactor/sysmsg"
+	"github.com/stretchr/testify/assert"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAsyncAwait(t *testing.T) {
+	caller, dispose := goactor.NewParentActor(nil)
+	defer dispose()
+
+	task, err := Async(caller, func(ctx context.Context) (interface{}, error) {
+		return 42, nil
+	})
+	if !assert.Nil(t, err) {return}
+	assert.NotNil(t, task.PID())
+
+	value, err := Await(task, time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, 42, value)
+
+	// awaiting again returns the same result
+	value, err = Await(task, time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, 42, value)
+
+	failing, err := Async(caller, func(ctx context.Context) (interface{}, error) {
+		return nil, errors.New("failed")
+	})
+	if !assert.Nil(t, err) {return}
+	_, err = Await(failing, time.Second)
+	assert.EqualError(t, err, "failed")
+
+	_, err = Async(nil, func(ctx context.Context) (interface{}, error) {return nil, nil})
+	assert.True(t, errors.Is(err, goactor.ErrSpawnNilParent))
+}
+
+func TestAwait_Timeout(t *testing.T) {
+	caller, dispose := goactor.NewParentActor(nil)
+	defer dispose()
+
+	release := make(chan struct{})
+	task, err := Async(caller, func(ctx context.Context) (interface{}, error) {
+		<-release
+		return "done", nil
+	})
+	if !assert.Nil(t, err) {return}
+
+	_, err = Await(task, 10*time.Millisecond)
+	assert.Equal(t, ErrTimeout, err)
+
+	close(release)
+	value, err := Await(task, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, "done", value)
+}
+
+func TestAsync_CrashPropagates(t *testing.T) {
+	caller, dispose := goactor.NewParentActor(nil)
+	defer dispose()
+	caller.SetTrapExit(true)
+
+	task, err := Async(caller, func(ctx context.Context) (interface{}, error) {
+		panic("boom")
+	})
+	if !assert.Nil(t, err) {return}
+
+	_, err = Await(task, time.Second)
+	var exitErr *ExitError
+	if !assert.True(t, errors.As(err, &exitErr)) {return}
+	panicReason, ok := exitErr.Reason.(reason.Panic)
+	if !assert.True(t, ok) {return}
+	assert.Equal(t, "boom", panicReason.Value)
+
+	// the linked caller gets the task's exit message
+	err = caller.ReceiveWithTimeout(time.Second, func(message interface{}) (loop bool) {
+		exit, ok := message.(sysmsg.AbnormalExit)
+		if !assert.True(t, ok) {return false}
+		assert.Equal(t, task.PID().ID(), exit.Sender().ID())
+		assert.Equal(t, panicReason, exit.Reason())
+		return false
+	})
+	assert.Nil(t, err)
+}
+
+func TestAsync_CallerCrashCancels(t *testing.T) {
+	cancelled := make(chan error, 1)
+	tasks := make(chan *Task, 1)
+	goactor.Spawn(func(actor *goactor.Actor) {
+		task, err := Async(actor, func(ctx context.Context) (interface{}, error) {
+			<-ctx.Done()
+			cancelled <- ctx.Err()
+			return nil, ctx.Err()
+		})
+		if err != nil {
+			return
+		}
+		tasks <- task
+		panic("caller crashed")
+	}, nil)
+
+	var task *Task
+	select {
+	case task = <-tasks:
+	case <-time.After(time.Second):
+		t.Fatal("the task didn't start")
+	}
+	select {
+	case err := <-cancelled:
+		assert.Equal(t, context.Canceled, err)
+	case <-time.After(time.Second):
+		t.Fatal("the task's ctx wasn't cancelled")
+	}
+
+	// the task exits with the caller's reason
+	_, err := Await(task, time.Second)
+	var exitErr *ExitError
+	if !assert.True(t, errors.As(err, &exitErr)) {return}
+	panicReason, ok := exitErr.Reason.(reason.Panic)
+	if !assert.True(t, ok) {return}
+	assert.Equal(t, "caller crashed", panicReason.Value)
+}
+
+func TestYieldMany(t *testing.T) {
+	caller, dispose := goactor.NewParentActor(nil)
+	defer dispose()
+
+	release := make(chan struct{})
+	defer close(release)
+	fast, err := Async(caller, func(ctx context.Context) (interface{}, error) {
+		return "fast", nil
+	})
+	if !assert.Nil(t, err) {return}
+	slow, err := Async(caller, func(ctx context.Context) (interface{}, error) {
+		<-release
+		return "slow", nil
+	})
+	if !assert.Nil(t, err) {return}
+
+	results := YieldMany([]*Task{slow, fast}, 20*time.Millisecond)
+	if !assert.Len(t, results, 2) {return}
+	assert.Equal(t, slow, results[0].Task)
+	assert.Equal(t, ErrTimeout, results[0].Err)
+	assert.Equal(t, fast, results[1].Task)
+	assert.Nil(t, results[1].Err)
+	assert.Equal(t, "fast", results[1].Value)
+}
+
+func TestAsyncStream(t *testing.T) {
+	caller, dispose := goactor.NewParentActor(nil)
+	defer dispose()
+
+	_, err := AsyncStream(caller, nil, 0, 0, nil)
+	assert.Equal(t, ErrInvalidConcurrency, err)
+
+	items := []interface{}{5, 1, 4, 2, 3}
+	var running, maxRunning int32
+	results, err := AsyncStream(caller, items, 2, time.Second,
+		func(ctx context.Context, item interface{}) (interface{}, error) {
+			n := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+			for {
+				max := atomic.LoadInt32(&maxRunning)
+				if n <= max || atomic.CompareAndSwapInt32(&maxRunning, max, n) {
+					break
+				}
+			}
+			// later items finish first, yet the results must be in order
+			time.Sleep(time.Duration(item.(int)) * time.Millisecond)
+			return item.(int) * 10, nil
+		})
+	if !assert.Nil(t, err) {return}
+
+	var values []interface{}
+	for result := range results {
+		assert.Nil(t, result.Err)
+		values = append(values, result.Value)
+	}
+	assert.Equal(t, []interface{}{50, 10, 40, 20, 30}, values)
+	assert.LessOrEqual(t, atomic.LoadInt32(&maxRunning), int32(2))
+}
+
+func TestSupervisor(t *testing.T) {
+	sup := NewSupervisor()
+
+	// a crashing task doesn't affect the caller, and is reported by Await
+	crashing, err := sup.Async(func(ctx context.Context) (interface{}, error) {
+		panic("boom")
+	}, time.Second)
+	if !assert.Nil(t, err) {return}
+	_, err = Await(crashing, time.Second)
+	var exitErr *ExitError
+	assert.True(t, errors.As(err, &exitErr))
+
+	task, err := sup.Async(func(ctx context.Context) (interface{}, error) {
+		return "ok", nil
+	}, time.Second)
+	if !assert.Nil(t, err) {return}
+	value, err := Await(task, time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, "ok", value)
+
+	// running tasks are cancelled once the supervisor stops
+	cancelled := make(chan struct{})
+	err = sup.Start(func(ctx context.Context) (interface{}, error) {
+		<-ctx.Done()
+		close(cancelled)
+		return nil, nil
+	}, time.Second)
+	if !assert.Nil(t, err) {return}
+
+	if !assert.Nil(t, sup.Stop()) {return}
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Error("the running task was not cancelled")
+	}
+}